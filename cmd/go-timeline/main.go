@@ -2,8 +2,13 @@ package main
 
 import (
 	"github.com/kamkali/go-timeline/internal/app"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		app.RunCommand(os.Args[1], os.Args[2:])
+		return
+	}
 	app.Run()
 }
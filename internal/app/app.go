@@ -1,7 +1,10 @@
 package app
 
 import (
+	"fmt"
 	"github.com/kamkali/go-timeline/internal/auth"
+	"github.com/kamkali/go-timeline/internal/blob"
+	"github.com/kamkali/go-timeline/internal/cache"
 	"github.com/kamkali/go-timeline/internal/config"
 	postgresql2 "github.com/kamkali/go-timeline/internal/postgresql"
 	"github.com/kamkali/go-timeline/internal/server"
@@ -10,6 +13,8 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"log"
+	"net/http"
+	"time"
 )
 
 type app struct {
@@ -18,13 +23,27 @@ type app struct {
 	database   *gorm.DB
 	jwtManager *auth.JWTManager
 	server     *server.Server
+	blobStore  timeline2.BlobStore
+	pageCache  timeline2.PageCache
+	// command is the one-off command run instead of the server, empty when serving.
+	command string
 
-	eventRepo      timeline2.EventRepository
-	eventService   timeline2.EventService
-	typeRepo       timeline2.TypeRepository
-	typeService    timeline2.TypeService
-	userService    timeline2.UserService
-	userRepository timeline2.UserRepository
+	eventRepo       timeline2.EventRepository
+	eventService    timeline2.EventService
+	typeRepo        timeline2.TypeRepository
+	typeService     timeline2.TypeService
+	userService     timeline2.UserService
+	userRepository  timeline2.UserRepository
+	mediaRepo       timeline2.MediaRepository
+	mediaService    timeline2.MediaService
+	tagRepo         timeline2.TagRepository
+	tagService      timeline2.TagService
+	relationRepo    timeline2.RelationRepository
+	relationService timeline2.RelationService
+	transactor      timeline2.Transactor
+	batchService    timeline2.BatchService
+	importService   timeline2.ImportService
+	exportService   timeline2.ExportService
 }
 
 func (a *app) initConfig() {
@@ -68,10 +87,55 @@ func (a *app) initDB() {
 	a.database = orm
 }
 
+func (a *app) initBlobStore() {
+	store, err := newBlobStore(a.config)
+	if err != nil {
+		log.Fatalf("cannot initialize blob store: %v\n", err)
+	}
+	a.blobStore = store
+}
+
+func newBlobStore(c *config.Config) (timeline2.BlobStore, error) {
+	switch c.Media.Backend {
+	case config.MediaBackendFilesystem:
+		return blob.NewFileStore(c.Media.Dir)
+	case config.MediaBackendS3:
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  c.Media.S3.Endpoint,
+			Region:    c.Media.S3.Region,
+			Bucket:    c.Media.S3.Bucket,
+			AccessKey: c.Media.S3.AccessKey,
+			SecretKey: c.Media.S3.SecretKey,
+		}, http.DefaultClient)
+	default:
+		return nil, fmt.Errorf("unknown media backend %q", c.Media.Backend)
+	}
+}
+
+func (a *app) initPageCache() {
+	ttl := time.Duration(a.config.Cache.TTLSeconds) * time.Second
+	switch a.config.Cache.Backend {
+	case config.CacheBackendNone:
+	case config.CacheBackendMemory:
+		// the cache of a command is not the one of the running servers, whose pages expire after the TTL
+		if a.command != "" {
+			a.log.Info(fmt.Sprintf("changes reach the pages cached in memory by running servers within %v", ttl))
+			return
+		}
+		a.pageCache = cache.NewMemoryCache(a.config.Cache.MaxEntries, a.config.Cache.MaxBytes, ttl)
+	case config.CacheBackendPostgres:
+		a.pageCache = postgresql2.NewPageCache(a.log, a.database, a.config.Cache.MaxEntries, a.config.Cache.MaxBytes, ttl)
+	default:
+		log.Fatalf("unknown cache backend %q\n", a.config.Cache.Backend)
+	}
+}
+
 func (a *app) initApp() {
 	a.initConfig()
 	a.initLogger()
 	a.initDB()
+	a.initBlobStore()
+	a.initPageCache()
 	a.initTimelineRepositories()
 	a.initTimelineServices()
 	a.initJWTManager()
@@ -79,15 +143,39 @@ func (a *app) initApp() {
 }
 
 func (a *app) initTimelineRepositories() {
-	a.eventRepo = postgresql2.NewEventRepository(a.log, a.database)
+	a.eventRepo = postgresql2.NewEventRepository(a.log, a.database, a.config.DB.SearchLanguage)
 	a.typeRepo = postgresql2.NewTypeRepository(a.log, a.database)
 	a.userRepository = postgresql2.NewUserRepository(a.log, a.database)
+	a.mediaRepo = postgresql2.NewMediaRepository(a.log, a.database)
+	a.tagRepo = postgresql2.NewTagRepository(a.log, a.database)
+	a.relationRepo = postgresql2.NewRelationRepository(a.log, a.database)
+	a.transactor = postgresql2.NewTransactor(a.log, a.database, a.config.DB.SearchLanguage)
 }
 
 func (a *app) initTimelineServices() {
-	a.eventService = service2.NewEventService(a.log, a.eventRepo)
+	mediaLimits := service2.MediaLimits{
+		MaxBytes:     a.config.Media.MaxUploadBytes,
+		MaxDimension: a.config.Media.MaxDimension,
+	}
+	a.eventService = service2.NewEventService(a.log, mediaLimits, a.eventRepo)
 	a.typeService = service2.NewTypeService(a.log, a.typeRepo)
 	a.userService = service2.NewUserService(a.log, a.userRepository)
+	a.mediaService = service2.NewMediaService(a.log, mediaLimits, a.mediaRepo, a.eventRepo, a.blobStore)
+	a.tagService = service2.NewTagService(a.log, a.tagRepo)
+	a.relationService = service2.NewRelationService(a.log, a.relationRepo, a.transactor)
+	a.batchService = service2.NewBatchService(a.log, a.config.Server.BatchMaxSize, mediaLimits, a.transactor)
+	a.importService = service2.NewImportService(a.log, mediaLimits, a.transactor)
+	a.exportService = service2.NewExportService(a.log, a.eventRepo, a.typeRepo, a.tagRepo)
+
+	if a.pageCache != nil {
+		a.eventService = service2.NewInvalidatingEventService(a.log, a.eventService, a.pageCache)
+		a.typeService = service2.NewInvalidatingTypeService(a.log, a.typeService, a.pageCache)
+		a.tagService = service2.NewInvalidatingTagService(a.log, a.tagService, a.pageCache)
+		a.relationService = service2.NewInvalidatingRelationService(a.log, a.relationService, a.pageCache)
+		a.batchService = service2.NewInvalidatingBatchService(a.log, a.batchService, a.pageCache)
+		a.importService = service2.NewInvalidatingImportService(a.log, a.importService, a.pageCache)
+		a.mediaService = service2.NewInvalidatingMediaService(a.log, a.mediaService, a.pageCache)
+	}
 }
 
 func (a *app) initJWTManager() {
@@ -104,6 +192,11 @@ func (a *app) initHTTPServer() {
 		a.log,
 		a.jwtManager,
 		a.eventService, a.typeService, a.userService,
+		a.mediaService, a.tagService, a.relationService,
+		a.batchService,
+		a.importService,
+		a.exportService,
+		a.pageCache,
 	)
 	if err != nil {
 		log.Fatalf("cannot init server: %v\n", err)
@@ -111,11 +204,18 @@ func (a *app) initHTTPServer() {
 	a.server = s
 }
 
-func (a *app) start() {
+func (a *app) migrateDB() {
 	if err := postgresql2.Migrate(a.database); err != nil {
 		log.Fatalf("couldn't migrate db: %v\n", err)
 	}
+	if err := postgresql2.MigrateSearch(a.database, a.config.DB.SearchLanguage); err != nil {
+		log.Fatalf("couldn't migrate db search: %v\n", err)
+	}
 	a.log.Info("successfully migrated database")
+}
+
+func (a *app) start() {
+	a.migrateDB()
 	if a.config.SeedDB {
 		if err := a.seedDBWithAdmin(a.config); err != nil {
 			log.Fatalf("cannot seed DB with admin info")
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	"github.com/kamkali/go-timeline/internal/generator"
+	"github.com/kamkali/go-timeline/internal/server"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"golang.org/x/net/context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type command func(a *app, args []string) error
+
+var commands = map[string]command{
+	"migrate-graphics": migrateGraphics,
+	"import":           importEvents,
+	"export":           exportTimeline,
+	"export-site":      exportSite,
+}
+
+// RunCommand runs a one-off maintenance command against the configured database instead of starting the server.
+func RunCommand(name string, args []string) {
+	cmd, ok := commands[name]
+	if !ok {
+		var names []string
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		log.Fatalf("unknown command %q, available commands: %s\n", name, strings.Join(names, ", "))
+	}
+
+	a := app{command: name}
+	a.initApp()
+	a.migrateDB()
+	if err := cmd(&a, args); err != nil {
+		log.Fatalf("%s: %v\n", name, err)
+	}
+}
+
+func migrateGraphics(a *app, _ []string) error {
+	migrated, err := a.mediaService.MigrateEventGraphics(context.Background())
+	if err != nil {
+		return err
+	}
+	a.log.Info(fmt.Sprintf("migrated %d event graphics to the media store", migrated))
+	return nil
+}
+
+// stringsFlag collects the values of a flag given multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func importEvents(a *app, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "csv, json, ndjson, ics or timelinejs, by default taken from the file extension")
+	createTypes := fs.Bool("create-types", false, "create types that do not exist yet")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without changing anything")
+	defaultType := fs.String("default-type", "", "type of created events that do not refer to any")
+	from := fs.String("from", "", "import occurrences of recurring iCalendar events from this time on, a year ago by default")
+	until := fs.String("until", "", "import occurrences of recurring iCalendar events up to this time, a year from now by default")
+	var mappings stringsFlag
+	fs.Var(&mappings, "map", "assign a CSV column to an import field as field:Column, can be repeated")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: import [flags] FILE")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected a single file to import")
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		switch *format {
+		case "jsonl":
+			*format = codec.FormatNDJSON
+		case "ical":
+			*format = codec.FormatICS
+		}
+	}
+	var (
+		parseOpts codec.ImportParseOptions
+		err       error
+	)
+	if parseOpts.Mapping, err = codec.ParseImportMapping(mappings); err != nil {
+		return err
+	}
+	for value, flagValue := range map[*time.Time]string{&parseOpts.Window.From: *from, &parseOpts.Window.Until: *until} {
+		if flagValue == "" {
+			continue
+		}
+		if *value, err = codec.ParseImportTime(flagValue); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	file, err := codec.ParseImport(*format, f, parseOpts)
+	if err != nil {
+		return err
+	}
+	report, err := a.importService.ImportEvents(context.Background(), file, timeline2.ImportOptions{
+		CreateTypes: *createTypes,
+		DryRun:      *dryRun,
+		DefaultType: *defaultType,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if row.Err != nil {
+			fmt.Printf("line %d: %v\n", row.Line, row.Err)
+		}
+	}
+	for _, name := range report.CreatedTypes {
+		fmt.Printf("type %q created\n", name)
+	}
+	fmt.Printf("%d created, %d updated, %d unchanged, %d failed\n", report.Created, report.Updated, report.Unchanged, report.Failed)
+	switch {
+	case report.Failed > 0:
+		return fmt.Errorf("%d rows failed, nothing was imported", report.Failed)
+	case report.DryRun:
+		fmt.Println("dry run, nothing was imported")
+	}
+	return nil
+}
+
+func exportTimeline(a *app, args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "csv, json, ndjson, ics, timelinejs, svg or pdf, by default taken from the output file extension or json")
+	output := fs.String("o", "", "output file, standard output by default")
+	baseURL := fs.String("base-url", a.config.Site.BaseURL, "URL the timeline is served at, makes media URLs of TimelineJS exports absolute")
+	title := fs.String("title", "", "title of SVG and PDF exports")
+	typeID := fs.Uint("type", 0, "export only events of the type and of its descendants")
+	tagMode := fs.String("tag-mode", "", "any or all of the tags must match")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "export only events with the tag, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), ".")
+		if *format == "" {
+			*format = codec.FormatJSON
+		}
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = f
+	}
+	w, err := codec.NewExportWriter(*format, out, codec.ExportOptions{BaseURL: *baseURL, Title: *title})
+	if err != nil {
+		return err
+	}
+	filter := timeline2.EventFilter{Tags: tags, TagMode: timeline2.TagMode(*tagMode)}
+	if *typeID != 0 {
+		filter.TypeIDs = []uint{*typeID}
+	}
+	return a.exportService.ExportTimeline(context.Background(), filter, w)
+}
+
+func exportSite(a *app, args []string) error {
+	fs := flag.NewFlagSet("export-site", flag.ContinueOnError)
+	out := fs.String("out", "", "directory to write the site to")
+	eventPages := fs.Bool("event-pages", false, "write a permalink page per event")
+	theme := fs.String("theme", "", "render all pages with the theme instead of the themes of their types")
+	baseURL := fs.String("base-url", "", "URL the site will be hosted at, for canonical URLs and the metadata of shared links")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *out == "" {
+		fs.Usage()
+		return errors.New("missing output directory")
+	}
+
+	ctx := context.Background()
+	events, err := a.eventService.ListEvents(ctx, timeline2.EventFilter{})
+	if err != nil {
+		return err
+	}
+	relations, err := a.relationService.ListRelations(ctx)
+	if err != nil {
+		return err
+	}
+	types, err := a.typeService.ListTypes(ctx)
+	if err != nil {
+		return err
+	}
+	assets, err := server.StaticAssets()
+	if err != nil {
+		return err
+	}
+	renderer, err := generator.NewRenderer(generator.RendererOptions{
+		ThemesDir:    a.config.Site.ThemesDir,
+		DefaultTheme: a.config.Site.Theme,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = renderer.WriteStaticSite(*out, generator.StaticSite{
+		Events:     events,
+		Relations:  relations,
+		Types:      types,
+		EventPages: *eventPages,
+		BaseURL:    *baseURL,
+		Theme:      *theme,
+		Assets:     assets,
+		OpenMedia: func(key string) (io.ReadCloser, error) {
+			_, content, err := a.mediaService.GetMedia(ctx, key)
+			return content, err
+		},
+	})
+	if err != nil {
+		return err
+	}
+	a.log.Info(fmt.Sprintf("exported %d events to %s", len(events), *out))
+	return nil
+}
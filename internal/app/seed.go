@@ -1,9 +1,8 @@
 package app
 
 import (
+	"bytes"
 	_ "embed"
-	"encoding/base64"
-	"fmt"
 	"github.com/kamkali/go-timeline/internal/config"
 	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
 	"golang.org/x/net/context"
@@ -33,16 +32,21 @@ func (a *app) seedDBWithExampleValues() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
 	defer cancel()
 	t1 := &timeline2.Type{
-		Name:  "normal",
-		Color: "white",
+		Name:         "normal",
+		Color:        "white",
+		DisplayOrder: 1,
 	}
 	t2 := &timeline2.Type{
-		Name:  "error",
-		Color: "red",
+		Name:         "error",
+		Color:        "red",
+		Icon:         "warning",
+		DisplayOrder: 3,
 	}
 	t3 := &timeline2.Type{
-		Name:  "special",
-		Color: "green",
+		Name:         "special",
+		Color:        "green",
+		Icon:         "star",
+		DisplayOrder: 2,
 	}
 	for _, t := range []*timeline2.Type{t1, t2, t3} {
 		id, err := a.typeService.CreateType(ctx, t)
@@ -58,6 +62,7 @@ func (a *app) seedDBWithExampleValues() error {
 		ShortDescription:    "The Soviet Union launches the first artificial satellite",
 		DetailedDescription: "Sputnik 1 was a Soviet artificial satellite that was launched into orbit on October 4, 1957. It was the first satellite to be launched into space, and its successful launch marked the beginning of the Space Age. The satellite was about the size of a beach ball and weighed just under 200 pounds. It was equipped with two radio transmitters, which sent out a series of beeps that could be heard by amateur radio operators around the world. The launch of Sputnik 1 sparked the Space Race between the Soviet Union and the United States, which ultimately led to the first human landing on the Moon in 1969.",
 		TypeID:              t1.ID,
+		Tags:                []string{"soviet", "satellites"},
 	}
 
 	e2 := &timeline2.Event{
@@ -66,6 +71,7 @@ func (a *app) seedDBWithExampleValues() error {
 		ShortDescription:    "Yuri Gagarin becomes the first human to orbit Earth",
 		DetailedDescription: "Yuri Gagarin was a Soviet pilot and cosmonaut who became the first human to orbit Earth on April 12, 1961. Gagarin's spacecraft, Vostok 1, circled the Earth once in just under an hour and a half, reaching an altitude of about 186 miles. Gagarin's flight marked a major milestone in the Space Race between the Soviet Union and the United States, and he became an international celebrity after his return to Earth. Gagarin died in a plane crash in 1968 at the age of 34.",
 		TypeID:              t2.ID,
+		Tags:                []string{"soviet", "crewed"},
 	}
 
 	e3 := &timeline2.Event{
@@ -74,6 +80,7 @@ func (a *app) seedDBWithExampleValues() error {
 		ShortDescription:    "Neil Armstrong becomes the first human to set foot on the Moon",
 		DetailedDescription: `The Apollo 11 mission was the first manned mission to land on the Moon. It was launched on July 16, 1969, and four days later, on July 20, astronauts Neil Armstrong and Edwin "Buzz" Aldrin landed the lunar module Eagle on the surface of the Moon. Armstrong became the first human to set foot on the Moon when he stepped out of the lunar module and onto the surface, saying the famous words, "That's one small step for man, one giant leap for mankind." The Apollo 11 mission marked the end of the Space Race between the United States and the Soviet Union, and it remains one of the most significant achievements in the history of space exploration.`,
 		TypeID:              t3.ID,
+		Tags:                []string{"apollo", "nasa", "crewed"},
 	}
 
 	e4 := &timeline2.Event{
@@ -82,6 +89,7 @@ func (a *app) seedDBWithExampleValues() error {
 		ShortDescription:    "First manned spacecraft to leave Earth's orbit and reach the Moon",
 		DetailedDescription: "Apollo 8 was the first manned spacecraft to leave Earth's orbit, and it successfully orbited the Moon on December 24, 1968. The crew of Apollo 8, consisting of Frank Borman, James Lovell, and William Anders, became the first humans to see the far side of the Moon. This mission was a major stepping stone in the Apollo program, paving the way for the eventual landing on the Moon.",
 		TypeID:              t3.ID,
+		Tags:                []string{"apollo", "nasa", "crewed"},
 	}
 
 	e5 := &timeline2.Event{
@@ -89,8 +97,8 @@ func (a *app) seedDBWithExampleValues() error {
 		EventTime:           time.Date(1969, 7, 16, 9, 32, 0, 0, time.UTC),
 		ShortDescription:    "Launch of the Apollo 11 mission to the Moon",
 		DetailedDescription: "Apollo 11 was the fifth manned mission in the Apollo program and the first mission to land humans on the Moon. The mission was launched on July 16, 1969, and was crewed by Neil Armstrong, Buzz Aldrin, and Michael Collins. The mission is most famous for the first human landing on the Moon on July 20, 1969, when Armstrong and Aldrin became the first humans to walk on the Moon's surface.",
-		Graphic:             fmt.Sprintf("data:text/plain;base64,%s", base64.StdEncoding.EncodeToString(apollo11Graphic)),
 		TypeID:              t3.ID,
+		Tags:                []string{"apollo", "nasa"},
 	}
 
 	e6 := &timeline2.Event{
@@ -99,6 +107,7 @@ func (a *app) seedDBWithExampleValues() error {
 		ShortDescription:    "Crash of the Lunar Landing Training Vehicle during a training mission",
 		DetailedDescription: "During a training mission for the Apollo 11 landing, the Lunar Landing Training Vehicle (LLTV) being piloted by Neil Armstrong experienced a malfunction and crashed to the ground. Armstrong was able to eject from the vehicle just seconds before impact, saving his life. The incident raised concerns about the safety of the Apollo program and highlighted the risks involved in space exploration.",
 		TypeID:              t3.ID,
+		Tags:                []string{"apollo", "nasa", "training"},
 	}
 
 	e7 := &timeline2.Event{
@@ -106,8 +115,8 @@ func (a *app) seedDBWithExampleValues() error {
 		EventTime:           time.Date(1969, 7, 24, 16, 50, 35, 0, time.UTC),
 		ShortDescription:    "Apollo 11 crew successfully returns to Earth",
 		DetailedDescription: "After spending eight days in space, the Apollo 11 crew successfully returned to Earth on July 24, 1969. The crew, consisting of Neil Armstrong, Buzz Aldrin, and Michael Collins, were hailed as heroes upon their return and were celebrated around the world for their historic achievement.",
-		Graphic:             fmt.Sprintf("data:text/plain;base64,%s", base64.StdEncoding.EncodeToString(apollo11CrewGraphic)),
 		TypeID:              t3.ID,
+		Tags:                []string{"apollo", "nasa", "crewed"},
 	}
 
 	for _, e := range []*timeline2.Event{e1, e2, e3, e4, e5, e6, e7} {
@@ -118,5 +127,15 @@ func (a *app) seedDBWithExampleValues() error {
 		e.ID = id
 	}
 
+	graphics := map[*timeline2.Event][]byte{
+		e5: apollo11Graphic,
+		e7: apollo11CrewGraphic,
+	}
+	for e, graphic := range graphics {
+		if _, err := a.mediaService.UploadEventGraphic(ctx, e.ID, bytes.NewReader(graphic)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
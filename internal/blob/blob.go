@@ -0,0 +1,13 @@
+package blob
+
+import (
+	"fmt"
+	"strings"
+)
+
+func validateKey(key string) error {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
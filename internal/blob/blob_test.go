@@ -0,0 +1,97 @@
+package blob
+
+import (
+	"bytes"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible server such as MinIO.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	auths   []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auths = append(f.auths, r.Header.Get("Authorization"))
+	if r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func testStore(t *testing.T, store timeline.BlobStore) {
+	ctx := context.Background()
+	content := []byte("some image bytes")
+
+	require.NoError(t, store.Put(ctx, "abc.png", bytes.NewReader(content), int64(len(content)), "image/png"))
+
+	rc, err := store.Get(ctx, "abc.png")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, content, got)
+
+	require.NoError(t, store.Delete(ctx, "abc.png"))
+	_, err = store.Get(ctx, "abc.png")
+	require.ErrorIs(t, err, timeline.ErrNotFound)
+
+	require.Error(t, store.Put(ctx, "../escape", bytes.NewReader(content), int64(len(content)), ""))
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	testStore(t, store)
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:  srv.URL,
+		Region:    "eu-central-1",
+		Bucket:    "timeline",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+	}, srv.Client())
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC) }
+
+	testStore(t, store)
+
+	require.NotEmpty(t, fake.auths)
+	for _, auth := range fake.auths {
+		require.True(t, strings.HasPrefix(auth,
+			"AWS4-HMAC-SHA256 Credential=minio/20221201/eu-central-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+	}
+}
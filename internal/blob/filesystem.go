@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"golang.org/x/net/context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create media dir: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, key)); err != nil {
+		return fmt.Errorf("cannot store blob: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(s.dir, key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, timeline.ErrNotFound
+		}
+		return nil, fmt.Errorf("cannot open blob: %w", err)
+	}
+	return f, nil
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.dir, key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot delete blob: %w", err)
+	}
+	return nil
+}
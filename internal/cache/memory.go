@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"container/list"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"golang.org/x/net/context"
+	"sync"
+	"time"
+)
+
+// MemoryCache keeps pages in the memory of a single instance. Beyond maxEntries pages or maxBytes of page bodies
+// the least recently used ones are dropped, pages expire after the TTL regardless.
+type MemoryCache struct {
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+	now        func() time.Time
+
+	mu      sync.Mutex
+	version timeline.PageCacheVersion
+	entries map[string]*list.Element
+	// size is the total size of the cached page bodies.
+	size int64
+	// recent lists the entries from the most to the least recently used.
+	recent *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	page    timeline.CachedPage
+	expires time.Time
+}
+
+// NewMemoryCache returns an empty cache. The timeline may have changed before it was created, so it reports the
+// time of its creation as the last modification.
+func NewMemoryCache(maxEntries int, maxBytes int64, ttl time.Duration) *MemoryCache {
+	c := &MemoryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+	c.version = timeline.PageCacheVersion{Generation: 1, Modified: c.now()}
+	return c
+}
+
+func (c *MemoryCache) Version(context.Context) (timeline.PageCacheVersion, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version, nil
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) (timeline.CachedPage, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return timeline.CachedPage{}, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(el)
+		return timeline.CachedPage{}, false, nil
+	}
+	c.recent.MoveToFront(el)
+	return entry.page, true, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, page timeline.CachedPage) error {
+	if c.maxEntries <= 0 || int64(len(page.Body)) > c.maxBytes {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.recent.PushFront(&memoryEntry{key: key, page: page, expires: c.now().Add(c.ttl)})
+	c.size += int64(len(page.Body))
+	for c.recent.Len() > c.maxEntries || c.size > c.maxBytes {
+		c.remove(c.recent.Back())
+	}
+	return nil
+}
+
+// Invalidate drops all pages along with starting a new generation, none of them would be served again.
+func (c *MemoryCache) Invalidate(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = timeline.PageCacheVersion{Generation: c.version.Generation + 1, Modified: c.now()}
+	c.entries = make(map[string]*list.Element)
+	c.recent.Init()
+	c.size = 0
+	return nil
+}
+
+func (c *MemoryCache) remove(el *list.Element) {
+	c.recent.Remove(el)
+	entry := el.Value.(*memoryEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.page.Body))
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func page(body string) timeline.CachedPage {
+	return timeline.CachedPage{Body: []byte(body), Header: http.Header{"Content-Type": {"text/html"}, "Vary": {"Accept", "Accept-Encoding"}}}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2, 1024, time.Minute)
+	require.NoError(t, c.Set(ctx, "a", page("a")))
+	require.NoError(t, c.Set(ctx, "b", page("b")))
+	_, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "c", page("c")))
+	_, ok, _ = c.Get(ctx, "b")
+	require.False(t, ok)
+	got, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, page("a"), got)
+	_, ok, _ = c.Get(ctx, "c")
+	require.True(t, ok)
+}
+
+func TestMemoryCacheEvictsBeyondMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10, 8, time.Minute)
+	require.NoError(t, c.Set(ctx, "a", page("aaaa")))
+	require.NoError(t, c.Set(ctx, "b", page("bbbb")))
+	require.NoError(t, c.Set(ctx, "c", page("cc")))
+	_, ok, _ := c.Get(ctx, "a")
+	require.False(t, ok)
+	_, ok, _ = c.Get(ctx, "b")
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "d", page("ddddddddd")))
+	_, ok, _ = c.Get(ctx, "d")
+	require.False(t, ok)
+	_, ok, _ = c.Get(ctx, "c")
+	require.True(t, ok)
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC)
+	c := NewMemoryCache(10, 1024, time.Minute)
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Set(ctx, "a", page("a")))
+
+	now = now.Add(59 * time.Second)
+	_, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+	now = now.Add(time.Second)
+	_, ok, _ = c.Get(ctx, "a")
+	require.False(t, ok)
+}
+
+func TestMemoryCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10, 1024, time.Minute)
+	before, err := c.Version(ctx)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "a", page("a")))
+
+	now := before.Modified.Add(time.Hour)
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Invalidate(ctx))
+	after, err := c.Version(ctx)
+	require.NoError(t, err)
+	require.Equal(t, before.Generation+1, after.Generation)
+	require.Equal(t, now, after.Modified)
+	_, ok, _ := c.Get(ctx, "a")
+	require.False(t, ok)
+}
+
+func TestMemoryCacheDisabled(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(0, 1024, time.Minute)
+	require.NoError(t, c.Set(ctx, "a", page("a")))
+	_, ok, _ := c.Get(ctx, "a")
+	require.False(t, ok)
+}
@@ -0,0 +1,341 @@
+package chart
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultWidth is the width of charts in pixels, or points in PDF documents, unless requested otherwise.
+const DefaultWidth = 1200
+
+// defaultColor marks events without a type or of types without a color, as on the timeline page.
+const defaultColor = "#999999"
+
+const (
+	margin        = 40.0
+	titleSize     = 20.0
+	nameSize      = 12.0
+	dateSize      = 10.0
+	tickSize      = 10.0
+	labelPadding  = 5.0
+	labelHeight   = 34.0
+	laneGap       = 6.0
+	axisGap       = 14.0
+	tickLabelGap  = 30.0
+	markerRadius  = 5.0
+	leaderMargin  = 3.0
+	legendSwatch  = 10.0
+	legendSpacing = 18.0
+	maxNameWidth  = 220.0
+	// minTickSpacing is the distance ticks of the axis keep at least, so that their labels do not touch.
+	minTickSpacing = 90.0
+)
+
+// Options tune the chart.
+type Options struct {
+	// Title is drawn above the chart, nothing is drawn if it is empty.
+	Title string
+	// Width of the chart, DefaultWidth if zero. Labels of the last events may widen it, the height follows from
+	// the events.
+	Width float64
+}
+
+// Chart is the graphic of a timeline: a horizontal time axis with the events marked in the color of their type
+// and labeled with their name and date. Labels are stacked in lanes above and below the axis so that neither they
+// nor the lines leading to them overlap. A legend of the types lists their colors.
+type Chart struct {
+	Width, Height float64
+
+	title   string
+	titleY  float64
+	axisY   float64
+	axisX0  float64
+	axisX1  float64
+	ticks   []tick
+	items   []item
+	legend  []legendEntry
+	lanes   int
+	message string
+}
+
+type tick struct {
+	X     float64
+	Label string
+}
+
+// item is an event with its marker on the axis and its label.
+type item struct {
+	X       float64
+	Color   string
+	Name    string
+	Date    string
+	LabelX  float64
+	LabelY  float64
+	LabelW  float64
+	Above   bool
+	LeaderY float64
+	Level   int
+}
+
+type legendEntry struct {
+	X, Y  float64
+	Color string
+	Name  string
+}
+
+// interval is a horizontal range taken in a lane by a label or by a line leading to a label further out.
+type interval struct {
+	from, to float64
+}
+
+// New lays out a chart of events. Types give the events their color and legend, their effective color is used
+// if set and their own color otherwise.
+func New(types []timeline.Type, events []timeline.Event, opts Options) *Chart {
+	c := &Chart{Width: opts.Width, title: strings.TrimSpace(opts.Title)}
+	if c.Width <= 0 {
+		c.Width = DefaultWidth
+	}
+	c.axisX0, c.axisX1 = margin, c.Width-margin
+
+	events = append([]timeline.Event(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].EventTime.Equal(events[j].EventTime) {
+			return events[i].EventTime.Before(events[j].EventTime)
+		}
+		return events[i].ID < events[j].ID
+	})
+
+	colors, names := make(map[uint]string), make(map[uint]string)
+	for _, t := range types {
+		names[t.ID] = t.Name
+		if colors[t.ID] = t.EffectiveColor; colors[t.ID] == "" {
+			colors[t.ID] = t.Color
+		}
+	}
+
+	y := margin
+	if c.title != "" {
+		c.titleY = y + titleSize
+		y += titleSize + 2*laneGap
+	}
+	if len(events) == 0 {
+		c.message = "No events"
+		c.axisY = y + axisGap
+		c.Height = c.axisY + tickLabelGap + margin
+		return c
+	}
+
+	start, end, step := axisRange(events[0].EventTime, events[len(events)-1].EventTime, (c.axisX1-c.axisX0)/minTickSpacing)
+	xOf := func(t time.Time) float64 {
+		return c.axisX0 + float64(t.Unix()-start.Unix())/float64(end.Unix()-start.Unix())*(c.axisX1-c.axisX0)
+	}
+	for t := start; !t.After(end); t = step.next(t) {
+		c.ticks = append(c.ticks, tick{X: xOf(t), Label: t.Format(step.layout)})
+	}
+
+	used := map[uint]bool{}
+	for _, e := range events {
+		it := item{
+			X:     xOf(e.EventTime),
+			Color: colors[e.TypeID],
+			Name:  truncate(e.Name, nameSize, maxNameWidth),
+			Date:  e.EventTime.Format("2 Jan 2006"),
+		}
+		if it.Color == "" {
+			it.Color = defaultColor
+		}
+		if e.TypeID != 0 && names[e.TypeID] != "" {
+			used[e.TypeID] = true
+		}
+		it.LabelW = maxFloat(textWidth(it.Name, nameSize), textWidth(it.Date, dateSize)) + 2*labelPadding
+		// labels hang from the line to their marker like flags
+		it.LabelX = it.X
+		c.items = append(c.items, it)
+	}
+	c.placeLabels()
+
+	levelsAbove, levelsBelow := (c.lanes+1)/2, c.lanes/2
+	rowHeight := labelHeight + laneGap
+	c.axisY = y + float64(levelsAbove)*rowHeight + axisGap
+	for i := range c.items {
+		it := &c.items[i]
+		level := float64(it.Level)
+		if it.Above {
+			it.LabelY = c.axisY - axisGap - level*rowHeight - labelHeight
+			it.LeaderY = it.LabelY + labelHeight
+		} else {
+			it.LabelY = c.axisY + tickLabelGap + level*rowHeight
+			it.LeaderY = it.LabelY
+		}
+	}
+	y = c.axisY + tickLabelGap + float64(levelsBelow)*rowHeight + laneGap
+
+	// the legend lists the types of the charted events in the order they were given
+	x := margin
+	for _, t := range types {
+		if !used[t.ID] {
+			continue
+		}
+		w := legendSwatch + laneGap + textWidth(t.Name, dateSize) + legendSpacing
+		if x > margin && x+w > c.Width-margin {
+			x, y = margin, y+legendSpacing
+		}
+		color := colors[t.ID]
+		if color == "" {
+			color = defaultColor
+		}
+		c.legend = append(c.legend, legendEntry{X: x, Y: y, Color: color, Name: t.Name})
+		x += w
+	}
+	if len(c.legend) > 0 {
+		y += legendSpacing
+	}
+	c.Height = y + margin - laneGap
+	return c
+}
+
+// placeLabels stacks the labels in lanes, widening the chart for labels running past the end of the axis.
+// Labels are placed from the last event on, so that the labels placed before cover none of the markers left and
+// the line to each label is only ever blocked by the lines taken in the lanes it passes.
+func (c *Chart) placeLabels() {
+	var lanes [][]interval
+	for i := len(c.items) - 1; i >= 0; i-- {
+		it := &c.items[i]
+		lane := placeLabel(&lanes, it.X, it.LabelX, it.LabelX+it.LabelW)
+		it.Above, it.Level = lane%2 == 0, lane/2
+		c.Width = maxFloat(c.Width, it.LabelX+it.LabelW+margin/2)
+	}
+	c.lanes = len(lanes)
+}
+
+// placeLabel finds the innermost lane where a label spanning from..to fits, lanes alternate above and below the
+// axis. The line from the marker at x to the label passes the inner lanes on its side, which are taken at x so
+// that no later label covers it.
+func placeLabel(lanes *[][]interval, x, from, to float64) int {
+	for lane := 0; ; lane++ {
+		if lane == len(*lanes) {
+			*lanes = append(*lanes, nil)
+		}
+		if !free((*lanes)[lane], from, to) {
+			continue
+		}
+		(*lanes)[lane] = append((*lanes)[lane], interval{from - laneGap/2, to + laneGap/2})
+		for inner := lane - 2; inner >= 0; inner -= 2 {
+			(*lanes)[inner] = append((*lanes)[inner], interval{x - leaderMargin, x + leaderMargin})
+		}
+		return lane
+	}
+}
+
+func free(taken []interval, from, to float64) bool {
+	for _, t := range taken {
+		if from < t.to && t.from < to {
+			return false
+		}
+	}
+	return true
+}
+
+// truncate shortens text to the given width, ending it with an ellipsis.
+func truncate(text string, size, width float64) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if textWidth(text, size) <= width {
+		return text
+	}
+	for text != "" && textWidth(text+"…", size) > width {
+		_, n := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-n]
+	}
+	return strings.TrimSpace(text) + "…"
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// axisStep is the distance between ticks of the axis, in years, months or days.
+type axisStep struct {
+	years, months, days int
+	layout              string
+}
+
+func (s axisStep) next(t time.Time) time.Time {
+	return t.AddDate(s.years, s.months, s.days)
+}
+
+// floor returns the tick at or before t.
+func (s axisStep) floor(t time.Time) time.Time {
+	switch {
+	case s.years > 0:
+		return time.Date(floorDiv(t.Year(), s.years)*s.years, 1, 1, 0, 0, 0, 0, time.UTC)
+	case s.months > 0:
+		return time.Date(t.Year(), time.Month((int(t.Month())-1)/s.months*s.months+1), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// axisSteps are the candidate steps from the finest to the coarsest.
+var axisSteps = func() []axisStep {
+	steps := []axisStep{
+		{days: 1, layout: "2 Jan"},
+		{days: 2, layout: "2 Jan"},
+		{days: 7, layout: "2 Jan"},
+		{days: 14, layout: "2 Jan"},
+		{months: 1, layout: "Jan 2006"},
+		{months: 3, layout: "Jan 2006"},
+		{months: 6, layout: "Jan 2006"},
+	}
+	for scale := 1; scale <= 1000; scale *= 10 {
+		for _, n := range []int{1, 2, 5} {
+			steps = append(steps, axisStep{years: n * scale, layout: "2006"})
+		}
+	}
+	return steps
+}()
+
+// axisRange picks the finest step giving at most maxTicks intervals between the events, and the ticks at or
+// around the first and last event that the axis spans.
+func axisRange(first, last time.Time, maxTicks float64) (time.Time, time.Time, axisStep) {
+	first, last = first.UTC(), last.UTC()
+	step := axisSteps[len(axisSteps)-1]
+	for _, s := range axisSteps {
+		start, end := s.floor(first), s.floor(last)
+		if end.Before(last) || end.Equal(start) {
+			end = s.next(end)
+		}
+		if intervals(start, end, s) <= maxTicks {
+			step = s
+			break
+		}
+	}
+	start, end := step.floor(first), step.floor(last)
+	if end.Before(last) || end.Equal(start) {
+		end = step.next(end)
+	}
+	return start, end, step
+}
+
+func intervals(start, end time.Time, step axisStep) float64 {
+	n := 0.0
+	for t := start; t.Before(end); t = step.next(t) {
+		if n++; n > 1000 {
+			break
+		}
+	}
+	return n
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,175 @@
+package chart
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleChart() *Chart {
+	types := []timeline.Type{
+		{ID: 1, Name: "Mission", Color: "#1f77b4", EffectiveColor: "#1f77b4"},
+		{ID: 2, Name: "Soviet", EffectiveColor: "crimson"},
+		{ID: 3, Name: "Unused", EffectiveColor: "#000000"},
+	}
+	events := []timeline.Event{
+		{ID: 1, Name: "Apollo 11 <landing>", EventTime: time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC), TypeID: 1},
+		{ID: 2, Name: "Sputnik 1", EventTime: time.Date(1957, 10, 4, 0, 0, 0, 0, time.UTC), TypeID: 2},
+		{ID: 3, Name: "Apollo 8", EventTime: time.Date(1968, 12, 21, 0, 0, 0, 0, time.UTC), TypeID: 1},
+		{ID: 4, Name: "Untyped", EventTime: time.Date(1961, 4, 12, 0, 0, 0, 0, time.UTC)},
+	}
+	return New(types, events, Options{Title: "Space race & more"})
+}
+
+func TestAxisRange(t *testing.T) {
+	tests := []struct {
+		first, last time.Time
+		ticks       float64
+		start, end  string
+	}{
+		{time.Date(1957, 10, 4, 0, 0, 0, 0, time.UTC), time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC), 12, "1956-01-01", "1970-01-01"},
+		{time.Date(1969, 7, 16, 13, 32, 0, 0, time.UTC), time.Date(1969, 7, 24, 16, 50, 0, 0, time.UTC), 12, "1969-07-16", "1969-07-25"},
+		{time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC), time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC), 12, "1969-07-20", "1969-07-21"},
+		{time.Date(-2560, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC), 12, "-3000-01-01", "2000-01-01"},
+	}
+	for _, tt := range tests {
+		start, end, step := axisRange(tt.first, tt.last, tt.ticks)
+		require.Equal(t, tt.start, start.Format("2006-01-02"))
+		require.Equal(t, tt.end, end.Format("2006-01-02"))
+		require.LessOrEqual(t, intervals(start, end, step), tt.ticks)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "Apollo 11", truncate("  Apollo\n11 ", nameSize, maxNameWidth))
+	long := truncate(strings.Repeat("Apollo ", 20), nameSize, 100)
+	require.True(t, strings.HasSuffix(long, "…"))
+	require.LessOrEqual(t, textWidth(long, nameSize), 100.0)
+}
+
+// TestLabelsDoNotOverlap lays out crowded timelines and checks that no two labels overlap and that the lines
+// leading to labels pass no other label.
+func TestLabelsDoNotOverlap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1969))
+	for run := 0; run < 20; run++ {
+		var events []timeline.Event
+		base := time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 40; i++ {
+			events = append(events, timeline.Event{
+				ID:        uint(i + 1),
+				Name:      strings.Repeat("Event ", 1+rnd.Intn(5)) + strconv.Itoa(i),
+				EventTime: base.Add(time.Duration(rnd.Int63n(int64(30 * 365 * 24 * time.Hour)))),
+			})
+		}
+		c := New(nil, events, Options{})
+		require.Len(t, c.items, len(events))
+
+		for i, a := range c.items {
+			require.GreaterOrEqual(t, a.LabelX, 0.0)
+			require.LessOrEqual(t, a.LabelX+a.LabelW, c.Width)
+			for j, b := range c.items {
+				if i == j {
+					continue
+				}
+				overlap := a.LabelX < b.LabelX+b.LabelW && b.LabelX < a.LabelX+a.LabelW &&
+					a.LabelY < b.LabelY+labelHeight && b.LabelY < a.LabelY+labelHeight
+				require.False(t, overlap, "labels of %q and %q overlap", a.Name, b.Name)
+
+				top, bottom := a.LeaderY, c.axisY
+				if !a.Above {
+					top, bottom = c.axisY, a.LeaderY
+				}
+				crossed := a.X > b.LabelX && a.X < b.LabelX+b.LabelW && top < b.LabelY+labelHeight && b.LabelY < bottom
+				require.False(t, crossed, "the line to %q crosses the label of %q", a.Name, b.Name)
+			}
+		}
+	}
+}
+
+func TestLabelsAtTheSameTime(t *testing.T) {
+	var events []timeline.Event
+	for i := 0; i < 10; i++ {
+		events = append(events, timeline.Event{ID: uint(i + 1), Name: "Launch", EventTime: time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC)})
+	}
+	c := New(nil, events, Options{})
+	require.Len(t, c.items, 10)
+	require.Greater(t, c.Height, 10*labelHeight)
+}
+
+func TestWriteSVG(t *testing.T) {
+	c := sampleChart()
+	// the label of the last event runs past the end of the axis
+	require.Greater(t, c.Width, float64(DefaultWidth))
+	var b bytes.Buffer
+	require.NoError(t, c.WriteSVG(&b))
+	svg := b.String()
+
+	require.True(t, strings.HasPrefix(svg, fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s"`, num(c.Width), num(c.Height))))
+	require.Contains(t, svg, "<title>Space race &amp; more</title>")
+	require.Contains(t, svg, ">Apollo 11 &lt;landing&gt;</text>")
+	require.Contains(t, svg, ">20 Jul 1969</text>")
+	require.Contains(t, svg, `fill="crimson"`)
+	require.Contains(t, svg, `fill="`+defaultColor+`"`)
+	require.Contains(t, svg, ">Mission</text>")
+	require.NotContains(t, svg, "Unused")
+	require.True(t, strings.HasSuffix(svg, "</svg>\n"))
+}
+
+func TestWriteSVGWithoutEvents(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, New(nil, nil, Options{}).WriteSVG(&b))
+	require.Contains(t, b.String(), ">No events</text>")
+	require.NotContains(t, b.String(), "<title>")
+}
+
+func TestWritePDF(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, sampleChart().WritePDF(&b))
+	pdf := b.Bytes()
+
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+
+	// the cross-reference table points at the objects
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n0 8\n")))
+	entries := strings.Split(string(pdf[xref:]), "\n")[3:10]
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[:10])
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+	require.Contains(t, string(pdf), "/Title (Space race & more)")
+
+	start := bytes.Index(pdf, []byte("stream\n")) + len("stream\n")
+	end := bytes.Index(pdf, []byte("\nendstream"))
+	z, err := zlib.NewReader(bytes.NewReader(pdf[start:end]))
+	require.NoError(t, err)
+	content, err := io.ReadAll(z)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "(Apollo 11 <landing>) Tj")
+	require.Contains(t, string(content), "0.863 0.078 0.235 rg") // crimson
+}
+
+func TestPDFString(t *testing.T) {
+	require.Equal(t, `(Jos\351 \(b\\c\) \205 ?)`, pdfString("José (b\\c) … 月"))
+}
+
+func TestPDFColor(t *testing.T) {
+	require.Equal(t, "1.000 0.000 0.000", pdfColor("#f00"))
+	require.Equal(t, "0.122 0.467 0.706", pdfColor("#1f77b480"))
+	require.Equal(t, "0.000 0.000 1.000", pdfColor("blue"))
+	require.Equal(t, pdfColor(defaultColor), pdfColor("rgb(1, 2, 3)"))
+}
@@ -0,0 +1,49 @@
+package chart
+
+// canvas is drawn on by charts, with the origin at the top left corner and y growing downwards.
+type canvas interface {
+	line(x1, y1, x2, y2, width float64, color string)
+	rect(x, y, w, h float64, fill, stroke string)
+	circle(x, y, r float64, fill, stroke string)
+	// text draws a line of text starting at x on the baseline y, in bold for titles.
+	text(x, y, size float64, color, text string, bold bool)
+}
+
+const (
+	textColor  = "#131313"
+	mutedColor = "#666666"
+	axisColor  = "#444444"
+	labelFill  = "#ffffff"
+)
+
+// draw paints the chart, SVG images and PDF documents draw the same shapes.
+func (c *Chart) draw(cv canvas) {
+	if c.title != "" {
+		cv.text(margin, c.titleY, titleSize, textColor, c.title, true)
+	}
+	cv.line(c.axisX0, c.axisY, c.axisX1, c.axisY, 1.5, axisColor)
+	for _, t := range c.ticks {
+		cv.line(t.X, c.axisY, t.X, c.axisY+5, 1, axisColor)
+		cv.text(t.X-textWidth(t.Label, tickSize)/2, c.axisY+5+tickSize+2, tickSize, mutedColor, t.Label, false)
+	}
+	if c.message != "" {
+		cv.text((c.Width-textWidth(c.message, nameSize))/2, c.axisY-axisGap/2, nameSize, mutedColor, c.message, false)
+	}
+
+	for _, it := range c.items {
+		cv.line(it.X, c.axisY, it.X, it.LeaderY, 1, it.Color)
+	}
+	for _, it := range c.items {
+		cv.rect(it.LabelX, it.LabelY, it.LabelW, labelHeight, labelFill, it.Color)
+		cv.text(it.LabelX+labelPadding, it.LabelY+labelPadding+nameSize-2, nameSize, textColor, it.Name, false)
+		cv.text(it.LabelX+labelPadding, it.LabelY+labelHeight-labelPadding-1, dateSize, mutedColor, it.Date, false)
+	}
+	for _, it := range c.items {
+		cv.circle(it.X, c.axisY, markerRadius, it.Color, labelFill)
+	}
+
+	for _, l := range c.legend {
+		cv.rect(l.X, l.Y, legendSwatch, legendSwatch, l.Color, l.Color)
+		cv.text(l.X+legendSwatch+laneGap, l.Y+legendSwatch-1, dateSize, textColor, l.Name, false)
+	}
+}
@@ -0,0 +1,187 @@
+package chart
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"golang.org/x/image/colornames"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pages of PDF documents are A4 in landscape, in points.
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 28.0
+)
+
+// WritePDF writes the chart as a single page PDF document, scaled to fit the page. The text is set in the standard
+// Helvetica font, which PDF readers provide, so that no font needs to be embedded. Characters outside of the
+// Windows-1252 encoding of the font are replaced by question marks.
+func (c *Chart) WritePDF(w io.Writer) error {
+	scale := (pdfPageWidth - 2*pdfMargin) / c.Width
+	if s := (pdfPageHeight - 2*pdfMargin) / c.Height; s < scale {
+		scale = s
+	}
+	cv := &pdfCanvas{
+		scale: scale,
+		x:     (pdfPageWidth - c.Width*scale) / 2,
+		y:     pdfPageHeight - (pdfPageHeight-c.Height*scale)/2,
+	}
+	c.draw(cv)
+
+	var content bytes.Buffer
+	z := zlib.NewWriter(&content)
+	if _, err := z.Write(cv.b.Bytes()); err != nil {
+		return err
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+
+	title := c.title
+	if title == "" {
+		title = "Timeline"
+	}
+	doc := pdfDocument{}
+	doc.add("<< /Type /Catalog /Pages 2 0 R >>")
+	doc.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	doc.add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+		"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", num(pdfPageWidth), num(pdfPageHeight)))
+	doc.add(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	doc.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	doc.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	doc.add(fmt.Sprintf("<< /Title %s /Producer (go-timeline) >>", pdfString(title)))
+	_, err := w.Write(doc.bytes())
+	return err
+}
+
+// pdfDocument assembles the objects of a document, numbered from 1 in the order they are added.
+type pdfDocument struct {
+	b       bytes.Buffer
+	offsets []int
+}
+
+func (d *pdfDocument) add(object string) {
+	if d.b.Len() == 0 {
+		// the comment of binary bytes tells transfer programs that the file is binary
+		d.b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	}
+	d.offsets = append(d.offsets, d.b.Len())
+	fmt.Fprintf(&d.b, "%d 0 obj\n%s\nendobj\n", len(d.offsets), object)
+}
+
+// bytes completes the document with the cross-reference table and the trailer, the last object added holds the
+// document information.
+func (d *pdfDocument) bytes() []byte {
+	xref := d.b.Len()
+	fmt.Fprintf(&d.b, "xref\n0 %d\n0000000000 65535 f \n", len(d.offsets)+1)
+	for _, offset := range d.offsets {
+		fmt.Fprintf(&d.b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&d.b, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.offsets)+1, len(d.offsets), xref)
+	return d.b.Bytes()
+}
+
+// pdfCanvas writes the content stream of the page. Chart coordinates are scaled and moved to the page, whose
+// origin is at the bottom left corner.
+type pdfCanvas struct {
+	b     bytes.Buffer
+	scale float64
+	x, y  float64
+}
+
+func (p *pdfCanvas) point(x, y float64) string {
+	return num(p.x+x*p.scale) + " " + num(p.y-y*p.scale)
+}
+
+func (p *pdfCanvas) line(x1, y1, x2, y2, width float64, color string) {
+	fmt.Fprintf(&p.b, "%s RG %s w %s m %s l S\n", pdfColor(color), num(width*p.scale), p.point(x1, y1), p.point(x2, y2))
+}
+
+func (p *pdfCanvas) rect(x, y, w, h float64, fill, stroke string) {
+	fmt.Fprintf(&p.b, "%s rg %s RG %s w %s %s %s re B\n", pdfColor(fill), pdfColor(stroke), num(p.scale),
+		p.point(x, y+h), num(w*p.scale), num(h*p.scale))
+}
+
+// circle draws four Bézier curves, the control points are at the usual distance of 0.5523 radii.
+func (p *pdfCanvas) circle(x, y, r float64, fill, stroke string) {
+	k := 0.5523 * r
+	fmt.Fprintf(&p.b, "%s rg %s RG %s w %s m\n", pdfColor(fill), pdfColor(stroke), num(1.5*p.scale), p.point(x+r, y))
+	fmt.Fprintf(&p.b, "%s %s %s c\n", p.point(x+r, y+k), p.point(x+k, y+r), p.point(x, y+r))
+	fmt.Fprintf(&p.b, "%s %s %s c\n", p.point(x-k, y+r), p.point(x-r, y+k), p.point(x-r, y))
+	fmt.Fprintf(&p.b, "%s %s %s c\n", p.point(x-r, y-k), p.point(x-k, y-r), p.point(x, y-r))
+	fmt.Fprintf(&p.b, "%s %s %s c\nB\n", p.point(x+k, y-r), p.point(x+r, y-k), p.point(x+r, y))
+}
+
+func (p *pdfCanvas) text(x, y, size float64, color, text string, bold bool) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.b, "BT %s rg /%s %s Tf %s Td %s Tj ET\n", pdfColor(color), font, num(size*p.scale), p.point(x, y), pdfString(text))
+}
+
+// pdfColor returns the operands of a color operator for a CSS color, which the type service normalizes to hex
+// colors or names. Other colors are drawn in the default color.
+func pdfColor(color string) string {
+	r, g, b, ok := parseColor(color)
+	if !ok {
+		r, g, b, _ = parseColor(defaultColor)
+	}
+	channel := func(v uint8) string {
+		return strconv.FormatFloat(float64(v)/255, 'f', 3, 64)
+	}
+	return channel(r) + " " + channel(g) + " " + channel(b)
+}
+
+func parseColor(color string) (r, g, b uint8, ok bool) {
+	c := strings.ToLower(strings.TrimSpace(color))
+	if named, ok := colornames.Map[c]; ok {
+		return named.R, named.G, named.B, true
+	}
+	c = strings.TrimPrefix(c, "#")
+	if len(c) == 3 || len(c) == 4 {
+		c = string([]byte{c[0], c[0], c[1], c[1], c[2], c[2]})
+	}
+	if len(c) != 6 && len(c) != 8 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(c[:6], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// winAnsiSpecials are the characters of Windows-1252 outside of Latin-1, which it shares otherwise.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a,
+	'‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfString encodes text as a literal string in Windows-1252.
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r <= '~':
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsiSpecials[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsiSpecials[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
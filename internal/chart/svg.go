@@ -0,0 +1,60 @@
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+)
+
+// svgFonts asks for Helvetica, which labels are measured in, or a sans-serif font of similar width.
+const svgFonts = "Helvetica, Arial, sans-serif"
+
+// WriteSVG writes the chart as an SVG image.
+func (c *Chart) WriteSVG(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s" font-family="%s">`+"\n",
+		num(c.Width), num(c.Height), svgFonts)
+	if c.title != "" {
+		fmt.Fprintf(b, "<title>%s</title>\n", html.EscapeString(c.title))
+	}
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", labelFill)
+	c.draw(svgCanvas{b})
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+func (s svgCanvas) line(x1, y1, x2, y2, width float64, color string) {
+	fmt.Fprintf(s.w, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"/>`+"\n",
+		num(x1), num(y1), num(x2), num(y2), html.EscapeString(color), num(width))
+}
+
+func (s svgCanvas) rect(x, y, w, h float64, fill, stroke string) {
+	fmt.Fprintf(s.w, `<rect x="%s" y="%s" width="%s" height="%s" rx="3" fill="%s" stroke="%s"/>`+"\n",
+		num(x), num(y), num(w), num(h), html.EscapeString(fill), html.EscapeString(stroke))
+}
+
+func (s svgCanvas) circle(x, y, r float64, fill, stroke string) {
+	fmt.Fprintf(s.w, `<circle cx="%s" cy="%s" r="%s" fill="%s" stroke="%s" stroke-width="1.5"/>`+"\n",
+		num(x), num(y), num(r), html.EscapeString(fill), html.EscapeString(stroke))
+}
+
+func (s svgCanvas) text(x, y, size float64, color, text string, bold bool) {
+	weight := ""
+	if bold {
+		weight = ` font-weight="bold"`
+	}
+	fmt.Fprintf(s.w, `<text x="%s" y="%s" font-size="%s" fill="%s"%s>%s</text>`+"\n",
+		num(x), num(y), num(size), html.EscapeString(color), weight, html.EscapeString(text))
+}
+
+// num formats coordinates with two decimals at most.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
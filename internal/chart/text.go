@@ -0,0 +1,35 @@
+package chart
+
+// helveticaWidths are the advances of the printable ASCII characters in Helvetica, in thousandths of the font size,
+// from the metrics of the standard PDF fonts. Charts measure their labels with them, PDF documents use the font
+// and SVG images ask for it or a similar sans-serif font.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0 to 9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A to M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N to Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a to m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n to z
+	334, 260, 334, 584, // { to ~
+}
+
+// averageWidth is assumed for characters without metrics, wide enough for most Latin letters.
+const averageWidth = 611
+
+// textWidth returns the width of text set in Helvetica at the given size.
+func textWidth(text string, size float64) float64 {
+	w := 0
+	for _, r := range text {
+		switch {
+		case r >= ' ' && r <= '~':
+			w += helveticaWidths[r-' ']
+		case r == '…':
+			w += 1000
+		default:
+			w += averageWidth
+		}
+	}
+	return float64(w) * size / 1000
+}
@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"github.com/kamkali/go-timeline/internal/chart"
+	"github.com/kamkali/go-timeline/internal/timeline"
+)
+
+// Graphic export formats, drawing the events on a time axis.
+const (
+	FormatSVG = "svg"
+	FormatPDF = "pdf"
+)
+
+// chartExportWriter collects the events, as the chart is laid out once all of them are known.
+type chartExportWriter struct {
+	exportWriter
+	format string
+	title  string
+	types  []timeline.Type
+	events []timeline.Event
+}
+
+func (c *chartExportWriter) WriteTypes(types []timeline.Type) error {
+	c.types = types
+	return nil
+}
+
+func (c *chartExportWriter) WriteTags([]timeline.Tag) error {
+	return nil
+}
+
+func (c *chartExportWriter) WriteEvent(ev timeline.Event) error {
+	// charts do not show graphics, inlined ones would be kept in memory until the export is closed
+	ev.Graphic = ""
+	c.events = append(c.events, ev)
+	return nil
+}
+
+func (c *chartExportWriter) Close() error {
+	ch := chart.New(c.types, c.events, chart.Options{Title: c.title})
+	var err error
+	if c.format == FormatPDF {
+		err = ch.WritePDF(c.w)
+	} else {
+		err = ch.WriteSVG(c.w)
+	}
+	if err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
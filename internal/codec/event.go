@@ -17,8 +17,10 @@ func HTTPToDomainEvent(e *schema.Event) (*timeline.Event, error) {
 		EventTime:           parsedTime,
 		ShortDescription:    e.ShortDescription,
 		DetailedDescription: e.DetailedDescription,
+		DescriptionFormat:   timeline.DescriptionFormat(e.DescriptionFormat),
 		Graphic:             e.Graphic,
 		TypeID:              e.TypeID,
+		Tags:                e.Tags,
 	}
 
 	return domainEvent, nil
@@ -31,8 +33,19 @@ func HTTPFromDomainEvent(e *timeline.Event) (*schema.Event, error) {
 		EventTime:           e.EventTime.Format(time.RFC3339),
 		ShortDescription:    e.ShortDescription,
 		DetailedDescription: e.DetailedDescription,
+		DescriptionFormat:   string(e.DescriptionFormat),
 		Graphic:             e.Graphic,
+		GraphicThumbnail:    timeline.MediaVariantPath(e.Graphic, timeline.MediaVariantThumbnail),
+		GraphicMedium:       timeline.MediaVariantPath(e.Graphic, timeline.MediaVariantMedium),
 		TypeID:              e.TypeID,
+		Tags:                e.Tags,
+		ExternalID:          e.ExternalID,
+	}
+	if !e.CreatedAt.IsZero() {
+		httpEvent.CreatedAt = e.CreatedAt.Format(time.RFC3339)
+	}
+	if !e.UpdatedAt.IsZero() {
+		httpEvent.UpdatedAt = e.UpdatedAt.Format(time.RFC3339)
 	}
 
 	return httpEvent, nil
@@ -4,6 +4,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/kamkali/go-timeline/internal/server/schema"
 	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
 	"time"
@@ -97,3 +98,16 @@ func TestHTTPFromDomainEvent(t *testing.T) {
 		t.Errorf("Expected %+v, got %+v", expected, httpEvent)
 	}
 }
+
+func TestHTTPFromDomainEventMediaVariants(t *testing.T) {
+	e := &timeline.Event{
+		ID:        1,
+		EventTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Graphic:   "/media/abcd.webp",
+	}
+
+	httpEvent, err := HTTPFromDomainEvent(e)
+	require.NoError(t, err)
+	require.Equal(t, "/media/abcd-thumbnail.png", httpEvent.GraphicThumbnail)
+	require.Equal(t, "/media/abcd-medium.png", httpEvent.GraphicMedium)
+}
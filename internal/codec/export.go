@@ -0,0 +1,278 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of NDJSON export records.
+const (
+	exportKindType  = "type"
+	exportKindTag   = "tag"
+	exportKindEvent = "event"
+)
+
+// ExportContentType returns the media type of exports in the given format.
+func ExportContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatICS:
+		return "text/calendar; charset=utf-8"
+	case FormatSVG:
+		return "image/svg+xml"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/json"
+	}
+}
+
+// ExportFileExtension returns the extension of files holding exports in the given format.
+func ExportFileExtension(format string) string {
+	if format == FormatTimelineJS {
+		return FormatJSON
+	}
+	return format
+}
+
+// ExportOptions tune exports in formats they apply to.
+type ExportOptions struct {
+	// BaseURL makes paths of stored media absolute in TimelineJS exports.
+	BaseURL string
+	// Title heads SVG and PDF exports, "Timeline" if empty.
+	Title string
+}
+
+// NewExportWriter returns a writer encoding exports in the given format. CSV, iCalendar and TimelineJS exports
+// hold the events only, SVG and PDF exports draw them. The writer buffers its output, which is flushed on Close.
+func NewExportWriter(format string, w io.Writer, opts ExportOptions) (timeline.ExportWriter, error) {
+	base := exportWriter{w: bufio.NewWriter(w), typeNames: make(map[uint]string)}
+	switch format {
+	case FormatCSV:
+		return &csvExportWriter{exportWriter: base}, nil
+	case FormatJSON:
+		return &jsonExportWriter{exportWriter: base}, nil
+	case FormatNDJSON:
+		return &ndjsonExportWriter{exportWriter: base}, nil
+	case FormatICS:
+		return &icalExportWriter{exportWriter: base, stamp: time.Now().UTC().Format(icalDateTimeLayout) + "Z"}, nil
+	case FormatTimelineJS:
+		return &timelineJSExportWriter{exportWriter: base, baseURL: opts.BaseURL}, nil
+	case FormatSVG, FormatPDF:
+		title := opts.Title
+		if title == "" {
+			title = "Timeline"
+		}
+		return &chartExportWriter{exportWriter: base, format: format, title: title}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown export format %q", timeline.ErrInvalid, format)
+	}
+}
+
+type exportWriter struct {
+	w         *bufio.Writer
+	typeNames map[uint]string
+}
+
+func (e *exportWriter) exportTypes(types []timeline.Type) []schema.ExportType {
+	for _, t := range types {
+		e.typeNames[t.ID] = t.Name
+	}
+	exported := make([]schema.ExportType, 0, len(types))
+	for _, t := range types {
+		et := schema.ExportType{
+			ID:           t.ID,
+			Name:         t.Name,
+			Color:        t.Color,
+			Icon:         t.Icon,
+			Theme:        t.Theme,
+			DisplayOrder: t.DisplayOrder,
+			ParentID:     t.ParentID,
+		}
+		if t.ParentID != nil {
+			et.Parent = e.typeNames[*t.ParentID]
+		}
+		exported = append(exported, et)
+	}
+	return exported
+}
+
+func (e *exportWriter) exportEvent(ev timeline.Event) schema.ExportEvent {
+	tags := ev.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return schema.ExportEvent{
+		ID:                  ev.ID,
+		ExternalID:          timeline.ExportedExternalID(ev),
+		Name:                ev.Name,
+		EventTime:           ev.EventTime.Format(time.RFC3339),
+		ShortDescription:    ev.ShortDescription,
+		DetailedDescription: ev.DetailedDescription,
+		DescriptionFormat:   string(ev.DescriptionFormat),
+		Graphic:             ev.Graphic,
+		Type:                e.typeNames[ev.TypeID],
+		TypeID:              ev.TypeID,
+		Tags:                tags,
+	}
+}
+
+type csvExportWriter struct {
+	exportWriter
+	csv *csv.Writer
+}
+
+// csvExportColumns precede the import fields, imports ignore them.
+var csvExportColumns = []string{"id"}
+
+func (c *csvExportWriter) WriteTypes(types []timeline.Type) error {
+	c.exportTypes(types)
+	c.csv = csv.NewWriter(c.w)
+	return c.csv.Write(append(append([]string{}, csvExportColumns...), timeline.ImportFields...))
+}
+
+func (c *csvExportWriter) WriteTags([]timeline.Tag) error {
+	return nil
+}
+
+func (c *csvExportWriter) WriteEvent(ev timeline.Event) error {
+	e := c.exportEvent(ev)
+	row := []string{strconv.FormatUint(uint64(e.ID), 10)}
+	for _, field := range timeline.ImportFields {
+		switch field {
+		case timeline.ImportFieldExternalID:
+			row = append(row, e.ExternalID)
+		case timeline.ImportFieldName:
+			row = append(row, e.Name)
+		case timeline.ImportFieldEventTime:
+			row = append(row, e.EventTime)
+		case timeline.ImportFieldShortDescription:
+			row = append(row, e.ShortDescription)
+		case timeline.ImportFieldDetailedDescription:
+			row = append(row, e.DetailedDescription)
+		case timeline.ImportFieldDescriptionFormat:
+			row = append(row, e.DescriptionFormat)
+		case timeline.ImportFieldGraphic:
+			row = append(row, e.Graphic)
+		case timeline.ImportFieldType:
+			row = append(row, e.Type)
+		case timeline.ImportFieldTypeID:
+			row = append(row, strconv.FormatUint(uint64(e.TypeID), 10))
+		case timeline.ImportFieldTags:
+			row = append(row, strings.Join(e.Tags, ImportTagSeparator))
+		}
+	}
+	return c.csv.Write(row)
+}
+
+func (c *csvExportWriter) Close() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+type jsonExportWriter struct {
+	exportWriter
+	events int
+}
+
+func (j *jsonExportWriter) WriteTypes(types []timeline.Type) error {
+	data, err := json.Marshal(j.exportTypes(types))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, "{\"types\":%s", data)
+	return err
+}
+
+func (j *jsonExportWriter) WriteTags(tags []timeline.Tag) error {
+	exported := make([]schema.ExportTag, 0, len(tags))
+	for _, t := range tags {
+		exported = append(exported, schema.ExportTag{Name: t.Name, EventCount: t.EventCount})
+	}
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, ",\"tags\":%s,\"events\":[", data)
+	return err
+}
+
+func (j *jsonExportWriter) WriteEvent(ev timeline.Event) error {
+	data, err := json.Marshal(j.exportEvent(ev))
+	if err != nil {
+		return err
+	}
+	if j.events > 0 {
+		if err := j.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	j.events++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) Close() error {
+	if _, err := j.w.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+type ndjsonExportWriter struct {
+	exportWriter
+}
+
+func (n *ndjsonExportWriter) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := n.w.Write(data); err != nil {
+		return err
+	}
+	return n.w.WriteByte('\n')
+}
+
+func (n *ndjsonExportWriter) WriteTypes(types []timeline.Type) error {
+	for _, t := range n.exportTypes(types) {
+		t.Kind = exportKindType
+		if err := n.writeLine(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonExportWriter) WriteTags(tags []timeline.Tag) error {
+	for _, t := range tags {
+		if err := n.writeLine(schema.ExportTag{Kind: exportKindTag, Name: t.Name, EventCount: t.EventCount}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonExportWriter) WriteEvent(ev timeline.Event) error {
+	e := n.exportEvent(ev)
+	e.Kind = exportKindEvent
+	return n.writeLine(e)
+}
+
+func (n *ndjsonExportWriter) Close() error {
+	return n.w.Flush()
+}
@@ -0,0 +1,151 @@
+package codec
+
+import (
+	"bytes"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	parentID := uint(1)
+	types := []timeline.Type{
+		{ID: 2, Name: "Landing", Color: "#ff0000", Icon: "moon", DisplayOrder: 1, ParentID: &parentID},
+		{ID: 1, Name: "Mission", Color: "blue"},
+	}
+	tags := []timeline.Tag{{ID: 1, Name: "moon", EventCount: 1}, {ID: 2, Name: "crewed", EventCount: 1}}
+	events := []timeline.Event{
+		{
+			ID:                  4,
+			ExternalID:          "apollo-11",
+			Name:                "Apollo 11",
+			EventTime:           time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC),
+			ShortDescription:    "First landing",
+			DetailedDescription: "Quotes \", commas, and\nnew lines",
+			Graphic:             "/media/abcd.webp",
+			TypeID:              2,
+			Tags:                []string{"moon", "crewed"},
+		},
+		{ID: 5, Name: "Apollo 12", EventTime: time.Date(1969, 11, 14, 16, 22, 0, 0, time.UTC), TypeID: 1},
+	}
+
+	// the IDs of events are not imported, the external IDs of exports refer to them
+	eventsByID := make(map[uint]timeline.Event)
+	for _, e := range events {
+		eventsByID[e.ID] = e
+	}
+	importedIDs := map[string]uint{"apollo-11": 4, "event-5": 5}
+	typeNames := make(map[uint]string)
+	for _, dt := range types {
+		typeNames[dt.ID] = dt.Name
+	}
+
+	for _, format := range []string{FormatCSV, FormatJSON, FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewExportWriter(format, &buf, ExportOptions{})
+			require.NoError(t, err)
+			require.NoError(t, w.WriteTypes(types))
+			require.NoError(t, w.WriteTags(tags))
+			for _, e := range events {
+				require.NoError(t, w.WriteEvent(e))
+			}
+			require.NoError(t, w.Close())
+
+			file, err := ParseImport(format, &buf, ImportParseOptions{})
+			require.NoError(t, err)
+			if format != FormatCSV {
+				require.Equal(t, []timeline.ImportType{
+					{Line: file.Types[0].Line, Type: timeline.Type{Name: "Landing", Color: "#ff0000", Icon: "moon", DisplayOrder: 1}, ParentName: "Mission"},
+					{Line: file.Types[1].Line, Type: timeline.Type{Name: "Mission", Color: "blue"}},
+				}, file.Types)
+			}
+			require.Len(t, file.Records, len(events))
+			seen := make(map[uint]bool)
+			for _, rec := range file.Records {
+				require.NoError(t, rec.Err)
+				id, ok := importedIDs[rec.Event.ExternalID]
+				require.True(t, ok, "unknown external ID %q", rec.Event.ExternalID)
+				require.False(t, seen[id], "event %d imported twice", id)
+				seen[id] = true
+
+				want := eventsByID[id]
+				want.ID = 0
+				want.ExternalID = rec.Event.ExternalID
+				if want.Tags == nil {
+					want.Tags = []string{}
+				}
+				require.Equal(t, want, rec.Event)
+				require.Equal(t, typeNames[want.TypeID], rec.TypeName)
+			}
+		})
+	}
+}
+
+func TestParseJSONImportLines(t *testing.T) {
+	input := `{
+  "types": [],
+  "events": [
+    {"name": "Apollo 11", "event_time": "1969-07-20"},
+    {"name": "Apollo 12",
+     "event_time": "soon"}
+  ]
+}`
+	file, err := ParseJSONImport(bytes.NewBufferString(input))
+	require.NoError(t, err)
+	require.Len(t, file.Records, 2)
+	require.Equal(t, 4, file.Records[0].Line)
+	require.Equal(t, 5, file.Records[1].Line)
+	require.ErrorIs(t, file.Records[1].Err, timeline.ErrInvalid)
+
+	_, err = ParseJSONImport(bytes.NewBufferString(`[]`))
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
+
+func TestParseJSONImportTruncated(t *testing.T) {
+	for _, input := range []string{`{"events"`, `{"events":`, `{"events": `, `{"events": [`, `{"events": [{"name": "Apollo 11"}`, `{`, ``} {
+		_, err := ParseJSONImport(bytes.NewBufferString(input))
+		require.ErrorIs(t, err, timeline.ErrInvalid, input)
+		_, err = ParseTimelineJSImport(bytes.NewBufferString(input))
+		require.ErrorIs(t, err, timeline.ErrInvalid, input)
+	}
+}
+
+func TestChartExport(t *testing.T) {
+	types := []timeline.Type{{ID: 1, Name: "Mission", EffectiveColor: "#1f77b4"}}
+	events := []timeline.Event{
+		{ID: 4, Name: "Apollo 11", EventTime: time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC), TypeID: 1},
+		{ID: 5, Name: "Apollo 12", EventTime: time.Date(1969, 11, 14, 16, 22, 0, 0, time.UTC), TypeID: 1},
+	}
+	for format, prefix := range map[string]string{FormatSVG: "<svg ", FormatPDF: "%PDF-"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewExportWriter(format, &buf, ExportOptions{Title: "Apollo program"})
+			require.NoError(t, err)
+			require.NoError(t, w.WriteTypes(types))
+			require.NoError(t, w.WriteTags(nil))
+			for _, e := range events {
+				require.NoError(t, w.WriteEvent(e))
+			}
+			// nothing is written before all events are known
+			require.Zero(t, buf.Len())
+			require.NoError(t, w.Close())
+			require.True(t, bytes.HasPrefix(buf.Bytes(), []byte(prefix)))
+			require.Contains(t, buf.String(), "Apollo program")
+		})
+	}
+	require.Equal(t, "application/pdf", ExportContentType(FormatPDF))
+	require.Equal(t, "svg", ExportFileExtension(FormatSVG))
+}
+
+func TestExportedExternalID(t *testing.T) {
+	require.Equal(t, "apollo-11", timeline.ExportedExternalID(timeline.Event{ID: 4, ExternalID: "apollo-11"}))
+	require.Equal(t, "event-5", timeline.ExportedExternalID(timeline.Event{ID: 5}))
+
+	for externalID, id := range map[string]uint{"event-5": 5, "event-05": 0, "event-": 0, "event-0": 0, "apollo-11": 0, "event-5@go-timeline": 0} {
+		got, ok := timeline.ExportedEventID(externalID)
+		require.Equal(t, id != 0, ok, externalID)
+		require.Equal(t, id, got, externalID)
+	}
+}
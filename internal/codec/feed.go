@@ -0,0 +1,204 @@
+package codec
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/generator"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"strings"
+	"time"
+)
+
+// Feed lists recently created or updated events. URLs are absolute, as feed readers need them.
+type Feed struct {
+	Title   string
+	SiteURL string
+	SelfURL string
+	Events  []timeline.Event
+	// TypeNames are used as the category of entries.
+	TypeNames map[uint]string
+}
+
+// Updated returns when the most recent event of the feed was created or updated, or the zero time for empty feeds.
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, e := range f.Events {
+		if e.UpdatedAt.After(updated) {
+			updated = e.UpdatedAt
+		}
+	}
+	return updated
+}
+
+// FeedEntryID returns the stable identifier of the entry of an event, which does not depend on the host serving it.
+func FeedEntryID(id uint) string {
+	return fmt.Sprintf("tag:%s,2022:event-%d", ICalUIDDomain, id)
+}
+
+// eventURL links entries to the permalink pages of their events.
+func (f Feed) eventURL(e timeline.Event) string {
+	return strings.TrimSuffix(f.SiteURL, "/") + "/" + generator.EventPagePath(e.ID, e.Name)
+}
+
+func (f Feed) categories(e timeline.Event) []string {
+	var categories []string
+	if name := f.TypeNames[e.TypeID]; name != "" {
+		categories = append(categories, name)
+	}
+	return append(categories, e.Tags...)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+// EncodeAtomFeed encodes the feed as an Atom 1.0 document.
+func EncodeAtomFeed(f Feed) ([]byte, error) {
+	updated := f.Updated()
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	feed := atomFeed{
+		ID:      f.SelfURL,
+		Title:   f.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL},
+			{Rel: "alternate", Type: "text/html", Href: f.SiteURL},
+		},
+		Author: atomAuthor{Name: f.Title},
+	}
+	for _, e := range f.Events {
+		entry := atomEntry{
+			ID:      FeedEntryID(e.ID),
+			Title:   e.Name,
+			Updated: e.UpdatedAt.UTC().Format(time.RFC3339),
+			Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: f.eventURL(e)}},
+		}
+		if !e.CreatedAt.IsZero() {
+			entry.Published = e.CreatedAt.UTC().Format(time.RFC3339)
+		}
+		for _, c := range f.categories(e) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		if e.ShortDescription != "" {
+			entry.Summary = &atomText{Type: "text", Body: e.ShortDescription}
+		}
+		content, err := generator.EventDescriptionHTML(e)
+		if err != nil {
+			return nil, err
+		}
+		if content != "" {
+			entry.Content = &atomText{Type: "html", Body: string(content)}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalFeed(feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description,omitempty"`
+}
+
+// EncodeRSSFeed encodes the feed as an RSS 2.0 document. Items are dated by their last update,
+// so that readers show updated events again.
+func EncodeRSSFeed(f Feed) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.SiteURL,
+			Description: "Recently added and updated events of " + f.Title,
+			AtomLink:    atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfURL},
+		},
+	}
+	if updated := f.Updated(); !updated.IsZero() {
+		feed.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, e := range f.Events {
+		description, err := generator.EventDescriptionHTML(e)
+		if err != nil {
+			return nil, err
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Name,
+			Link:        f.eventURL(e),
+			GUID:        rssGUID{Value: fmt.Sprintf("%s#%d", FeedEntryID(e.ID), e.Revision)},
+			PubDate:     e.UpdatedAt.UTC().Format(time.RFC1123Z),
+			Categories:  f.categories(e),
+			Description: string(description),
+		})
+	}
+	return marshalFeed(feed)
+}
+
+func marshalFeed(feed any) ([]byte, error) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
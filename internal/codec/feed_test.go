@@ -0,0 +1,74 @@
+package codec
+
+import (
+	"encoding/xml"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	return Feed{
+		Title:     "Timeline",
+		SiteURL:   "https://example.com/",
+		SelfURL:   "https://example.com/api/events.atom",
+		TypeNames: map[uint]string{1: "Mission"},
+		Events: []timeline.Event{
+			{
+				ID:                  4,
+				Name:                "Apollo 11 <landing>",
+				ShortDescription:    "First crewed landing",
+				DetailedDescription: "One *small* step.\n\nOne giant leap.",
+				DescriptionFormat:   timeline.DescriptionFormatMarkdown,
+				TypeID:              1,
+				Tags:                []string{"moon"},
+				Revision:            2,
+				CreatedAt:           time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC),
+				UpdatedAt:           time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC),
+			},
+			{
+				ID:        5,
+				Name:      "Apollo 12",
+				Revision:  1,
+				CreatedAt: time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestEncodeAtomFeed(t *testing.T) {
+	body, err := EncodeAtomFeed(testFeed())
+	require.NoError(t, err)
+
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(body, &feed))
+	require.Equal(t, "2022-05-03T10:00:00Z", feed.Updated)
+	require.Len(t, feed.Entries, 2)
+	entry := feed.Entries[0]
+	require.Equal(t, "tag:go-timeline,2022:event-4", entry.ID)
+	require.Equal(t, "Apollo 11 <landing>", entry.Title)
+	require.Equal(t, "2022-05-01T10:00:00Z", entry.Published)
+	require.Equal(t, "https://example.com/events/4-apollo-11-landing", entry.Links[0].Href)
+	require.Equal(t, []atomCategory{{Term: "Mission"}, {Term: "moon"}}, entry.Categories)
+	require.Equal(t, "<p>First crewed landing</p>\n<p>One <em>small</em> step.</p>\n<p>One giant leap.</p>\n", entry.Content.Body)
+	require.Nil(t, feed.Entries[1].Content)
+}
+
+func TestEncodeRSSFeed(t *testing.T) {
+	body, err := EncodeRSSFeed(testFeed())
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(body), xml.Header))
+
+	var feed rssFeed
+	require.NoError(t, xml.Unmarshal(body, &feed))
+	require.Equal(t, "Tue, 03 May 2022 10:00:00 +0000", feed.Channel.LastBuildDate)
+	require.Len(t, feed.Channel.Items, 2)
+	item := feed.Channel.Items[0]
+	require.Equal(t, "tag:go-timeline,2022:event-4#2", item.GUID.Value)
+	require.False(t, item.GUID.IsPermaLink)
+	require.Equal(t, "https://example.com/events/4-apollo-11-landing", item.Link)
+	require.Equal(t, []string{"Mission", "moon"}, item.Categories)
+}
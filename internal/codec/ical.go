@@ -0,0 +1,644 @@
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// TZIDs are resolved with the embedded time zone database, the runtime image has none
+	_ "time/tzdata"
+	"unicode/utf8"
+)
+
+const (
+	// ICalUIDDomain names the timeline in the tag URIs identifying feed entries.
+	ICalUIDDomain = "go-timeline"
+	// ICalMaxOccurrences limits the occurrences imported for a single recurring event.
+	ICalMaxOccurrences = 1000
+	// ICalDefaultHorizon bounds the expansion of recurring events when the window is open, both into the past
+	// and into the future.
+	ICalDefaultHorizon = 365 * 24 * time.Hour
+
+	// icalTypeProperty carries the type name of exported events, so that imports restore it.
+	icalTypeProperty = "X-TIMELINE-TYPE"
+
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+	icalLineLimit      = 75
+	icalMaxIterations  = 100000
+)
+
+// ICalEventUID returns the stable UID of an exported event, its exported external ID, so that importing the export
+// updates the events instead of duplicating them.
+func ICalEventUID(e timeline.Event) string {
+	return timeline.ExportedExternalID(e)
+}
+
+type icalExportWriter struct {
+	exportWriter
+	stamp string
+}
+
+func (c *icalExportWriter) writeLine(name, value string) error {
+	line := name + ":" + value
+	// content lines are folded at 75 octets without splitting UTF-8 sequences,
+	// continuation lines start with a space counting towards the limit
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := c.w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		limit = icalLineLimit - 1
+	}
+	_, err := c.w.WriteString(line + "\r\n")
+	return err
+}
+
+func (c *icalExportWriter) WriteTypes(types []timeline.Type) error {
+	c.exportTypes(types)
+	for _, p := range [][2]string{
+		{"BEGIN", "VCALENDAR"},
+		{"VERSION", "2.0"},
+		{"PRODID", "-//go-timeline//Timeline//EN"},
+		{"CALSCALE", "GREGORIAN"},
+		{"X-WR-CALNAME", "Timeline"},
+	} {
+		if err := c.writeLine(p[0], p[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *icalExportWriter) WriteTags([]timeline.Tag) error {
+	return nil
+}
+
+// WriteEvent writes the event as a VEVENT. Events at midnight UTC are taken to be known to the day and
+// become all-day events, the others are instants without a duration.
+func (c *icalExportWriter) WriteEvent(ev timeline.Event) error {
+	props := [][2]string{
+		{"BEGIN", "VEVENT"},
+		{"UID", ICalEventUID(ev)},
+		{"DTSTAMP", c.stamp},
+	}
+	t := ev.EventTime.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		props = append(props,
+			[2]string{"DTSTART;VALUE=DATE", t.Format(icalDateLayout)},
+			[2]string{"DTEND;VALUE=DATE", t.AddDate(0, 0, 1).Format(icalDateLayout)},
+		)
+	} else {
+		props = append(props, [2]string{"DTSTART", t.Format(icalDateTimeLayout) + "Z"})
+	}
+	props = append(props, [2]string{"SUMMARY", icalEscape(ev.Name)})
+	if description := icalDescription(ev); description != "" {
+		props = append(props, [2]string{"DESCRIPTION", icalEscape(description)})
+	}
+	if len(ev.Tags) > 0 {
+		escaped := make([]string, 0, len(ev.Tags))
+		for _, tag := range ev.Tags {
+			escaped = append(escaped, icalEscape(tag))
+		}
+		props = append(props, [2]string{"CATEGORIES", strings.Join(escaped, ",")})
+	}
+	if name := c.typeNames[ev.TypeID]; name != "" {
+		props = append(props, [2]string{icalTypeProperty, icalEscape(name)})
+	}
+	if ev.Revision > 1 {
+		props = append(props, [2]string{"SEQUENCE", strconv.FormatUint(uint64(ev.Revision-1), 10)})
+	}
+	props = append(props, [2]string{"END", "VEVENT"})
+
+	for _, p := range props {
+		if err := c.writeLine(p[0], p[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *icalExportWriter) Close() error {
+	if err := c.writeLine("END", "VCALENDAR"); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// icalDescription joins the descriptions with an empty line, imports split them there again.
+func icalDescription(ev timeline.Event) string {
+	switch {
+	case ev.DetailedDescription == "":
+		return ev.ShortDescription
+	case ev.ShortDescription == "":
+		return "\n\n" + ev.DetailedDescription
+	default:
+		return ev.ShortDescription + "\n\n" + ev.DetailedDescription
+	}
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icalEscape(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+func icalUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// icalSplit splits a list value at the separators that are not escaped.
+func icalSplit(s string, sep byte) []string {
+	var (
+		parts []string
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type icalEvent struct {
+	line  int
+	props map[string][]icalProperty
+}
+
+func (e icalEvent) get(name string) (icalProperty, bool) {
+	props := e.props[name]
+	if len(props) == 0 {
+		return icalProperty{}, false
+	}
+	return props[0], true
+}
+
+// ICalWindow bounds the expansion of recurring events. A zero end ends the window ICalDefaultHorizon from now,
+// a zero start begins it ICalDefaultHorizon before now, or before its end if that is earlier.
+type ICalWindow struct {
+	From  time.Time
+	Until time.Time
+}
+
+// ParseICalImport reads the VEVENTs of an iCalendar file. UIDs become external IDs, recurring events are
+// expanded to one event per occurrence within the window, identified by the UID and the occurrence start.
+// Cancelled events are skipped.
+func ParseICalImport(r io.Reader, window ICalWindow) (timeline.ImportFile, error) {
+	now := time.Now()
+	if window.Until.IsZero() {
+		window.Until = now.Add(ICalDefaultHorizon)
+	}
+	if window.From.IsZero() {
+		// endless events that started long ago would have more than ICalMaxOccurrences occurrences otherwise
+		window.From = now.Add(-ICalDefaultHorizon)
+		if window.Until.Before(now) {
+			window.From = window.Until.Add(-ICalDefaultHorizon)
+		}
+	}
+	events, err := readICalEvents(r)
+	if err != nil {
+		return timeline.ImportFile{}, err
+	}
+
+	// overridden occurrences of recurring events are listed as separate VEVENTs with a RECURRENCE-ID
+	overridden := make(map[string]bool)
+	for _, ev := range events {
+		uid, _ := ev.get("UID")
+		if rid, ok := ev.get("RECURRENCE-ID"); ok {
+			if t, err := parseICalTime(rid); err == nil {
+				overridden[uid.value+"/"+icalOccurrenceKey(t)] = true
+			}
+		}
+	}
+
+	var file timeline.ImportFile
+	for _, ev := range events {
+		if status, ok := ev.get("STATUS"); ok && strings.EqualFold(status.value, "CANCELLED") {
+			continue
+		}
+		rec, err := icalRecord(ev)
+		if err != nil {
+			file.Records = append(file.Records, timeline.ImportRecord{Line: ev.line, Err: err})
+			continue
+		}
+		uid := rec.Event.ExternalID
+
+		if rid, ok := ev.get("RECURRENCE-ID"); ok {
+			t, err := parseICalTime(rid)
+			if err != nil {
+				file.Records = append(file.Records, timeline.ImportRecord{Line: ev.line, Err: err})
+				continue
+			}
+			if t.Before(window.From) || t.After(window.Until) {
+				continue
+			}
+			rec.Event.ExternalID = uid + "/" + icalOccurrenceKey(t)
+			file.Records = append(file.Records, rec)
+			continue
+		}
+		rule, ok := ev.get("RRULE")
+		if !ok {
+			file.Records = append(file.Records, rec)
+			continue
+		}
+
+		occurrences, err := icalOccurrences(ev, rule, window)
+		if err != nil {
+			file.Records = append(file.Records, timeline.ImportRecord{Line: ev.line, Event: timeline.Event{ExternalID: uid}, Err: err})
+			continue
+		}
+		for _, t := range occurrences {
+			key := icalOccurrenceKey(t)
+			if uid != "" && overridden[uid+"/"+key] {
+				continue
+			}
+			occurrence := rec
+			occurrence.Event.EventTime = t
+			occurrence.Event.Tags = append([]string(nil), rec.Event.Tags...)
+			if uid != "" {
+				occurrence.Event.ExternalID = uid + "/" + key
+			}
+			file.Records = append(file.Records, occurrence)
+		}
+	}
+	return file, nil
+}
+
+func icalOccurrenceKey(t time.Time) string {
+	return t.UTC().Format(icalDateTimeLayout) + "Z"
+}
+
+func icalRecord(ev icalEvent) (timeline.ImportRecord, error) {
+	rec := timeline.ImportRecord{Line: ev.line}
+	provide := func(field string) {
+		rec.Provided = append(rec.Provided, field)
+	}
+
+	if uid, ok := ev.get("UID"); ok {
+		rec.Event.ExternalID = strings.TrimSpace(uid.value)
+		provide(timeline.ImportFieldExternalID)
+	}
+	start, ok := ev.get("DTSTART")
+	if !ok {
+		return rec, fmt.Errorf("%w: VEVENT without DTSTART", timeline.ErrInvalid)
+	}
+	t, err := parseICalTime(start)
+	if err != nil {
+		return rec, err
+	}
+	rec.Event.EventTime = t.UTC()
+	provide(timeline.ImportFieldEventTime)
+	if summary, ok := ev.get("SUMMARY"); ok {
+		rec.Event.Name = icalUnescape(summary.value)
+		provide(timeline.ImportFieldName)
+	}
+	if description, ok := ev.get("DESCRIPTION"); ok {
+		short, detailed, _ := strings.Cut(icalUnescape(description.value), "\n\n")
+		rec.Event.ShortDescription, rec.Event.DetailedDescription = short, detailed
+		provide(timeline.ImportFieldShortDescription)
+		provide(timeline.ImportFieldDetailedDescription)
+	}
+	if categories, ok := ev.props["CATEGORIES"]; ok {
+		rec.Event.Tags = []string{}
+		for _, p := range categories {
+			for _, tag := range icalSplit(p.value, ',') {
+				if tag = strings.TrimSpace(icalUnescape(tag)); tag != "" {
+					rec.Event.Tags = append(rec.Event.Tags, tag)
+				}
+			}
+		}
+		provide(timeline.ImportFieldTags)
+	}
+	if typeName, ok := ev.get(icalTypeProperty); ok {
+		rec.TypeName = strings.TrimSpace(icalUnescape(typeName.value))
+		provide(timeline.ImportFieldType)
+	}
+	return rec, nil
+}
+
+// parseICalTime parses DATE and DATE-TIME values in their time zone. Floating times are taken as UTC, as are dates.
+func parseICalTime(p icalProperty) (time.Time, error) {
+	return parseICalTimeValue(p, strings.TrimSpace(p.value))
+}
+
+func parseICalTimeValue(p icalProperty, value string) (time.Time, error) {
+	if p.params["VALUE"] == "DATE" || len(value) == len(icalDateLayout) {
+		t, err := time.Parse(icalDateLayout, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid %s date %q", timeline.ErrInvalid, p.name, value)
+		}
+		return t, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTimeLayout, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid %s time %q", timeline.ErrInvalid, p.name, value)
+		}
+		return t, nil
+	}
+	loc := time.UTC
+	if tzid := p.params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/"))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: unknown time zone %q", timeline.ErrInvalid, tzid)
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation(icalDateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid %s time %q", timeline.ErrInvalid, p.name, value)
+	}
+	return t, nil
+}
+
+// readICalEvents unfolds the content lines and collects the properties of VEVENTs, skipping nested
+// components such as VALARMs.
+func readICalEvents(r io.Reader) ([]icalEvent, error) {
+	type contentLine struct {
+		line int
+		text string
+	}
+	var lines []contentLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	n := 0
+	for scanner.Scan() {
+		n++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if n == 1 {
+			// some calendar exports start with a UTF-8 byte order mark
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, contentLine{line: n, text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: line %d: %v", timeline.ErrInvalid, n+1, err)
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0].text, "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("%w: not an iCalendar file", timeline.ErrInvalid)
+	}
+
+	var (
+		events  []icalEvent
+		current *icalEvent
+		nested  int
+	)
+	for _, l := range lines {
+		p, err := parseICalLine(l.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", timeline.ErrInvalid, l.line, err)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && current == nil:
+			current = &icalEvent{line: l.line, props: make(map[string][]icalProperty)}
+		case current == nil:
+		case p.name == "BEGIN":
+			nested++
+		case p.name == "END" && nested > 0:
+			nested--
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			events = append(events, *current)
+			current = nil
+		case nested == 0:
+			current.props[p.name] = append(current.props[p.name], p)
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("%w: line %d: unterminated VEVENT", timeline.ErrInvalid, current.line)
+	}
+	return events, nil
+}
+
+// parseICalLine parses a content line of the form NAME;PARAM=VALUE;PARAM="VALUE":VALUE.
+func parseICalLine(text string) (icalProperty, error) {
+	p := icalProperty{params: make(map[string]string)}
+	i := strings.IndexAny(text, ";:")
+	if i <= 0 {
+		return p, fmt.Errorf("invalid content line %q", text)
+	}
+	p.name = strings.ToUpper(text[:i])
+	for text[i] == ';' {
+		text = text[i+1:]
+		eq := strings.IndexByte(text, '=')
+		if eq <= 0 {
+			return p, fmt.Errorf("invalid parameter of %s", p.name)
+		}
+		param := strings.ToUpper(text[:eq])
+		text = text[eq+1:]
+		var value string
+		if strings.HasPrefix(text, `"`) {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				return p, fmt.Errorf("unterminated quoted parameter of %s", p.name)
+			}
+			value, text = text[1:end+1], text[end+2:]
+		} else {
+			end := strings.IndexAny(text, ";:")
+			if end < 0 {
+				return p, fmt.Errorf("missing value of %s", p.name)
+			}
+			value, text = text[:end], text[end:]
+		}
+		p.params[param] = value
+		i = 0
+		if len(text) == 0 {
+			return p, fmt.Errorf("missing value of %s", p.name)
+		}
+	}
+	p.value = text[i+1:]
+	return p, nil
+}
+
+type icalRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// parseICalRule supports the common subset of recurrence rules: FREQ, INTERVAL, COUNT, UNTIL and
+// plain weekdays in BYDAY of weekly rules.
+func parseICalRule(p icalProperty) (icalRule, error) {
+	rule := icalRule{interval: 1}
+	for _, part := range strings.Split(p.value, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("%w: invalid RRULE interval %q", timeline.ErrInvalid, value)
+			}
+			rule.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("%w: invalid RRULE count %q", timeline.ErrInvalid, value)
+			}
+			rule.count = n
+		case "UNTIL":
+			t, err := parseICalTimeValue(icalProperty{name: "UNTIL"}, value)
+			if err != nil {
+				return rule, err
+			}
+			rule.until = t
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := icalWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return rule, fmt.Errorf("%w: unsupported RRULE BYDAY %q", timeline.ErrInvalid, day)
+				}
+				rule.byDay = append(rule.byDay, wd)
+			}
+		case "WKST":
+		default:
+			return rule, fmt.Errorf("%w: unsupported RRULE part %q", timeline.ErrInvalid, key)
+		}
+	}
+	switch rule.freq {
+	case "DAILY", "MONTHLY", "YEARLY":
+		if len(rule.byDay) > 0 {
+			return rule, fmt.Errorf("%w: BYDAY is only supported in weekly RRULEs", timeline.ErrInvalid)
+		}
+	case "WEEKLY":
+	default:
+		return rule, fmt.Errorf("%w: unsupported RRULE frequency %q", timeline.ErrInvalid, rule.freq)
+	}
+	return rule, nil
+}
+
+// icalOccurrences expands the recurrence of an event within the window, leaving out EXDATEs.
+func icalOccurrences(ev icalEvent, ruleProp icalProperty, window ICalWindow) ([]time.Time, error) {
+	rule, err := parseICalRule(ruleProp)
+	if err != nil {
+		return nil, err
+	}
+	// the start keeps its time zone, so that occurrences keep their local time across DST changes
+	startProp, _ := ev.get("DTSTART")
+	start, err := parseICalTime(startProp)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[int64]bool)
+	for _, p := range ev.props["EXDATE"] {
+		for _, value := range strings.Split(p.value, ",") {
+			t, err := parseICalTimeValue(p, strings.TrimSpace(value))
+			if err != nil {
+				return nil, err
+			}
+			excluded[t.Unix()] = true
+		}
+	}
+
+	end := window.Until
+	if !rule.until.IsZero() && rule.until.Before(end) {
+		end = rule.until
+	}
+	var occurrences []time.Time
+	generated := 0
+	for period := 0; period < icalMaxIterations; period++ {
+		candidates, ok := rule.period(start, period)
+		if !ok {
+			continue
+		}
+		for _, t := range candidates {
+			if t.After(end) {
+				return occurrences, nil
+			}
+			generated++
+			if rule.count > 0 && generated > rule.count {
+				return occurrences, nil
+			}
+			if t.Before(window.From) || excluded[t.Unix()] {
+				continue
+			}
+			if len(occurrences) == ICalMaxOccurrences {
+				return nil, fmt.Errorf("%w: recurring event has more than %d occurrences, narrow the window", timeline.ErrTooLarge, ICalMaxOccurrences)
+			}
+			occurrences = append(occurrences, t.UTC())
+		}
+	}
+	return nil, fmt.Errorf("%w: recurring event does not end within the window", timeline.ErrTooLarge)
+}
+
+// period returns the occurrences in the nth period of the rule. Monthly and yearly rules skip periods
+// in which the day of the start does not exist, like February 30th.
+func (r icalRule) period(start time.Time, n int) ([]time.Time, bool) {
+	step := n * r.interval
+	switch r.freq {
+	case "DAILY":
+		return []time.Time{start.AddDate(0, 0, step)}, true
+	case "WEEKLY":
+		base := start.AddDate(0, 0, 7*step)
+		if len(r.byDay) == 0 {
+			return []time.Time{base}, true
+		}
+		// weeks start on Monday
+		monday := base.AddDate(0, 0, -((int(base.Weekday()) + 6) % 7))
+		var days []time.Time
+		for _, wd := range r.byDay {
+			t := monday.AddDate(0, 0, (int(wd)+6)%7)
+			if !t.Before(start) {
+				days = append(days, t)
+			}
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+		return days, true
+	default:
+		months := step
+		if r.freq == "YEARLY" {
+			months *= 12
+		}
+		month := int(start.Month()) - 1 + months
+		t := time.Date(start.Year()+month/12, time.Month(month%12+1), start.Day(),
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		return []time.Time{t}, t.Day() == start.Day()
+	}
+}
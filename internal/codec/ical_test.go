@@ -0,0 +1,189 @@
+package codec
+
+import (
+	"bytes"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICalExport(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewExportWriter(FormatICS, &buf, ExportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteTypes([]timeline.Type{{ID: 1, Name: "Mission"}}))
+	require.NoError(t, w.WriteTags(nil))
+	require.NoError(t, w.WriteEvent(timeline.Event{
+		ID:                  4,
+		Name:                "Apollo 11; the landing",
+		EventTime:           time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC),
+		ShortDescription:    "First crewed landing",
+		DetailedDescription: strings.Repeat("Tranquility Base here, the Eagle has landed. ", 3),
+		TypeID:              1,
+		Tags:                []string{"moon", "crewed"},
+		Revision:            3,
+	}))
+	require.NoError(t, w.WriteEvent(timeline.Event{ID: 5, Name: "Apollo 12", EventTime: time.Date(1969, 11, 14, 0, 0, 0, 0, time.UTC), ExternalID: "apollo-12"}))
+	require.NoError(t, w.Close())
+
+	out := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+	require.Contains(t, out, "UID:event-4\r\n")
+	require.Contains(t, out, "UID:apollo-12\r\n")
+	require.Contains(t, out, "DTSTART:19690720T201700Z\r\n")
+	require.Contains(t, out, "SUMMARY:Apollo 11\\; the landing\r\n")
+	require.Contains(t, out, "CATEGORIES:moon,crewed\r\n")
+	require.Contains(t, out, "SEQUENCE:2\r\n")
+	require.Contains(t, out, "DTSTART;VALUE=DATE:19691114\r\nDTEND;VALUE=DATE:19691115\r\n")
+
+	file, err := ParseICalImport(&buf, ICalWindow{})
+	require.NoError(t, err)
+	require.Len(t, file.Records, 2)
+	rec := file.Records[0]
+	require.NoError(t, rec.Err)
+	// importing the export updates the events it was made of
+	require.Equal(t, "event-4", rec.Event.ExternalID)
+	id, ok := timeline.ExportedEventID(rec.Event.ExternalID)
+	require.True(t, ok)
+	require.Equal(t, uint(4), id)
+	require.Equal(t, "apollo-12", file.Records[1].Event.ExternalID)
+	require.Equal(t, "Apollo 11; the landing", rec.Event.Name)
+	require.Equal(t, time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC), rec.Event.EventTime)
+	require.Equal(t, "First crewed landing", rec.Event.ShortDescription)
+	require.Equal(t, strings.Repeat("Tranquility Base here, the Eagle has landed. ", 3), rec.Event.DetailedDescription)
+	require.Equal(t, []string{"moon", "crewed"}, rec.Event.Tags)
+	require.Equal(t, "Mission", rec.TypeName)
+	require.Equal(t, time.Date(1969, 11, 14, 0, 0, 0, 0, time.UTC), file.Records[1].Event.EventTime)
+}
+
+func TestParseICalImportRecurrence(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"SUMMARY:Stand",
+		"  up",
+		"DTSTART;TZID=Europe/Warsaw:20230320T090000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6",
+		"EXDATE;TZID=Europe/Warsaw:20230322T090000",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"RECURRENCE-ID;TZID=Europe/Warsaw:20230327T090000",
+		"SUMMARY:Moved stand up",
+		"DTSTART;TZID=Europe/Warsaw:20230327T110000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:rent",
+		"SUMMARY:Rent",
+		"DTSTART;VALUE=DATE:20230131",
+		"RRULE:FREQ=MONTHLY;UNTIL=20230601",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled",
+		"SUMMARY:Cancelled",
+		"DTSTART:20230101T100000Z",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:forever",
+		"SUMMARY:Forever",
+		"DTSTART:20230101T100000Z",
+		"RRULE:FREQ=DAILY",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	file, err := ParseICalImport(strings.NewReader(input), ICalWindow{
+		From:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	var got []string
+	for _, rec := range file.Records {
+		if rec.Err != nil {
+			got = append(got, rec.Event.ExternalID+" failed")
+			continue
+		}
+		got = append(got, rec.Event.ExternalID+" "+rec.Event.Name+" "+rec.Event.EventTime.Format(time.RFC3339))
+	}
+	require.Equal(t, []string{
+		// the occurrences after the switch to summer time stay at 9 local time
+		"standup/20230320T080000Z Stand up 2023-03-20T08:00:00Z",
+		"standup/20230329T070000Z Stand up 2023-03-29T07:00:00Z",
+		"standup/20230403T070000Z Stand up 2023-04-03T07:00:00Z",
+		"standup/20230405T070000Z Stand up 2023-04-05T07:00:00Z",
+		"standup/20230327T070000Z Moved stand up 2023-03-27T09:00:00Z",
+		// months without a 31st day are skipped
+		"rent/20230131T000000Z Rent 2023-01-31T00:00:00Z",
+		"rent/20230331T000000Z Rent 2023-03-31T00:00:00Z",
+		"rent/20230531T000000Z Rent 2023-05-31T00:00:00Z",
+		"forever failed",
+	}, got)
+	require.ErrorIs(t, file.Records[len(file.Records)-1].Err, timeline.ErrTooLarge)
+
+	t.Run("window", func(t *testing.T) {
+		file, err := ParseICalImport(strings.NewReader(input), ICalWindow{
+			From:  time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		// two stand ups, the moved one, the rent and 30 days of the endless event
+		require.Len(t, file.Records, 34)
+	})
+
+	t.Run("default window", func(t *testing.T) {
+		file, err := ParseICalImport(strings.NewReader(input), ICalWindow{})
+		require.NoError(t, err)
+		// a year before and after now of the endless event, the others ended before
+		require.True(t, len(file.Records) == 730 || len(file.Records) == 731, "%d occurrences", len(file.Records))
+		for _, rec := range file.Records {
+			require.NoError(t, rec.Err)
+			require.Equal(t, "Forever", rec.Event.Name)
+		}
+
+		file, err = ParseICalImport(strings.NewReader(input), ICalWindow{Until: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+		// the window ends before now, so it starts a year before its end: two stand ups, the moved one,
+		// two rents and 90 days of the endless event
+		require.Len(t, file.Records, 95)
+	})
+
+	t.Run("not a calendar", func(t *testing.T) {
+		_, err := ParseICalImport(strings.NewReader("name,event_time\n"), ICalWindow{})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
+
+func TestParseICalTimeZones(t *testing.T) {
+	// zones are resolved by the embedded time zone database, hosts without zoneinfo parse them alike
+	tests := map[string]struct {
+		tzid string
+		want time.Time
+	}{
+		"winter time":           {"Europe/Warsaw", time.Date(2023, 1, 10, 8, 0, 0, 0, time.UTC)},
+		"other hemisphere":      {"Australia/Sydney", time.Date(2023, 1, 9, 22, 0, 0, 0, time.UTC)},
+		"globally unique TZID":  {"/America/New_York", time.Date(2023, 1, 10, 14, 0, 0, 0, time.UTC)},
+		"zone without DST rule": {"Asia/Kolkata", time.Date(2023, 1, 10, 3, 30, 0, 0, time.UTC)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := icalProperty{name: "DTSTART", params: map[string]string{"TZID": tt.tzid}, value: "20230110T090000"}
+			got, err := parseICalTime(p)
+			require.NoError(t, err)
+			require.True(t, tt.want.Equal(got), "got %s", got.UTC())
+		})
+	}
+
+	_, err := parseICalTime(icalProperty{name: "DTSTART", params: map[string]string{"TZID": "Mars/Olympus_Mons"}, value: "20230110T090000"})
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
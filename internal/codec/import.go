@@ -0,0 +1,475 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of imports and exports.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatICS    = "ics"
+)
+
+// ImportTagSeparator separates the tags within a single CSV column.
+const ImportTagSeparator = ";"
+
+// importTimeLayouts are the accepted formats of event times, spreadsheets rarely produce RFC 3339.
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseImportTime parses the event times of imports, either in RFC 3339 or as a plain date and time.
+func ParseImportTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid event time %q", timeline.ErrInvalid, s)
+}
+
+// ImportParseOptions tune reading import files in formats they apply to.
+type ImportParseOptions struct {
+	// Mapping assigns CSV columns to import fields.
+	Mapping map[string]string
+	// Window bounds the expansion of recurring iCalendar events.
+	Window ICalWindow
+}
+
+// ParseImport reads events in the given format.
+func ParseImport(format string, r io.Reader, opts ImportParseOptions) (timeline.ImportFile, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSVImport(r, opts.Mapping)
+	case FormatJSON:
+		return ParseJSONImport(r)
+	case FormatNDJSON:
+		return ParseNDJSONImport(r)
+	case FormatICS:
+		return ParseICalImport(r, opts.Window)
+	case FormatTimelineJS:
+		return ParseTimelineJSImport(r)
+	default:
+		return timeline.ImportFile{}, fmt.Errorf("%w: unknown import format %q", timeline.ErrInvalid, format)
+	}
+}
+
+// ParseCSVImport reads events from CSV with a header row. Columns are named after the import fields
+// unless mapping assigns a field another column name. Columns not referring to a field are ignored.
+func ParseCSVImport(r io.Reader, mapping map[string]string) (timeline.ImportFile, error) {
+	for field := range mapping {
+		if !isImportField(field) {
+			return timeline.ImportFile{}, fmt.Errorf("%w: unknown import field %q", timeline.ErrInvalid, field)
+		}
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return timeline.ImportFile{}, fmt.Errorf("%w: missing CSV header", timeline.ErrInvalid)
+		}
+		return timeline.ImportFile{}, fmt.Errorf("%w: %v", timeline.ErrInvalid, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		// spreadsheets tend to prefix UTF-8 exports with a byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		columns[strings.ToLower(name)] = i
+	}
+	fieldColumns := make(map[string]int)
+	for _, field := range timeline.ImportFields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		i, ok := columns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if _, mapped := mapping[field]; mapped {
+				return timeline.ImportFile{}, fmt.Errorf("%w: column %q mapped to %s not found", timeline.ErrInvalid, name, field)
+			}
+			continue
+		}
+		fieldColumns[field] = i
+	}
+
+	var records []timeline.ImportRecord
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				// the reader cannot recover from malformed quoting, so the rest of the file is lost
+				return timeline.ImportFile{}, fmt.Errorf("%w: line %d: %v", timeline.ErrInvalid, parseErr.StartLine, parseErr.Err)
+			}
+			return timeline.ImportFile{}, err
+		}
+		line, _ := cr.FieldPos(0)
+		if isBlankRow(row) {
+			continue
+		}
+		values := make(map[string]string)
+		for field, i := range fieldColumns {
+			if i < len(row) {
+				values[field] = row[i]
+			}
+		}
+		records = append(records, importRecord(line, values))
+	}
+	return timeline.ImportFile{Records: records}, nil
+}
+
+// ParseNDJSONImport reads newline delimited JSON objects keyed by the import fields. Objects of the
+// "type" kind declare types like exports do, those of the "tag" kind are skipped.
+func ParseNDJSONImport(r io.Reader) (timeline.ImportFile, error) {
+	var file timeline.ImportFile
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var kind struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(data, &kind); err != nil {
+			file.Records = append(file.Records, timeline.ImportRecord{
+				Line: line,
+				Err:  fmt.Errorf("%w: invalid JSON: %v", timeline.ErrInvalid, err),
+			})
+			continue
+		}
+		switch kind.Kind {
+		case exportKindType:
+			it, err := jsonImportType(line, data)
+			if err != nil {
+				return timeline.ImportFile{}, err
+			}
+			file.Types = append(file.Types, it)
+		case exportKindTag:
+		case "", exportKindEvent:
+			file.Records = append(file.Records, jsonImportRecord(line, data))
+		default:
+			return timeline.ImportFile{}, fmt.Errorf("%w: line %d: unknown kind %q", timeline.ErrInvalid, line, kind.Kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return timeline.ImportFile{}, fmt.Errorf("%w: line %d: %v", timeline.ErrInvalid, line+1, err)
+	}
+	return file, nil
+}
+
+// ParseJSONImport reads a JSON document as written by exports, with "types", "tags" and "events" arrays.
+// Tags are skipped, they are created along with the events.
+func ParseJSONImport(r io.Reader) (timeline.ImportFile, error) {
+	var file timeline.ImportFile
+	err := decodeJSONArrays(r, func(key string, line int, raw json.RawMessage) error {
+		switch key {
+		case "events":
+			file.Records = append(file.Records, jsonImportRecord(line, raw))
+		case "types":
+			it, err := jsonImportType(line, raw)
+			if err != nil {
+				return err
+			}
+			file.Types = append(file.Types, it)
+		}
+		return nil
+	})
+	if err != nil {
+		return timeline.ImportFile{}, err
+	}
+	return file, nil
+}
+
+// decodeJSONArrays calls fn for the items of the arrays in a JSON object, along with the key of the array
+// and the line the item starts on. Members that are not arrays are skipped.
+func decodeJSONArrays(r io.Reader, fn func(key string, line int, raw json.RawMessage) error) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	invalid := func(err error) error {
+		return fmt.Errorf("%w: invalid JSON: %v", timeline.ErrInvalid, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return invalid(err)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return invalid(err)
+		}
+		next := lineOffset(data, dec.InputOffset())
+		if next >= int64(len(data)) {
+			return invalid(io.ErrUnexpectedEOF)
+		}
+		if data[next] != '[' {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return invalid(err)
+			}
+			continue
+		}
+		if err := expectDelim(dec, '['); err != nil {
+			return invalid(err)
+		}
+		for dec.More() {
+			line := lineAt(data, dec.InputOffset())
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return invalid(err)
+			}
+			if err := fn(key.(string), line, raw); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return invalid(err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return invalid(err)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, found %v", delim, tok)
+	}
+	return nil
+}
+
+// lineAt returns the line of the first value at or after offset.
+func lineAt(data []byte, offset int64) int {
+	return bytes.Count(data[:lineOffset(data, offset)], []byte("\n")) + 1
+}
+
+// lineOffset skips the whitespace and separators at offset, up to the next value.
+func lineOffset(data []byte, offset int64) int64 {
+	for int(offset) < len(data)-1 && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func jsonImportType(line int, data []byte) (timeline.ImportType, error) {
+	var t schema.ExportType
+	if err := json.Unmarshal(data, &t); err != nil {
+		return timeline.ImportType{}, fmt.Errorf("%w: line %d: invalid type: %v", timeline.ErrInvalid, line, err)
+	}
+	return timeline.ImportType{
+		Line: line,
+		Type: timeline.Type{
+			Name:         t.Name,
+			Color:        t.Color,
+			Icon:         t.Icon,
+			Theme:        t.Theme,
+			DisplayOrder: t.DisplayOrder,
+		},
+		ParentName: t.Parent,
+	}, nil
+}
+
+func jsonImportRecord(line int, data []byte) timeline.ImportRecord {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return timeline.ImportRecord{Line: line, Err: fmt.Errorf("%w: invalid JSON: %v", timeline.ErrInvalid, err)}
+	}
+	values := make(map[string]string)
+	var tags []string
+	for _, field := range timeline.ImportFields {
+		v, ok := raw[field]
+		if !ok {
+			continue
+		}
+		if field == timeline.ImportFieldTags {
+			if string(v) != "null" {
+				if err := json.Unmarshal(v, &tags); err != nil {
+					return timeline.ImportRecord{Line: line, Err: fmt.Errorf("%w: %s must be an array of strings", timeline.ErrInvalid, field)}
+				}
+			}
+			values[field] = ""
+			continue
+		}
+		s, err := jsonImportValue(field, v)
+		if err != nil {
+			return timeline.ImportRecord{Line: line, Err: err}
+		}
+		values[field] = s
+	}
+	rec := importRecord(line, values)
+	if _, ok := values[timeline.ImportFieldTags]; ok {
+		rec.Event.Tags = append([]string{}, tags...)
+	}
+	return rec
+}
+
+// jsonImportValue flattens a JSON value to the textual form used in CSV columns.
+func jsonImportValue(field string, v json.RawMessage) (string, error) {
+	if string(v) == "null" {
+		return "", nil
+	}
+	if field == timeline.ImportFieldTypeID {
+		var id uint
+		if err := json.Unmarshal(v, &id); err != nil {
+			return "", fmt.Errorf("%w: %s must be a positive number", timeline.ErrInvalid, field)
+		}
+		return strconv.FormatUint(uint64(id), 10), nil
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return "", fmt.Errorf("%w: %s must be a string", timeline.ErrInvalid, field)
+	}
+	return s, nil
+}
+
+func importRecord(line int, values map[string]string) timeline.ImportRecord {
+	rec := timeline.ImportRecord{Line: line}
+	for _, field := range timeline.ImportFields {
+		v, ok := values[field]
+		if !ok {
+			continue
+		}
+		rec.Provided = append(rec.Provided, field)
+		switch field {
+		case timeline.ImportFieldExternalID:
+			rec.Event.ExternalID = strings.TrimSpace(v)
+		case timeline.ImportFieldName:
+			rec.Event.Name = v
+		case timeline.ImportFieldEventTime:
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			t, err := ParseImportTime(v)
+			if err != nil && rec.Err == nil {
+				rec.Err = err
+			}
+			rec.Event.EventTime = t
+		case timeline.ImportFieldShortDescription:
+			rec.Event.ShortDescription = v
+		case timeline.ImportFieldDetailedDescription:
+			rec.Event.DetailedDescription = v
+		case timeline.ImportFieldDescriptionFormat:
+			rec.Event.DescriptionFormat = timeline.DescriptionFormat(strings.TrimSpace(v))
+		case timeline.ImportFieldGraphic:
+			rec.Event.Graphic = strings.TrimSpace(v)
+		case timeline.ImportFieldType:
+			rec.TypeName = strings.TrimSpace(v)
+		case timeline.ImportFieldTypeID:
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+			if err != nil && rec.Err == nil {
+				rec.Err = fmt.Errorf("%w: invalid type id %q", timeline.ErrInvalid, v)
+			}
+			rec.Event.TypeID = uint(id)
+		case timeline.ImportFieldTags:
+			rec.Event.Tags = []string{}
+			for _, tag := range strings.Split(v, ImportTagSeparator) {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					rec.Event.Tags = append(rec.Event.Tags, tag)
+				}
+			}
+		}
+	}
+	return rec
+}
+
+func isImportField(field string) bool {
+	for _, f := range timeline.ImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseImportMapping parses "field:Column" pairs assigning CSV columns to import fields.
+func ParseImportMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range pairs {
+		field, column, ok := strings.Cut(pair, ":")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("%w: invalid column mapping %q, expected field:Column", timeline.ErrInvalid, pair)
+		}
+		if !isImportField(field) {
+			return nil, fmt.Errorf("%w: unknown import field %q", timeline.ErrInvalid, field)
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+func HTTPFromImportReport(report timeline.ImportReport) *schema.ImportReport {
+	httpReport := &schema.ImportReport{
+		DryRun:       report.DryRun,
+		Committed:    report.Committed,
+		Created:      report.Created,
+		Updated:      report.Updated,
+		Unchanged:    report.Unchanged,
+		Failed:       report.Failed,
+		CreatedTypes: report.CreatedTypes,
+		Rows:         make([]*schema.ImportRow, 0, len(report.Rows)),
+	}
+	if httpReport.CreatedTypes == nil {
+		httpReport.CreatedTypes = []string{}
+	}
+	for _, row := range report.Rows {
+		httpRow := &schema.ImportRow{
+			Line:       row.Line,
+			ExternalID: row.ExternalID,
+			Action:     string(row.Action),
+			EventID:    row.EventID,
+		}
+		if row.Err != nil {
+			httpRow.Action = ""
+			httpRow.Error = row.Err.Error()
+		}
+		httpReport.Rows = append(httpReport.Rows, httpRow)
+	}
+	return httpReport
+}
@@ -0,0 +1,79 @@
+package codec
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSVImport(t *testing.T) {
+	input := "\ufeffID,Title,Date,Category,tags\n" +
+		"apollo-11,Apollo 11,1969-07-16,Mission,moon; crewed\n" +
+		",,,,\n" +
+		"\"apollo-13\",\"Apollo 13,\nthe failed one\",1970-04-11 19:13,Mission,\n" +
+		"apollo-x,Broken,someday,Mission,\n"
+
+	file, err := ParseCSVImport(strings.NewReader(input), map[string]string{
+		timeline.ImportFieldExternalID: "id",
+		timeline.ImportFieldName:       "Title",
+		timeline.ImportFieldEventTime:  "Date",
+		timeline.ImportFieldType:       "Category",
+	})
+	require.NoError(t, err)
+	records := file.Records
+	require.Len(t, records, 3)
+
+	require.Equal(t, 2, records[0].Line)
+	require.NoError(t, records[0].Err)
+	require.Equal(t, "apollo-11", records[0].Event.ExternalID)
+	require.Equal(t, "Apollo 11", records[0].Event.Name)
+	require.Equal(t, time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC), records[0].Event.EventTime)
+	require.Equal(t, "Mission", records[0].TypeName)
+	require.Equal(t, []string{"moon", "crewed"}, records[0].Event.Tags)
+	require.ElementsMatch(t, []string{"external_id", "name", "event_time", "type", "tags"}, records[0].Provided)
+
+	require.Equal(t, 4, records[1].Line)
+	require.Equal(t, "Apollo 13,\nthe failed one", records[1].Event.Name)
+	require.Equal(t, time.Date(1970, 4, 11, 19, 13, 0, 0, time.UTC), records[1].Event.EventTime)
+	require.Equal(t, []string{}, records[1].Event.Tags)
+
+	require.Equal(t, 6, records[2].Line)
+	require.ErrorIs(t, records[2].Err, timeline.ErrInvalid)
+
+	t.Run("mapped column must exist", func(t *testing.T) {
+		_, err := ParseCSVImport(strings.NewReader("name\nApollo 11\n"), map[string]string{timeline.ImportFieldEventTime: "Date"})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := ParseImportMapping([]string{"title:Name"})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
+
+func TestParseNDJSONImport(t *testing.T) {
+	input := `{"external_id":"apollo-11","name":"Apollo 11","event_time":"1969-07-16T13:32:00Z","type_id":2,"tags":["moon"]}
+
+{"external_id":"apollo-12","short_description":"Lightning strike"}
+{"name":"Apollo 13","tags":"moon"}
+not json
+`
+	file, err := ParseNDJSONImport(strings.NewReader(input))
+	require.NoError(t, err)
+	records := file.Records
+	require.Len(t, records, 4)
+
+	require.NoError(t, records[0].Err)
+	require.Equal(t, uint(2), records[0].Event.TypeID)
+	require.Equal(t, time.Date(1969, 7, 16, 13, 32, 0, 0, time.UTC), records[0].Event.EventTime)
+	require.Equal(t, []string{"moon"}, records[0].Event.Tags)
+
+	require.Equal(t, 3, records[1].Line)
+	require.Equal(t, []string{"external_id", "short_description"}, records[1].Provided)
+
+	require.Equal(t, 4, records[2].Line)
+	require.ErrorIs(t, records[2].Err, timeline.ErrInvalid)
+	require.Equal(t, 5, records[3].Line)
+	require.ErrorIs(t, records[3].Err, timeline.ErrInvalid)
+}
@@ -0,0 +1,31 @@
+package codec
+
+import (
+	"github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"time"
+)
+
+// HTTPFromDomainRelatedEvent describes the related event from the point of view of the event with eventID.
+func HTTPFromDomainRelatedEvent(eventID uint, r *timeline.RelatedEvent) (*schema.RelatedEvent, error) {
+	direction := schema.RelationOutgoing
+	if r.Relation.ToID == eventID {
+		direction = schema.RelationIncoming
+	}
+	return &schema.RelatedEvent{
+		RelationID: r.Relation.ID,
+		Kind:       string(r.Relation.Kind),
+		Direction:  direction,
+		EventID:    r.Event.ID,
+		Name:       r.Event.Name,
+		EventTime:  r.Event.EventTime.Format(time.RFC3339),
+	}, nil
+}
+
+func HTTPToDomainRelation(eventID uint, r *schema.Relation) (*timeline.Relation, error) {
+	return &timeline.Relation{
+		FromID: eventID,
+		ToID:   r.EventID,
+		Kind:   timeline.RelationKind(r.Kind),
+	}, nil
+}
@@ -0,0 +1,25 @@
+package codec
+
+import (
+	"github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"html"
+	"strings"
+)
+
+var highlightReplacer = strings.NewReplacer(
+	timeline.HighlightStart, "<mark>",
+	timeline.HighlightStop, "</mark>",
+)
+
+func HTTPFromDomainSearchResult(r *timeline.SearchResult) (*schema.SearchResult, error) {
+	httpEvent, err := HTTPFromDomainEvent(&r.Event)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.SearchResult{
+		Event:   httpEvent,
+		Rank:    r.Rank,
+		Snippet: highlightReplacer.Replace(html.EscapeString(r.Snippet)),
+	}, nil
+}
@@ -0,0 +1,26 @@
+package codec
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHTTPFromDomainSearchResult(t *testing.T) {
+	r := &timeline.SearchResult{
+		Event: timeline.Event{
+			ID:        1,
+			Name:      "Apollo 11",
+			EventTime: time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC),
+		},
+		Rank:    0.5,
+		Snippet: "<b>first</b> " + timeline.HighlightStart + "Moon" + timeline.HighlightStop + " landing",
+	}
+
+	got, err := HTTPFromDomainSearchResult(r)
+	require.NoError(t, err)
+	require.Equal(t, uint(1), got.Event.ID)
+	require.Equal(t, 0.5, got.Rank)
+	require.Equal(t, "&lt;b&gt;first&lt;/b&gt; <mark>Moon</mark> landing", got.Snippet)
+}
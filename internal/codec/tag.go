@@ -0,0 +1,14 @@
+package codec
+
+import (
+	"github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+)
+
+func HTTPFromDomainTag(t *timeline.Tag) (*schema.Tag, error) {
+	return &schema.Tag{
+		ID:         t.ID,
+		Name:       t.Name,
+		EventCount: t.EventCount,
+	}, nil
+}
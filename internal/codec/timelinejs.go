@@ -0,0 +1,312 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/generator"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"golang.org/x/net/html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatTimelineJS is the JSON format of Knight Lab's TimelineJS, see https://timeline.knightlab.com/docs/json-format.html.
+const FormatTimelineJS = "timelinejs"
+
+// timelineJSTitle is the headline of the title slide of exports.
+const timelineJSTitle = "Timeline"
+
+type timelineJSSlide struct {
+	StartDate *timelineJSDate  `json:"start_date,omitempty"`
+	Text      *timelineJSText  `json:"text,omitempty"`
+	Media     *timelineJSMedia `json:"media,omitempty"`
+	Group     string           `json:"group,omitempty"`
+	UniqueID  string           `json:"unique_id,omitempty"`
+}
+
+// timelineJSDate is written with the precision of the event time. Imports read dates through
+// parseTimelineJSDate instead, as TimelineJS accepts both numbers and strings.
+type timelineJSDate struct {
+	Year   int  `json:"year"`
+	Month  int  `json:"month"`
+	Day    int  `json:"day"`
+	Hour   *int `json:"hour,omitempty"`
+	Minute *int `json:"minute,omitempty"`
+	Second *int `json:"second,omitempty"`
+}
+
+type timelineJSText struct {
+	Headline string `json:"headline,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+type timelineJSMedia struct {
+	URL       string `json:"url"`
+	Caption   string `json:"caption,omitempty"`
+	Credit    string `json:"credit,omitempty"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+}
+
+// timelineJSDateParts are the components of TimelineJS dates, with their default and range.
+var timelineJSDateParts = []struct {
+	name     string
+	def      int
+	min, max int
+}{
+	{"month", 1, 1, 12},
+	{"day", 1, 1, 31},
+	{"hour", 0, 0, 23},
+	{"minute", 0, 0, 59},
+	{"second", 0, 0, 59},
+	{"millisecond", 0, 0, 999},
+}
+
+// ParseTimelineJSImport reads the events of a TimelineJS document. Groups refer to types by name and unique IDs
+// become external IDs. Dates may be as coarse as a year, missing parts default to the start of the period.
+// End dates are checked but dropped, as events have a single time. The title slide is not an event and is skipped.
+func ParseTimelineJSImport(r io.Reader) (timeline.ImportFile, error) {
+	var file timeline.ImportFile
+	err := decodeJSONArrays(r, func(key string, line int, raw json.RawMessage) error {
+		if key == "events" {
+			file.Records = append(file.Records, timelineJSRecord(line, raw))
+		}
+		return nil
+	})
+	if err != nil {
+		return timeline.ImportFile{}, err
+	}
+	return file, nil
+}
+
+func timelineJSRecord(line int, data []byte) timeline.ImportRecord {
+	rec := timeline.ImportRecord{Line: line}
+	provide := func(field string) {
+		rec.Provided = append(rec.Provided, field)
+	}
+
+	var slide struct {
+		StartDate map[string]json.RawMessage `json:"start_date"`
+		EndDate   map[string]json.RawMessage `json:"end_date"`
+		Text      timelineJSText             `json:"text"`
+		Media     *timelineJSMedia           `json:"media"`
+		Group     string                     `json:"group"`
+		UniqueID  string                     `json:"unique_id"`
+	}
+	if err := json.Unmarshal(data, &slide); err != nil {
+		rec.Err = fmt.Errorf("%w: invalid slide: %v", timeline.ErrInvalid, err)
+		return rec
+	}
+
+	if id := strings.TrimSpace(slide.UniqueID); id != "" {
+		rec.Event.ExternalID = id
+		provide(timeline.ImportFieldExternalID)
+	}
+	if slide.StartDate == nil {
+		rec.Err = fmt.Errorf("%w: slide without start_date", timeline.ErrInvalid)
+		return rec
+	}
+	start, err := parseTimelineJSDate(slide.StartDate)
+	if err != nil {
+		rec.Err = fmt.Errorf("%w: start_date: %v", timeline.ErrInvalid, err)
+		return rec
+	}
+	if slide.EndDate != nil {
+		end, err := parseTimelineJSDate(slide.EndDate)
+		if err != nil {
+			rec.Err = fmt.Errorf("%w: end_date: %v", timeline.ErrInvalid, err)
+			return rec
+		}
+		if end.Before(start) {
+			rec.Err = fmt.Errorf("%w: end_date is before start_date", timeline.ErrInvalid)
+			return rec
+		}
+	}
+	rec.Event.EventTime = start
+	provide(timeline.ImportFieldEventTime)
+
+	rec.Event.Name = htmlToText(slide.Text.Headline)
+	provide(timeline.ImportFieldName)
+	paragraphs := strings.Split(htmlToText(slide.Text.Text), "\n\n")
+	rec.Event.ShortDescription = paragraphs[0]
+	rec.Event.DetailedDescription = strings.Join(paragraphs[1:], "\n\n")
+	provide(timeline.ImportFieldShortDescription)
+	provide(timeline.ImportFieldDetailedDescription)
+
+	if slide.Media != nil {
+		rec.Event.Graphic = strings.TrimSpace(slide.Media.URL)
+		provide(timeline.ImportFieldGraphic)
+	}
+	if group := strings.TrimSpace(slide.Group); group != "" {
+		rec.TypeName = group
+		provide(timeline.ImportFieldType)
+	}
+	return rec
+}
+
+// parseTimelineJSDate reads a TimelineJS date in UTC. Its parts are numbers or numeric strings, only the year is required.
+func parseTimelineJSDate(parts map[string]json.RawMessage) (time.Time, error) {
+	year, ok, err := timelineJSDatePart(parts, "year")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("missing year")
+	}
+	values := make([]int, len(timelineJSDateParts))
+	for i, part := range timelineJSDateParts {
+		v, ok, err := timelineJSDatePart(parts, part.name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !ok {
+			v = part.def
+		}
+		if v < part.min || v > part.max {
+			return time.Time{}, fmt.Errorf("%s %d out of range", part.name, v)
+		}
+		values[i] = v
+	}
+	t := time.Date(year, time.Month(values[0]), values[1], values[2], values[3], values[4], values[5]*int(time.Millisecond), time.UTC)
+	if t.Day() != values[1] {
+		return time.Time{}, fmt.Errorf("day %d out of range", values[1])
+	}
+	return t, nil
+}
+
+func timelineJSDatePart(parts map[string]json.RawMessage, name string) (int, bool, error) {
+	raw, ok := parts[name]
+	if !ok || string(raw) == "null" {
+		return 0, false, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, false, fmt.Errorf("%s must be a number", name)
+		}
+		n = json.Number(strings.TrimSpace(s))
+	}
+	if n == "" {
+		return 0, false, nil
+	}
+	v, err := strconv.Atoi(string(n))
+	if err != nil {
+		return 0, false, fmt.Errorf("%s must be a whole number", name)
+	}
+	return v, true, nil
+}
+
+// htmlToText flattens the HTML of TimelineJS texts. Block elements separate paragraphs with a blank line,
+// line breaks are kept and other whitespace is collapsed.
+func htmlToText(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			var paragraphs []string
+			for _, p := range strings.Split(b.String(), "\n\n") {
+				lines := strings.Split(p, "\n")
+				for i := range lines {
+					lines[i] = strings.Join(strings.Fields(lines[i]), " ")
+				}
+				if p = strings.Trim(strings.Join(lines, "\n"), "\n"); p != "" {
+					paragraphs = append(paragraphs, p)
+				}
+			}
+			return strings.Join(paragraphs, "\n\n")
+		case html.TextToken:
+			b.WriteString(strings.NewReplacer("\r", " ", "\n", " ").Replace(string(z.Text())))
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "br":
+				b.WriteString("\n")
+			case "p", "div", "li", "ul", "ol", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6":
+				b.WriteString("\n\n")
+			}
+		}
+	}
+}
+
+type timelineJSExportWriter struct {
+	exportWriter
+	baseURL string
+	events  int
+}
+
+func (t *timelineJSExportWriter) WriteTypes(types []timeline.Type) error {
+	t.exportTypes(types)
+	title, err := json.Marshal(timelineJSSlide{Text: &timelineJSText{Headline: timelineJSTitle}})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(t.w, "{\"title\":%s,\"events\":[", title)
+	return err
+}
+
+func (t *timelineJSExportWriter) WriteTags([]timeline.Tag) error {
+	return nil
+}
+
+func (t *timelineJSExportWriter) WriteEvent(ev timeline.Event) error {
+	description, err := generator.EventDescriptionHTML(ev)
+	if err != nil {
+		return err
+	}
+	slide := timelineJSSlide{
+		StartDate: timelineJSDateOf(ev.EventTime),
+		Text: &timelineJSText{
+			Headline: html.EscapeString(ev.Name),
+			Text:     string(description),
+		},
+		Group:    t.typeNames[ev.TypeID],
+		UniqueID: timeline.ExportedExternalID(ev),
+	}
+	if ev.Graphic != "" {
+		slide.Media = &timelineJSMedia{URL: t.absoluteURL(ev.Graphic)}
+		if thumbnail := timeline.MediaVariantPath(ev.Graphic, timeline.MediaVariantThumbnail); thumbnail != "" {
+			slide.Media.Thumbnail = t.absoluteURL(thumbnail)
+		}
+	}
+	data, err := json.Marshal(slide)
+	if err != nil {
+		return err
+	}
+	if t.events > 0 {
+		if err := t.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	t.events++
+	_, err = t.w.Write(data)
+	return err
+}
+
+func (t *timelineJSExportWriter) Close() error {
+	if _, err := t.w.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return t.w.Flush()
+}
+
+// absoluteURL resolves paths of stored media against the base URL, as TimelineJS loads media from other origins.
+func (t *timelineJSExportWriter) absoluteURL(u string) string {
+	if t.baseURL == "" || !strings.HasPrefix(u, "/") || strings.HasPrefix(u, "//") {
+		return u
+	}
+	return strings.TrimSuffix(t.baseURL, "/") + u
+}
+
+// timelineJSDateOf writes the time of the day only for events not at midnight UTC, which are whole-day events.
+func timelineJSDateOf(t time.Time) *timelineJSDate {
+	t = t.UTC()
+	d := &timelineJSDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+	if !t.Equal(t.Truncate(24 * time.Hour)) {
+		hour, minute, second := t.Hour(), t.Minute(), t.Second()
+		d.Hour, d.Minute, d.Second = &hour, &minute, &second
+	}
+	return d
+}
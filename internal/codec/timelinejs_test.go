@@ -0,0 +1,100 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimelineJSImport(t *testing.T) {
+	input := `{
+  "title": {"text": {"headline": "Space race"}},
+  "events": [
+    {
+      "start_date": {"year": "1969", "month": "7", "day": "20", "hour": 20, "minute": 17},
+      "end_date": {"year": 1969, "month": 7, "day": 21},
+      "text": {"headline": "Apollo 11 &amp; the landing", "text": "<p>First crewed landing</p><p>Tranquility Base here,<br>the Eagle   has landed.</p>"},
+      "media": {"url": "https://example.com/eagle.jpg", "caption": "The Eagle"},
+      "group": "Mission",
+      "unique_id": "apollo-11"
+    },
+    {"start_date": {"year": 1957}, "text": {"headline": "Sputnik"}},
+    {"start_date": {"year": 1961, "month": 2, "day": 30}},
+    {"start_date": {"year": 1962}, "end_date": {"year": 1961}},
+    {"text": {"headline": "No date"}}
+  ]
+}`
+	file, err := ParseTimelineJSImport(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, file.Records, 5)
+
+	apollo := file.Records[0]
+	require.NoError(t, apollo.Err)
+	require.Equal(t, 4, apollo.Line)
+	require.Equal(t, "apollo-11", apollo.Event.ExternalID)
+	require.Equal(t, "Apollo 11 & the landing", apollo.Event.Name)
+	require.Equal(t, time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC), apollo.Event.EventTime)
+	require.Equal(t, "First crewed landing", apollo.Event.ShortDescription)
+	require.Equal(t, "Tranquility Base here,\nthe Eagle has landed.", apollo.Event.DetailedDescription)
+	require.Equal(t, "https://example.com/eagle.jpg", apollo.Event.Graphic)
+	require.Equal(t, "Mission", apollo.TypeName)
+
+	sputnik := file.Records[1]
+	require.NoError(t, sputnik.Err)
+	require.Equal(t, 12, sputnik.Line)
+	require.Equal(t, time.Date(1957, 1, 1, 0, 0, 0, 0, time.UTC), sputnik.Event.EventTime)
+	require.NotContains(t, sputnik.Provided, timeline.ImportFieldExternalID)
+	require.NotContains(t, sputnik.Provided, timeline.ImportFieldGraphic)
+
+	for _, rec := range file.Records[2:] {
+		require.ErrorIs(t, rec.Err, timeline.ErrInvalid)
+	}
+}
+
+func TestParseTimelineJSImportInvalidJSON(t *testing.T) {
+	_, err := ParseTimelineJSImport(strings.NewReader(`{"events": [`))
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
+
+func TestTimelineJSRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewExportWriter(FormatTimelineJS, &buf, ExportOptions{BaseURL: "https://timeline.example.com/"})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteTypes([]timeline.Type{{ID: 1, Name: "Mission"}}))
+	require.NoError(t, w.WriteTags(nil))
+	require.NoError(t, w.WriteEvent(timeline.Event{
+		ID:                  4,
+		Name:                "Apollo <11>",
+		EventTime:           time.Date(1969, 7, 20, 20, 17, 40, 0, time.UTC),
+		ShortDescription:    "First crewed landing",
+		DetailedDescription: "Tranquility Base here.\n\nThe Eagle has landed.",
+		Graphic:             "/media/abcd.webp",
+		TypeID:              1,
+	}))
+	require.NoError(t, w.WriteEvent(timeline.Event{ID: 5, ExternalID: "apollo-12", Name: "Apollo 12", EventTime: time.Date(1969, 11, 14, 0, 0, 0, 0, time.UTC)}))
+	require.NoError(t, w.Close())
+
+	var doc map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Contains(t, doc, "title")
+	out := buf.String()
+	require.Contains(t, out, `"media":{"url":"https://timeline.example.com/media/abcd.webp","thumbnail":"https://timeline.example.com/media/abcd-thumbnail.png"}`)
+	require.Contains(t, out, `"start_date":{"year":1969,"month":11,"day":14}`)
+
+	file, err := ParseTimelineJSImport(&buf)
+	require.NoError(t, err)
+	require.Len(t, file.Records, 2)
+	first := file.Records[0]
+	require.NoError(t, first.Err)
+	require.Equal(t, "event-4", first.Event.ExternalID)
+	require.Equal(t, "Apollo <11>", first.Event.Name)
+	require.Equal(t, time.Date(1969, 7, 20, 20, 17, 40, 0, time.UTC), first.Event.EventTime)
+	require.Equal(t, "First crewed landing", first.Event.ShortDescription)
+	require.Equal(t, "Tranquility Base here.\n\nThe Eagle has landed.", first.Event.DetailedDescription)
+	require.Equal(t, "Mission", first.TypeName)
+	require.Equal(t, "apollo-12", file.Records[1].Event.ExternalID)
+}
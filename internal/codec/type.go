@@ -3,21 +3,53 @@ package codec
 import (
 	"github.com/kamkali/go-timeline/internal/server/schema"
 	"github.com/kamkali/go-timeline/internal/timeline"
+	"time"
 )
 
 func HTTPToDomainType(e *schema.Type) (*timeline.Type, error) {
 	domainType := &timeline.Type{
-		Name:  e.Name,
-		Color: e.Color,
+		Name:         e.Name,
+		Color:        e.Color,
+		Icon:         e.Icon,
+		Theme:        e.Theme,
+		DisplayOrder: e.DisplayOrder,
+		ParentID:     e.ParentID,
 	}
 
 	return domainType, nil
 }
 
 func HTTPFromDomainType(t *timeline.Type) (*schema.Type, error) {
-	return &schema.Type{
-		ID:    t.ID,
-		Name:  t.Name,
-		Color: t.Color,
-	}, nil
+	httpType := &schema.Type{
+		ID:             t.ID,
+		Name:           t.Name,
+		Color:          t.Color,
+		Icon:           t.Icon,
+		Theme:          t.Theme,
+		DisplayOrder:   t.DisplayOrder,
+		ParentID:       t.ParentID,
+		EffectiveColor: t.EffectiveColor,
+		EventCount:     t.EventCount,
+	}
+	if t.FirstEventTime != nil {
+		httpType.FirstEventTime = t.FirstEventTime.Format(time.RFC3339)
+	}
+	if t.LastEventTime != nil {
+		httpType.LastEventTime = t.LastEventTime.Format(time.RFC3339)
+	}
+	for i := range t.Events {
+		httpEvent, err := HTTPFromDomainEvent(&t.Events[i])
+		if err != nil {
+			return nil, err
+		}
+		httpType.Events = append(httpType.Events, httpEvent)
+	}
+	for i := range t.Children {
+		child, err := HTTPFromDomainType(&t.Children[i])
+		if err != nil {
+			return nil, err
+		}
+		httpType.Children = append(httpType.Children, child)
+	}
+	return httpType, nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestHTTPToDomainType(t *testing.T) {
@@ -49,3 +50,32 @@ func TestHTTPFromDomainType(t *testing.T) {
 		t.Errorf("Expected %+v, got %+v", expected, httpType)
 	}
 }
+
+func TestHTTPFromDomainTypeWithEvents(t *testing.T) {
+	first := time.Date(1968, 12, 21, 0, 0, 0, 0, time.UTC)
+	last := time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC)
+	dt := &timeline.Type{
+		ID:             1,
+		Name:           "lunar",
+		EventCount:     2,
+		FirstEventTime: &first,
+		LastEventTime:  &last,
+		Events: []timeline.Event{
+			{ID: 3, Name: "Apollo 8", EventTime: first, TypeID: 1},
+			{ID: 5, Name: "Apollo 11", EventTime: last, TypeID: 1},
+		},
+	}
+
+	got, err := HTTPFromDomainType(dt)
+	require.NoError(t, err)
+	require.Equal(t, 2, got.EventCount)
+	require.Equal(t, "1968-12-21T00:00:00Z", got.FirstEventTime)
+	require.Equal(t, "1969-07-16T00:00:00Z", got.LastEventTime)
+	require.Len(t, got.Events, 2)
+	require.Equal(t, "Apollo 11", got.Events[1].Name)
+
+	empty, err := HTTPFromDomainType(&timeline.Type{ID: 2, Name: "unused"})
+	require.NoError(t, err)
+	require.Empty(t, empty.FirstEventTime)
+	require.Nil(t, empty.Events)
+}
@@ -14,6 +14,17 @@ const (
 	StageProduction  AppStage = "PROD"
 )
 
+const (
+	MediaBackendFilesystem = "filesystem"
+	MediaBackendS3         = "s3"
+)
+
+const (
+	CacheBackendNone     = "none"
+	CacheBackendMemory   = "memory"
+	CacheBackendPostgres = "postgres"
+)
+
 type Config struct {
 	Stage  AppStage `envconfig:"STAGE" default:"DEV"`
 	SeedDB bool     `envconfig:"SEED_DB" default:"false"`
@@ -30,12 +41,57 @@ type Config struct {
 		Password string `envconfig:"DB_PASSWORD" default:"postgres"`
 		Name     string `envconfig:"DB_NAME" default:"timeline"`
 		URI      string `envconfig:"DATABASE_URL"`
+
+		SearchLanguage string `envconfig:"DB_SEARCH_LANGUAGE" default:"english"`
 	}
 
 	Server struct {
 		Host           string `envconfig:"SERVER_HOST"`
 		Port           string `envconfig:"PORT" default:"8080" required:"true"`
 		TimeoutSeconds uint   `envconfig:"SERVER_TIMEOUT" default:"30"`
+		// ExportTimeoutSeconds bounds exports, which stream the whole timeline.
+		ExportTimeoutSeconds uint  `envconfig:"SERVER_EXPORT_TIMEOUT" default:"300"`
+		BatchMaxSize         int   `envconfig:"SERVER_BATCH_MAX_SIZE" default:"500"`
+		BatchMaxBytes        int64 `envconfig:"SERVER_BATCH_MAX_BYTES" default:"10485760"`
+		ImportMaxBytes       int64 `envconfig:"SERVER_IMPORT_MAX_BYTES" default:"10485760"`
+		// TrustProxyHeaders takes the base URL of the site from the Host and X-Forwarded headers of requests
+		// unless it is configured, for proxies serving the site under several names.
+		TrustProxyHeaders bool `envconfig:"SERVER_TRUST_PROXY_HEADERS" default:"false"`
+	}
+
+	Media struct {
+		Backend        string `envconfig:"MEDIA_BACKEND" default:"filesystem"`
+		Dir            string `envconfig:"MEDIA_DIR" default:"media"`
+		MaxUploadBytes int64  `envconfig:"MEDIA_MAX_UPLOAD_BYTES" default:"10485760"`
+		MaxDimension   int    `envconfig:"MEDIA_MAX_DIMENSION" default:"8000"`
+
+		S3 struct {
+			Endpoint  string `envconfig:"MEDIA_S3_ENDPOINT"`
+			Region    string `envconfig:"MEDIA_S3_REGION" default:"us-east-1"`
+			Bucket    string `envconfig:"MEDIA_S3_BUCKET"`
+			AccessKey string `envconfig:"MEDIA_S3_ACCESS_KEY"`
+			SecretKey string `envconfig:"MEDIA_S3_SECRET_KEY"`
+		}
+	}
+
+	Site struct {
+		// BaseURL is the scheme and host the site is served at, e.g. https://timeline.example.com.
+		BaseURL   string `envconfig:"SITE_BASE_URL"`
+		Theme     string `envconfig:"SITE_THEME" default:"vertical"`
+		ThemesDir string `envconfig:"SITE_THEMES_DIR"`
+		// EmbedOrigins may show embedded timelines in frames, besides the site itself.
+		EmbedOrigins []string `envconfig:"SITE_EMBED_ORIGINS"`
+	}
+
+	Cache struct {
+		// Backend keeps rendered pages and list responses, postgres shares them between instances of the server.
+		Backend    string `envconfig:"CACHE_BACKEND" default:"memory"`
+		TTLSeconds int    `envconfig:"CACHE_TTL_SECONDS" default:"300"`
+		// MaxEntries and MaxBytes bound the number of pages kept and the total size of their bodies.
+		MaxEntries int   `envconfig:"CACHE_MAX_ENTRIES" default:"500"`
+		MaxBytes   int64 `envconfig:"CACHE_MAX_BYTES" default:"104857600"`
+		// MaxAgeSeconds is how long browsers and proxies may use cached pages without asking whether they changed.
+		MaxAgeSeconds int `envconfig:"CACHE_MAX_AGE_SECONDS" default:"0"`
 	}
 
 	Auth struct {
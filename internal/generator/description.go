@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
+	"html/template"
+	"strings"
+)
+
+// markdownConverter renders CommonMark. Raw HTML is kept so that editors can use it, the sanitizer decides what stays.
+var markdownConverter = goldmark.New(goldmark.WithRendererOptions(html.WithUnsafe()))
+
+// descriptionPolicy allows the markup of user generated content: formatting, lists, tables, links and images, but
+// no scripts, styles, forms, frames or event handler attributes. Links get rel="nofollow" and only safe URL schemes
+// are kept.
+var descriptionPolicy = bluemonday.UGCPolicy().
+	RequireNoFollowOnLinks(true).
+	AddTargetBlankToFullyQualifiedLinks(true)
+
+// DescriptionHTML renders a detailed description to sanitized HTML. Plain text is split into paragraphs at blank
+// lines, Markdown is rendered as CommonMark.
+func (r *Renderer) DescriptionHTML(format timeline.DescriptionFormat, text string) (template.HTML, error) {
+	return descriptionHTML(format, text)
+}
+
+// EventDescriptionHTML renders both descriptions of an event to sanitized HTML, as feeds and exports show them: the
+// short description as plain text, followed by the detailed description in its format.
+func EventDescriptionHTML(e timeline.Event) (template.HTML, error) {
+	detailed, err := descriptionHTML(e.DescriptionFormat, e.DetailedDescription)
+	if err != nil {
+		return "", err
+	}
+	return textHTML(e.ShortDescription) + detailed, nil
+}
+
+func descriptionHTML(format timeline.DescriptionFormat, text string) (template.HTML, error) {
+	switch format {
+	case "", timeline.DescriptionFormatText:
+		return textHTML(text), nil
+	case timeline.DescriptionFormatMarkdown:
+		return markdown(text)
+	default:
+		return "", fmt.Errorf("%w: unknown description format %q", timeline.ErrInvalid, format)
+	}
+}
+
+// markdown renders Markdown to sanitized HTML.
+func markdown(text string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := markdownConverter.Convert([]byte(text), &buf); err != nil {
+		return "", err
+	}
+	return template.HTML(descriptionPolicy.SanitizeBytes(buf.Bytes())), nil
+}
+
+// textHTML escapes plain text, blank lines separate paragraphs and single line breaks are kept.
+func textHTML(text string) template.HTML {
+	var b strings.Builder
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i := range lines {
+			lines[i] = template.HTMLEscapeString(strings.TrimSpace(lines[i]))
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br>\n") + "</p>\n")
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDescriptionHTML(t *testing.T) {
+	tests := map[string]struct {
+		format timeline.DescriptionFormat
+		text   string
+		want   string
+	}{
+		"plain text": {
+			text: "One small step <for> man.\nOne giant leap.\n\nTranquility Base.",
+			want: "<p>One small step &lt;for&gt; man.<br>\nOne giant leap.</p>\n<p>Tranquility Base.</p>\n",
+		},
+		"plain text markdown is not rendered": {
+			format: timeline.DescriptionFormatText,
+			text:   "*not emphasized*",
+			want:   "<p>*not emphasized*</p>\n",
+		},
+		"markdown": {
+			format: timeline.DescriptionFormatMarkdown,
+			text:   "Launched by *Saturn V*:\n\n- [NASA](/nasa)\n- `code`",
+			want: "<p>Launched by <em>Saturn V</em>:</p>\n<ul>\n" +
+				"<li><a href=\"/nasa\" rel=\"nofollow\">NASA</a></li>\n<li><code>code</code></li>\n</ul>\n",
+		},
+		"script tags": {
+			format: timeline.DescriptionFormatMarkdown,
+			text:   "Hello <script>alert(1)</script><b onclick=\"alert(1)\">world</b>",
+			want:   "<p>Hello <b>world</b></p>\n",
+		},
+		"javascript links": {
+			format: timeline.DescriptionFormatMarkdown,
+			text:   "[click](javascript:alert(1)) <a href=\"javascript:alert(1)\">me</a>",
+			want:   "<p>click me</p>\n",
+		},
+		"external links": {
+			format: timeline.DescriptionFormatMarkdown,
+			text:   "<https://www.nasa.gov>",
+			want:   "<p><a href=\"https://www.nasa.gov\" rel=\"nofollow noopener\" target=\"_blank\">https://www.nasa.gov</a></p>\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := descriptionHTML(tt.format, tt.text)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+
+	_, err := descriptionHTML("html", "")
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EmbedAll names the embedded timeline of all events, other timelines are named by the ID of their type.
+	EmbedAll = "all"
+	// DefaultEmbedHeight is the height of embedded timelines in pixels unless requested otherwise.
+	DefaultEmbedHeight = 600
+	// MinEmbedHeight and MaxEmbedHeight bound the height of embedded timelines in pixels.
+	MinEmbedHeight = 150
+	MaxEmbedHeight = 4000
+)
+
+// embedScript is the path of the script inserting embedded timelines into other pages.
+const embedScript = "static/js/embed.js"
+
+// EmbedSnippet is the HTML other sites include to embed a timeline.
+type EmbedSnippet struct {
+	// URL of the embedded page.
+	URL string
+	// Script inserts a frame showing the page where it is included.
+	Script string
+	// IFrame is the frame itself, for pages that do not allow scripts.
+	IFrame string
+}
+
+// NewEmbedSnippet returns the snippet embedding a timeline of the site at baseURL, with query holding the filters,
+// layout and theme of the page.
+func NewEmbedSnippet(baseURL, timelineName string, query url.Values, height int) EmbedSnippet {
+	if height <= 0 {
+		height = DefaultEmbedHeight
+	}
+	v := url.Values{}
+	for key, values := range query {
+		v[key] = values
+	}
+	v.Set("height", strconv.Itoa(height))
+	base := strings.TrimSuffix(baseURL, "/")
+	src := base + "/embed/" + url.PathEscape(timelineName) + "?" + v.Encode()
+
+	return EmbedSnippet{
+		URL: src,
+		Script: fmt.Sprintf(`<script src="%s" data-timeline="%s" data-height="%d" async></script>`,
+			html.EscapeString(base+"/"+embedScript), html.EscapeString(src), height),
+		IFrame: fmt.Sprintf(`<iframe src="%s" title="Timeline" width="100%%" height="%d" style="border: 0" loading="lazy"></iframe>`,
+			html.EscapeString(src), height),
+	}
+}
+
+// embedOriginPattern matches origins like https://docs.example.com:8443, the host may start with a *. wildcard
+// standing for its subdomains.
+var embedOriginPattern = regexp.MustCompile(`^https?://(\*\.)?[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)*(:[0-9]{1,5})?$`)
+
+// FrameAncestors returns the frame-ancestors directive of the Content-Security-Policy of embedded timelines. The
+// site itself may always frame them, so may the given origins, "*" allowing any site.
+func FrameAncestors(origins []string) (string, error) {
+	sources := []string{"'self'"}
+	for _, origin := range origins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		switch {
+		case origin == "":
+			continue
+		case origin == "*":
+			return "frame-ancestors *", nil
+		case !embedOriginPattern.MatchString(origin):
+			return "", fmt.Errorf("%w: invalid embed origin %q, expected an origin like https://example.com", timeline.ErrInvalid, origin)
+		}
+		sources = append(sources, strings.ToLower(origin))
+	}
+	return "frame-ancestors " + strings.Join(sources, " "), nil
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewEmbedSnippet(t *testing.T) {
+	snippet := NewEmbedSnippet("https://example.com/", "3", url.Values{"theme": {"compact"}}, 0)
+	require.Equal(t, "https://example.com/embed/3?height=600&theme=compact", snippet.URL)
+	require.Equal(t, `<script src="https://example.com/static/js/embed.js" `+
+		`data-timeline="https://example.com/embed/3?height=600&amp;theme=compact" data-height="600" async></script>`,
+		snippet.Script)
+	require.Contains(t, snippet.IFrame, `src="https://example.com/embed/3?height=600&amp;theme=compact"`)
+	require.Contains(t, snippet.IFrame, `height="600"`)
+}
+
+func TestFrameAncestors(t *testing.T) {
+	csp, err := FrameAncestors(nil)
+	require.NoError(t, err)
+	require.Equal(t, "frame-ancestors 'self'", csp)
+
+	csp, err = FrameAncestors([]string{"https://Intranet.example.com/", " https://*.docs.example.com:8443", ""})
+	require.NoError(t, err)
+	require.Equal(t, "frame-ancestors 'self' https://intranet.example.com https://*.docs.example.com:8443", csp)
+
+	csp, err = FrameAncestors([]string{"https://example.com", "*"})
+	require.NoError(t, err)
+	require.Equal(t, "frame-ancestors *", csp)
+
+	for _, origin := range []string{"example.com", "https://example.com/path", "ftp://example.com", "https://ex ample.com"} {
+		_, err = FrameAncestors([]string{origin})
+		require.ErrorIs(t, err, timeline.ErrInvalid, origin)
+	}
+}
+
+func TestRenderSiteEmbed(t *testing.T) {
+	r, err := NewRenderer(RendererOptions{})
+	require.NoError(t, err)
+	events := []timeline.Event{{ID: 1, Name: "Apollo 11", EventTime: time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC)}}
+
+	for _, theme := range r.Themes() {
+		page, err := r.RenderSite(events, SiteOptions{Theme: theme, Embed: true, Height: 400, Links: SiteLinks{Root: "/"}})
+		require.NoError(t, err)
+		require.Contains(t, string(page), `<meta name="robots" content="noindex">`, theme)
+		require.Contains(t, string(page), `height: 400px`, theme)
+		require.NotContains(t, string(page), `timeline-filter-bar`, theme)
+		require.NotContains(t, string(page), `timeline-layout`, theme)
+	}
+}
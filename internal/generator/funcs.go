@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateFuncs are the helpers available to the templates of all themes.
+var templateFuncs = template.FuncMap{
+	"formatDate": formatDate,
+	"isoTime":    isoTime,
+	"textColor":  textColor,
+	"alphaColor": alphaColor,
+	"markdown":   markdown,
+}
+
+// formatDate formats a time with a Go layout like "2 January 2006".
+func formatDate(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// isoTime formats a time for datetime attributes and structured data.
+func isoTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// parseHexColor reads #rgb and #rrggbb colors, the forms the type service normalizes colors to apart from names.
+func parseHexColor(color string) (r, g, b uint8, ok bool) {
+	c := strings.TrimPrefix(strings.TrimSpace(color), "#")
+	if len(c) == 3 {
+		c = string([]byte{c[0], c[0], c[1], c[1], c[2], c[2]})
+	}
+	if len(c) != 6 && len(c) != 8 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(c[:6], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// textColor returns black or white, whichever reads better on a background of the given color.
+// Colors other than hex colors get the dark text of the default marker.
+func textColor(background string) string {
+	r, g, b, ok := parseHexColor(background)
+	if !ok {
+		return "#131313"
+	}
+	// perceived brightness as in the W3C accessibility guidelines for color contrast
+	if (299*int(r)+587*int(g)+114*int(b))/1000 >= 128 {
+		return "#131313"
+	}
+	return "#eeefef"
+}
+
+// alphaColor makes a hex color translucent, e.g. for backgrounds tinted with the type color.
+// Other colors are returned as they are.
+func alphaColor(color string, alpha float64) string {
+	r, g, b, ok := parseHexColor(color)
+	if !ok {
+		return color
+	}
+	return fmt.Sprintf("rgba(%d, %d, %d, %s)", r, g, b, strconv.FormatFloat(alpha, 'f', -1, 64))
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"net/url"
+	"strconv"
+)
+
+// Grouping selects the periods events are listed under.
+type Grouping string
+
+const (
+	GroupByYear    Grouping = "year"
+	GroupByDecade  Grouping = "decade"
+	GroupByCentury Grouping = "century"
+)
+
+// Order is the chronological direction events are listed in.
+type Order string
+
+const (
+	OrderDescending Order = "desc"
+	OrderAscending  Order = "asc"
+)
+
+// Layout arranges the events of a timeline page. The zero value groups events by year, the most recent first.
+type Layout struct {
+	Grouping Grouping
+	Order    Order
+}
+
+// ParseLayout reads a layout as given in query parameters, empty values select the defaults.
+func ParseLayout(grouping, order string) (Layout, error) {
+	l := Layout{Grouping: Grouping(grouping), Order: Order(order)}
+	switch l.Grouping {
+	case "":
+		l.Grouping = GroupByYear
+	case GroupByYear, GroupByDecade, GroupByCentury:
+	default:
+		return Layout{}, fmt.Errorf("%w: unknown grouping %q", timeline.ErrInvalid, grouping)
+	}
+	switch l.Order {
+	case "":
+		l.Order = OrderDescending
+	case OrderDescending, OrderAscending:
+	default:
+		return Layout{}, fmt.Errorf("%w: unknown order %q", timeline.ErrInvalid, order)
+	}
+	return l, nil
+}
+
+// query adds the parameters selecting the layout to v, leaving out defaults.
+func (l Layout) query(v url.Values) {
+	if l.Grouping != "" && l.Grouping != GroupByYear {
+		v.Set("group", string(l.Grouping))
+	}
+	if l.Order != "" && l.Order != OrderDescending {
+		v.Set("order", string(l.Order))
+	}
+}
+
+// Group is a period of the timeline with the events that happened in it.
+type Group struct {
+	Label    string
+	Grouping Grouping
+	Events   []Event
+}
+
+// groupEvents splits sorted events into consecutive periods.
+func groupEvents(events []Event, grouping Grouping) []Group {
+	var groups []Group
+	for _, e := range events {
+		label := periodLabel(e.EventTime.Year(), grouping)
+		if len(groups) == 0 || groups[len(groups)-1].Label != label {
+			groups = append(groups, Group{Label: label, Grouping: grouping})
+		}
+		groups[len(groups)-1].Events = append(groups[len(groups)-1].Events, e)
+	}
+	return groups
+}
+
+// periodLabel names the period a year falls into. Years before 1 AD are astronomical, year 0 is 1 BC.
+func periodLabel(year int, grouping Grouping) string {
+	switch grouping {
+	case GroupByDecade:
+		return fmt.Sprintf("%ds", floorDiv(year, 10)*10)
+	case GroupByCentury:
+		if year <= 0 {
+			return ordinal((-year)/100+1) + " century BC"
+		}
+		return ordinal((year-1)/100+1) + " century"
+	default:
+		return strconv.Itoa(year)
+	}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPeriodLabel(t *testing.T) {
+	tests := []struct {
+		year     int
+		grouping Grouping
+		want     string
+	}{
+		{1969, GroupByYear, "1969"},
+		{1969, GroupByDecade, "1960s"},
+		{-5, GroupByDecade, "-10s"},
+		{1969, GroupByCentury, "20th century"},
+		{2000, GroupByCentury, "20th century"},
+		{2001, GroupByCentury, "21st century"},
+		{1112, GroupByCentury, "12th century"},
+		{0, GroupByCentury, "1st century BC"},
+		{-100, GroupByCentury, "2nd century BC"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, periodLabel(tt.year, tt.grouping))
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	l, err := ParseLayout("", "")
+	require.NoError(t, err)
+	require.Equal(t, Layout{Grouping: GroupByYear, Order: OrderDescending}, l)
+
+	_, err = ParseLayout("week", "")
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+	_, err = ParseLayout("", "random")
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
+
+func TestSortAndGroupEvents(t *testing.T) {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	d := data{
+		Layout: Layout{Grouping: GroupByDecade, Order: OrderAscending},
+		Events: []Event{
+			{ID: 1, EventTime: at(1969, 7, 20)},
+			{ID: 2, EventTime: at(1957, 10, 4)},
+			{ID: 3, EventTime: at(1969, 1, 1)},
+			{ID: 4, EventTime: at(1961, 4, 12)},
+			{ID: 5, EventTime: at(1969, 1, 1)},
+		},
+	}
+	d.Sort()
+	groups := groupEvents(d.Events, d.Layout.Grouping)
+
+	require.Len(t, groups, 2)
+	require.Equal(t, "1950s", groups[0].Label)
+	var ids []uint
+	for _, e := range groups[1].Events {
+		ids = append(ids, e.ID)
+	}
+	require.Equal(t, []uint{4, 3, 5, 1}, ids)
+
+	d.Layout.Order = OrderDescending
+	d.Sort()
+	require.Equal(t, uint(1), d.Events[0].ID)
+	require.Equal(t, uint(5), d.Events[1].ID)
+}
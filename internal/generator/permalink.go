@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"encoding/json"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxSlugLength keeps permalinks of events with long names readable.
+const maxSlugLength = 60
+
+// EventPagePath is the path of the permalink page of an event served by the server, e.g. "events/42-apollo-11".
+func EventPagePath(id uint, name string) string {
+	p := "events/" + strconv.FormatUint(uint64(id), 10)
+	if s := slug(name); s != "" {
+		p += "-" + s
+	}
+	return p
+}
+
+// slug turns a name into lower case words joined by dashes, e.g. "Apollo 11: Landing" into "apollo-11-landing".
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = b.Len() > 0
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		if b.Len()+len(string(r)) > maxSlugLength {
+			break
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// neighbourEvents finds the events right before and after e in chronological order, events at the same time
+// ordered by ID as on the timeline page.
+func neighbourEvents(events []timeline.Event, e timeline.Event) (previous, next *RelatedEvent) {
+	sorted := append([]timeline.Event(nil), events...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].EventTime.Equal(sorted[j].EventTime) {
+			return sorted[i].EventTime.Before(sorted[j].EventTime)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	for i := range sorted {
+		if sorted[i].ID != e.ID {
+			continue
+		}
+		if i > 0 {
+			previous = &RelatedEvent{ID: sorted[i-1].ID, Name: sorted[i-1].Name, Label: "Previous"}
+		}
+		if i < len(sorted)-1 {
+			next = &RelatedEvent{ID: sorted[i+1].ID, Name: sorted[i+1].Name, Label: "Next"}
+		}
+		break
+	}
+	return previous, next
+}
+
+// EventMeta describes an event page in OpenGraph and Twitter card meta tags and in schema.org structured data.
+// URL and Image are absolute, they are empty without a base URL of the site.
+type EventMeta struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	// TwitterCard is "summary_large_image" for events with a graphic, "summary" otherwise.
+	TwitterCard string
+	// StructuredData is the JSON-LD of a schema.org Event.
+	StructuredData template.JS
+}
+
+// structuredEvent is a schema.org Event as JSON-LD.
+type structuredEvent struct {
+	Context     string   `json:"@context"`
+	Type        string   `json:"@type"`
+	Name        string   `json:"name"`
+	StartDate   string   `json:"startDate"`
+	Description string   `json:"description,omitempty"`
+	Image       []string `json:"image,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Keywords    string   `json:"keywords,omitempty"`
+	About       string   `json:"about,omitempty"`
+}
+
+func eventMeta(e timeline.Event, t *LegendType, links SiteLinks) (EventMeta, error) {
+	meta := EventMeta{
+		Title:       e.Name,
+		Description: e.ShortDescription,
+		URL:         links.absolute(links.eventPage(e.ID, e.Name)),
+		TwitterCard: "summary",
+	}
+	if e.Graphic != "" {
+		meta.Image = links.absolute(graphicVariantPath(e.Graphic, timeline.MediaVariantMedium))
+		meta.TwitterCard = "summary_large_image"
+	}
+
+	data := structuredEvent{
+		Context:     "https://schema.org",
+		Type:        "Event",
+		Name:        e.Name,
+		StartDate:   e.EventTime.Format(time.RFC3339),
+		Description: e.ShortDescription,
+		URL:         meta.URL,
+		Keywords:    strings.Join(e.Tags, ", "),
+	}
+	if meta.Image != "" {
+		data.Image = []string{meta.Image}
+	}
+	if t != nil {
+		data.About = t.Name
+	}
+	// the encoder escapes <, > and &, so the JSON cannot end the script element it is embedded in
+	b, err := json.Marshal(data)
+	if err != nil {
+		return EventMeta{}, err
+	}
+	meta.StructuredData = template.JS(b)
+	return meta, nil
+}
@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlug(t *testing.T) {
+	require.Equal(t, "apollo-11-the-eagle-has-landed", slug("Apollo 11: The Eagle has landed!"))
+	require.Equal(t, "восток-1", slug(" Восток-1 "))
+	require.Equal(t, "", slug("?!"))
+	require.Len(t, slug(strings.Repeat("moon ", 30)), 59)
+
+	require.Equal(t, "events/42-apollo-11", EventPagePath(42, "Apollo 11"))
+	require.Equal(t, "events/42", EventPagePath(42, "..."))
+}
+
+func TestNeighbourEvents(t *testing.T) {
+	at := func(year int) time.Time {
+		return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	events := []timeline.Event{
+		{ID: 1, Name: "Apollo 11", EventTime: at(1969)},
+		{ID: 2, Name: "Sputnik 1", EventTime: at(1957)},
+		{ID: 3, Name: "Apollo 12", EventTime: at(1969)},
+	}
+
+	previous, next := neighbourEvents(events, events[0])
+	require.Equal(t, uint(2), previous.ID)
+	require.Equal(t, uint(3), next.ID)
+
+	previous, next = neighbourEvents(events, events[1])
+	require.Nil(t, previous)
+	require.Equal(t, uint(1), next.ID)
+}
+
+func TestRenderEventMeta(t *testing.T) {
+	r, err := NewRenderer(RendererOptions{})
+	require.NoError(t, err)
+	event := timeline.Event{
+		ID:               1,
+		Name:             "Apollo 11 </script>",
+		EventTime:        time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC),
+		ShortDescription: "First crewed Moon landing",
+		Graphic:          "/media/eagle.jpg",
+	}
+
+	page, err := r.RenderEvent(event, EventPageOptions{
+		Events: []timeline.Event{event},
+		Links:  SiteLinks{Root: "/", EventPages: true, BaseURL: "https://example.com"},
+	})
+	require.NoError(t, err)
+	html := string(page)
+	require.Contains(t, html, `<link rel="canonical" href="https://example.com/events/1-apollo-11-script">`)
+	require.Contains(t, html, `<meta property="og:image" content="https://example.com/media/eagle-medium.jpg">`)
+	require.Contains(t, html, `<meta name="twitter:card" content="summary_large_image">`)
+	require.Contains(t, html, `"@type":"Event","name":"Apollo 11 \u003c/script\u003e","startDate":"1969-07-20T20:17:00Z"`)
+	require.NotContains(t, html, "timeline-event-nav")
+
+	page, err = r.RenderEvent(event, EventPageOptions{Links: SiteLinks{Root: "../", Static: true, EventPages: true}})
+	require.NoError(t, err)
+	require.NotContains(t, string(page), "canonical")
+}
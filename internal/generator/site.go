@@ -2,46 +2,248 @@ package generator
 
 import (
 	"bytes"
-	_ "embed"
 	"fmt"
 	"github.com/kamkali/go-timeline/internal/timeline"
 	"html/template"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-//go:embed template.gohtml
-var htmlContent string
-
 type Renderer struct {
-	template *template.Template
+	themes       map[string]*Theme
+	defaultTheme string
+}
+
+// RendererOptions configure the themes of a renderer.
+type RendererOptions struct {
+	// ThemesDir holds additional themes, one per subdirectory. They replace built-in themes of the same name.
+	ThemesDir string
+	// DefaultTheme renders pages that neither request a theme nor belong to a type with one.
+	DefaultTheme string
 }
 
-func NewRenderer() (*Renderer, error) {
-	r := &Renderer{}
-	if err := r.loadTemplate(); err != nil {
+func NewRenderer(opts RendererOptions) (*Renderer, error) {
+	r := &Renderer{themes: map[string]*Theme{}, defaultTheme: opts.DefaultTheme}
+	if r.defaultTheme == "" {
+		r.defaultTheme = DefaultTheme
+	}
+	if err := r.loadThemes(opts.ThemesDir); err != nil {
 		return nil, err
 	}
+	if r.themes[r.defaultTheme] == nil {
+		return nil, fmt.Errorf("default theme %q not found", r.defaultTheme)
+	}
 	return r, nil
 }
 
-func (r *Renderer) loadTemplate() error {
-	r.template = template.Must(template.New("").Parse(htmlContent))
-	return nil
-}
-
 type Event struct {
 	ID                  uint
 	Name                string
 	EventTime           time.Time
 	ShortDescription    string
 	DetailedDescription string
-	Graphic             template.URL
-	TypeID              uint
+	// DescriptionHTML is the detailed description rendered according to its format.
+	DescriptionHTML  template.HTML
+	Graphic          template.URL
+	GraphicThumbnail template.URL
+	GraphicMedium    template.URL
+	TypeID           uint
+	Marker           Marker
+	Tags             []string
+	Related          []RelatedEvent
+	// Open events are expanded when the page loads, e.g. the event a shared link points to.
+	Open bool
+}
+
+// Marker is the colored, optionally iconified bullet of an event or a legend entry.
+type Marker struct {
+	Title string
+	Color string
+	Icon  string
+	Glyph string
+}
+
+// iconGlyphs are the icons the renderer can draw. Other icon identifiers are only exposed to stylesheets.
+var iconGlyphs = map[string]string{
+	"book":      "\U0001F4D6",
+	"flag":      "\u2691",
+	"flame":     "\U0001F525",
+	"globe":     "\U0001F30D",
+	"moon":      "\U0001F319",
+	"person":    "\U0001F464",
+	"planet":    "\U0001FA90",
+	"rocket":    "\U0001F680",
+	"satellite": "\U0001F6F0",
+	"star":      "\u2605",
+	"telescope": "\U0001F52D",
+	"trophy":    "\U0001F3C6",
+	"warning":   "\u26A0",
+}
+
+type LegendType struct {
+	ID     uint
+	Name   string
+	Marker Marker
+}
+
+type RelatedEvent struct {
+	ID    uint
+	Name  string
+	Label string
+}
+
+// relationLabels describe a relation from the point of view of its source and its target event.
+var relationLabels = map[timeline.RelationKind][2]string{
+	timeline.RelationCausedBy: {"Caused by", "Led to"},
+	timeline.RelationFollows:  {"Follows", "Followed by"},
+	timeline.RelationPartOf:   {"Part of", "Includes"},
+}
+
+// SiteOptions describe how the site was requested.
+type SiteOptions struct {
+	Filter    timeline.EventFilter
+	Relations []timeline.Relation
+	// Types are expected in display order and with their effective colors resolved.
+	Types  []timeline.Type
+	Layout Layout
+	// Theme is the theme requested for the page. Without one, pages filtered by type use the theme of the type
+	// or of its closest ancestor setting one, other pages the default theme.
+	Theme string
+	// OpenEvent is expanded when the page loads.
+	OpenEvent uint
+	// Embed renders the page for frames on other sites, without the controls of the page and with links to
+	// other pages opening outside of the frame.
+	Embed bool
+	// Height of an embedded page in pixels, its events scroll within it. Zero lets the page grow.
+	Height int
+	Links  SiteLinks
+}
+
+// SiteLinks resolve the URLs pages refer to. The zero value links pages served by the server.
+type SiteLinks struct {
+	// Root is prepended to the paths of assets, media and other pages, e.g. "../" for pages in subdirectories.
+	Root string
+	// Static pages have no server behind them to filter events or serve feeds,
+	// filters link to pre-rendered pages instead.
+	Static bool
+	// EventPages link events to their permalink page.
+	EventPages bool
+	// BaseURL is the absolute URL of the root of the site, e.g. "https://example.com". Canonical URLs and the
+	// metadata of shared links are left out without one.
+	BaseURL string
+
+	// layout and the requested theme are kept by links to filtered pages.
+	layout         Layout
+	requestedTheme string
+	// theme is the theme rendering the page.
+	theme string
+}
+
+// TypeURL returns the page showing the events of a type.
+func (l SiteLinks) TypeURL(id uint) string {
+	if l.Static {
+		return l.Root + staticTypePage(id)
+	}
+	return l.query(url.Values{"type": {strconv.FormatUint(uint64(id), 10)}})
+}
+
+// TagURL returns the page showing the events with a tag, or an empty string for static pages.
+func (l SiteLinks) TagURL(tag string) string {
+	if l.Static {
+		return ""
+	}
+	return l.query(url.Values{"tag": {tag}})
+}
+
+// AllURL returns the page showing all events.
+func (l SiteLinks) AllURL() string {
+	if l.Static {
+		return l.Root + staticIndexPage
+	}
+	return l.query(url.Values{})
+}
+
+func (l SiteLinks) query(v url.Values) string {
+	l.layout.query(v)
+	if l.requestedTheme != "" {
+		v.Set("theme", l.requestedTheme)
+	}
+	return l.Root + "?" + v.Encode()
+}
+
+// jQueryURL serves the version of jQuery the pages are written for.
+const jQueryURL = "https://ajax.googleapis.com/ajax/libs/jquery/1.9.1/jquery.min.js"
+
+// JQuery returns the URL of the jQuery script. Static pages may be viewed offline, they use the copy bundled with
+// the assets.
+func (l SiteLinks) JQuery() string {
+	if l.Static {
+		return l.Root + staticAssetsDir + "/js/jquery.min.js"
+	}
+	return jQueryURL
+}
+
+// ThemeAsset returns the URL of an asset of the theme rendering the page.
+func (l SiteLinks) ThemeAsset(name string) string {
+	return l.Root + themeAssetsPath(l.theme) + name
+}
+
+// EventURL returns the permalink page of an event, or an empty string without event pages.
+func (l SiteLinks) EventURL(id uint, name string) string {
+	if !l.EventPages {
+		return ""
+	}
+	return l.Root + l.eventPage(id, name)
+}
+
+// eventPage is the path of the permalink page of an event relative to the root of the site. Pages served by the
+// server name the event in their path, the ID alone still finds it after it was renamed.
+func (l SiteLinks) eventPage(id uint, name string) string {
+	if l.Static {
+		return staticEventPage(id)
+	}
+	return EventPagePath(id, name)
+}
+
+// absolute returns the absolute URL of a path relative to the root of the site, or of stored media,
+// or an empty string without a base URL. Absolute URLs are returned as they are.
+func (l SiteLinks) absolute(path string) string {
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	if l.BaseURL == "" || path == "" {
+		return ""
+	}
+	return strings.TrimSuffix(l.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// graphic makes stored media relative to the root of static pages, which serve it from their own directory.
+func (l SiteLinks) graphic(graphic string) string {
+	if l.Static && strings.HasPrefix(graphic, timeline.MediaPathPrefix) {
+		return l.Root + strings.TrimPrefix(graphic, "/")
+	}
+	return graphic
 }
 
 type data struct {
 	Events []Event
+	Groups []Group
+	Filter timeline.EventFilter
+	// FilterTypes are the names of the types the page is filtered by.
+	FilterTypes []string
+	// Legend lists the types of the events on the page, Types all types to filter by.
+	Legend []LegendType
+	Types  []LegendType
+	Layout Layout
+	Links  SiteLinks
+	// Theme renders the page, Themes lists all themes available.
+	Theme  string
+	Themes []string
+	Embed  bool
+	Height int
 }
 
 func (d *data) Sort() {
@@ -50,29 +252,335 @@ func (d *data) Sort() {
 
 func (d *data) Len() int      { return len(d.Events) }
 func (d *data) Swap(i, j int) { d.Events[i], d.Events[j] = d.Events[j], d.Events[i] }
+
+// Less orders events by their full time, events at the same time by ID to keep the page stable.
 func (d *data) Less(i, j int) bool {
-	return d.Events[i].EventTime.Year() > d.Events[j].EventTime.Year()
+	a, b := d.Events[i], d.Events[j]
+	if d.Layout.Order == OrderAscending {
+		a, b = b, a
+	}
+	if !a.EventTime.Equal(b.EventTime) {
+		return a.EventTime.After(b.EventTime)
+	}
+	return a.ID > b.ID
+}
+
+// LayoutURL returns the current page with another grouping or order, an empty value keeps the current one.
+func (d data) LayoutURL(grouping, order string) string {
+	links := d.Links
+	if grouping != "" {
+		links.layout.Grouping = Grouping(grouping)
+	}
+	if order != "" {
+		links.layout.Order = Order(order)
+	}
+	return d.pageURL(links)
+}
+
+// CanonicalURL returns the absolute URL of the page showing the events the page shows,
+// or an empty string without a base URL.
+func (d data) CanonicalURL() string {
+	if d.Links.Static {
+		if len(d.Filter.TypeIDs) == 1 {
+			return d.Links.absolute(staticTypePage(d.Filter.TypeIDs[0]))
+		}
+		return d.Links.absolute(staticIndexPage)
+	}
+	v := url.Values{}
+	filterQuery(d.Filter, v)
+	if len(v) == 0 {
+		return d.Links.absolute("/")
+	}
+	return d.Links.absolute("/?" + v.Encode())
+}
+
+// ThemeURL returns the current page rendered by another theme.
+func (d data) ThemeURL(theme string) string {
+	links := d.Links
+	links.requestedTheme = theme
+	return d.pageURL(links)
+}
+
+func (d data) pageURL(links SiteLinks) string {
+	v := url.Values{}
+	filterQuery(d.Filter, v)
+	return links.query(v)
+}
+
+// EventLink returns a link to the current page that opens an event and scrolls to it.
+func (d data) EventLink(id uint) string {
+	fragment := "#event" + strconv.FormatUint(uint64(id), 10)
+	if d.Links.Static {
+		return fragment
+	}
+	v := url.Values{}
+	filterQuery(d.Filter, v)
+	v.Set("event", strconv.FormatUint(uint64(id), 10))
+	return d.Links.query(v) + fragment
+}
+
+// FeedURL returns a feed of the events matching the filters of the page.
+func (d data) FeedURL(path string) string {
+	v := url.Values{}
+	filterQuery(d.Filter, v)
+	if len(v) == 0 {
+		return path
+	}
+	return path + "?" + v.Encode()
+}
+
+// FormParams are the query parameters the filter form keeps as hidden fields.
+func (d data) FormParams() url.Values {
+	v := url.Values{}
+	if d.Filter.TagMode != "" && d.Filter.TagMode != timeline.TagModeAny {
+		v.Set("tag_mode", string(d.Filter.TagMode))
+	}
+	d.Links.layout.query(v)
+	if d.Links.requestedTheme != "" {
+		v.Set("theme", d.Links.requestedTheme)
+	}
+	return v
+}
+
+// FilterFrom and FilterTo format the bounds of the time range the page is filtered by.
+func (d data) FilterFrom() string {
+	return formatFilterTime(d.Filter.From, false)
+}
+
+func (d data) FilterTo() string {
+	return formatFilterTime(d.Filter.To, true)
+}
+
+// FilterByType reports whether the page is filtered by a type.
+func (d data) FilterByType(id uint) bool {
+	for _, typeID := range d.Filter.TypeIDs {
+		if typeID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// filterQuery adds the parameters selecting the events of a filter to v, as read by the server.
+func filterQuery(f timeline.EventFilter, v url.Values) {
+	for _, id := range f.TypeIDs {
+		v.Add("type", strconv.FormatUint(uint64(id), 10))
+	}
+	for _, tag := range f.Tags {
+		v.Add("tag", tag)
+	}
+	if f.TagMode != "" && f.TagMode != timeline.TagModeAny {
+		v.Set("tag_mode", string(f.TagMode))
+	}
+	if !f.From.IsZero() {
+		v.Set("from", formatFilterTime(f.From, false))
+	}
+	if !f.To.IsZero() {
+		v.Set("to", formatFilterTime(f.To, true))
+	}
+	if f.Query != "" {
+		v.Set("q", f.Query)
+	}
+}
+
+// formatFilterTime writes times at day boundaries as dates, the form date inputs use. Ends of ranges are
+// inclusive, so the last moment of a day is written as that day.
+func formatFilterTime(t time.Time, end bool) string {
+	if t.IsZero() {
+		return ""
+	}
+	day := t.UTC()
+	if end {
+		day = day.Add(time.Nanosecond)
+	}
+	if !day.Equal(day.Truncate(24 * time.Hour)) {
+		return t.Format(time.RFC3339Nano)
+	}
+	if end {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day.Format(timeline.FilterDateLayout)
+}
+
+// linkRelated attaches the relations between the rendered events to both of their ends.
+func (d *data) linkRelated(relations []timeline.Relation) {
+	byID := map[uint]*Event{}
+	for i := range d.Events {
+		byID[d.Events[i].ID] = &d.Events[i]
+	}
+	for _, r := range relations {
+		from, to := byID[r.FromID], byID[r.ToID]
+		labels, ok := relationLabels[r.Kind]
+		if from == nil || to == nil || !ok {
+			continue
+		}
+		from.Related = append(from.Related, RelatedEvent{ID: to.ID, Name: to.Name, Label: labels[0]})
+		to.Related = append(to.Related, RelatedEvent{ID: from.ID, Name: from.Name, Label: labels[1]})
+	}
+}
+
+// applyTypes styles the event markers and builds the legend of the types present on the page.
+func (d *data) applyTypes(types []timeline.Type) {
+	used := map[uint]bool{}
+	byID := map[uint]*timeline.Type{}
+	for i := range types {
+		byID[types[i].ID] = &types[i]
+	}
+	for i := range d.Events {
+		if t := byID[d.Events[i].TypeID]; t != nil {
+			d.Events[i].Marker = typeMarker(t)
+			used[t.ID] = true
+		}
+	}
+	for i := range types {
+		lt := LegendType{ID: types[i].ID, Name: types[i].Name, Marker: typeMarker(&types[i])}
+		d.Types = append(d.Types, lt)
+		if used[types[i].ID] {
+			d.Legend = append(d.Legend, lt)
+		}
+	}
+	for _, id := range d.Filter.TypeIDs {
+		if t := byID[id]; t != nil {
+			d.FilterTypes = append(d.FilterTypes, t.Name)
+		}
+	}
 }
 
-func (r *Renderer) RenderSite(events []timeline.Event) ([]byte, error) {
-	var d data
+func typeMarker(t *timeline.Type) Marker {
+	return Marker{
+		Title: t.Name,
+		Color: t.EffectiveColor,
+		Icon:  t.Icon,
+		Glyph: iconGlyphs[t.Icon],
+	}
+}
+
+func (r *Renderer) RenderSite(events []timeline.Event, opts SiteOptions) ([]byte, error) {
+	layout, err := ParseLayout(string(opts.Layout.Grouping), string(opts.Layout.Order))
+	if err != nil {
+		return nil, err
+	}
+	// pages of a single type use its theme
+	var typeID uint
+	if len(opts.Filter.TypeIDs) == 1 {
+		typeID = opts.Filter.TypeIDs[0]
+	}
+	theme, err := r.pageTheme(opts.Theme, opts.Types, typeID)
+	if err != nil {
+		return nil, err
+	}
+	d := data{
+		Filter: opts.Filter,
+		Layout: layout,
+		Links:  opts.Links,
+		Theme:  theme.Name,
+		Themes: r.Themes(),
+		Embed:  opts.Embed,
+		Height: opts.Height,
+	}
+	d.Links.layout, d.Links.requestedTheme, d.Links.theme = layout, opts.Theme, theme.Name
 	for _, e := range events {
-		d.Events = append(d.Events, Event{
-			ID:                  e.ID,
-			Name:                e.Name,
-			EventTime:           e.EventTime,
-			ShortDescription:    e.ShortDescription,
-			DetailedDescription: e.DetailedDescription,
-			Graphic:             template.URL(e.Graphic),
-			TypeID:              e.TypeID,
-		})
+		re, err := renderedEvent(e, opts.Links)
+		if err != nil {
+			return nil, err
+		}
+		re.Open = opts.OpenEvent != 0 && e.ID == opts.OpenEvent
+		d.Events = append(d.Events, re)
 	}
 
+	d.applyTypes(opts.Types)
+	d.linkRelated(opts.Relations)
 	d.Sort()
+	d.Groups = groupEvents(d.Events, layout.Grouping)
+
+	var buf bytes.Buffer
+	if err := theme.template.ExecuteTemplate(&buf, siteTemplate, d); err != nil {
+		return nil, fmt.Errorf("execute tmpl: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// EventPageOptions describe the permalink page of an event.
+type EventPageOptions struct {
+	// Types are expected with their effective colors resolved.
+	Types []timeline.Type
+	// Events of the timeline, or at least the events right before and after the rendered one, which the page links to.
+	Events []timeline.Event
+	// Theme is the theme requested for the page, the theme of the event's type is used otherwise.
+	Theme string
+	Links SiteLinks
+}
+
+type eventData struct {
+	Event Event
+	Type  *LegendType
+	// Previous and Next are the neighbours of the event on the timeline, nil at its ends.
+	Previous *RelatedEvent
+	Next     *RelatedEvent
+	Links    SiteLinks
+	Theme    string
+	// Meta describes the event to search engines and to sites the page is shared on.
+	Meta EventMeta
+}
+
+// RenderEvent renders the permalink page of a single event.
+func (r *Renderer) RenderEvent(e timeline.Event, opts EventPageOptions) ([]byte, error) {
+	theme, err := r.pageTheme(opts.Theme, opts.Types, e.TypeID)
+	if err != nil {
+		return nil, err
+	}
+	re, err := renderedEvent(e, opts.Links)
+	if err != nil {
+		return nil, err
+	}
+	d := eventData{Event: re, Links: opts.Links, Theme: theme.Name}
+	d.Links.requestedTheme, d.Links.theme = opts.Theme, theme.Name
+	for i := range opts.Types {
+		if t := &opts.Types[i]; t.ID == e.TypeID {
+			d.Event.Marker = typeMarker(t)
+			d.Type = &LegendType{ID: t.ID, Name: t.Name, Marker: d.Event.Marker}
+		}
+	}
+	d.Previous, d.Next = neighbourEvents(opts.Events, e)
+	if d.Meta, err = eventMeta(e, d.Type, opts.Links); err != nil {
+		return nil, err
+	}
 
 	var buf bytes.Buffer
-	if err := r.template.Execute(&buf, d); err != nil {
+	if err := theme.template.ExecuteTemplate(&buf, eventTemplate, d); err != nil {
 		return nil, fmt.Errorf("execute tmpl: %w", err)
 	}
 	return buf.Bytes(), nil
 }
+
+func renderedEvent(e timeline.Event, links SiteLinks) (Event, error) {
+	description, err := descriptionHTML(e.DescriptionFormat, e.DetailedDescription)
+	if err != nil {
+		return Event{}, fmt.Errorf("event %d: %w", e.ID, err)
+	}
+	return Event{
+		ID:                  e.ID,
+		Name:                e.Name,
+		EventTime:           e.EventTime,
+		ShortDescription:    e.ShortDescription,
+		DetailedDescription: e.DetailedDescription,
+		DescriptionHTML:     description,
+		Graphic:             template.URL(links.graphic(e.Graphic)),
+		GraphicThumbnail:    graphicVariant(e.Graphic, timeline.MediaVariantThumbnail, links),
+		GraphicMedium:       graphicVariant(e.Graphic, timeline.MediaVariantMedium, links),
+		TypeID:              e.TypeID,
+		Tags:                e.Tags,
+	}, nil
+}
+
+// graphicVariant falls back to the original graphic for graphics that were not uploaded to the media store.
+func graphicVariant(graphic, variant string, links SiteLinks) template.URL {
+	return template.URL(links.graphic(graphicVariantPath(graphic, variant)))
+}
+
+func graphicVariantPath(graphic, variant string) string {
+	if p := timeline.MediaVariantPath(graphic, variant); p != "" {
+		return p
+	}
+	return graphic
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestFilterQuery(t *testing.T) {
+	v := url.Values{}
+	filterQuery(timeline.EventFilter{
+		TypeIDs: []uint{2, 3},
+		Tags:    []string{"apollo"},
+		TagMode: timeline.TagModeAny,
+		From:    time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		Query:   "moon landing",
+	}, v)
+	require.Equal(t, "from=1960-01-01&q=moon+landing&tag=apollo&to=1969-12-31&type=2&type=3", v.Encode())
+}
+
+func TestFormatFilterTime(t *testing.T) {
+	at := time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC)
+	require.Equal(t, "", formatFilterTime(time.Time{}, false))
+	require.Equal(t, "1969-07-20", formatFilterTime(at.Truncate(24*time.Hour), false))
+	require.Equal(t, "1969-07-20T20:17:00Z", formatFilterTime(at, false))
+	require.Equal(t, "1969-07-20T20:17:00Z", formatFilterTime(at, true))
+	require.Equal(t, "1969-07-19", formatFilterTime(at.Truncate(24*time.Hour).Add(-time.Nanosecond), true))
+}
+
+func TestEventLink(t *testing.T) {
+	d := data{
+		Filter: timeline.EventFilter{Tags: []string{"apollo"}},
+		Links:  SiteLinks{layout: Layout{Grouping: GroupByDecade}},
+	}
+	require.Equal(t, "?event=7&group=decade&tag=apollo#event7", d.EventLink(7))
+
+	d.Links.Static = true
+	require.Equal(t, "#event7", d.EventLink(7))
+}
+
+func TestRenderSiteOpenEvent(t *testing.T) {
+	r, err := NewRenderer(RendererOptions{})
+	require.NoError(t, err)
+	events := []timeline.Event{
+		{ID: 1, Name: "Sputnik 1", EventTime: time.Date(1957, 10, 4, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "Apollo 11", EventTime: time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC)},
+	}
+
+	page, err := r.RenderSite(events, SiteOptions{OpenEvent: 2})
+	require.NoError(t, err)
+	require.Contains(t, string(page), `<dt class="timeline-event start-open" id="event2">`)
+	require.Contains(t, string(page), `<dt class="timeline-event" id="event1">`)
+
+	page, err = r.RenderSite(events, SiteOptions{OpenEvent: 2, Theme: "compact"})
+	require.NoError(t, err)
+	require.Contains(t, string(page), `<details open>`)
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	staticIndexPage = "index.html"
+	staticAssetsDir = "static"
+)
+
+func staticTypePage(id uint) string {
+	return fmt.Sprintf("types/%d.html", id)
+}
+
+func staticEventPage(id uint) string {
+	return fmt.Sprintf("events/%d.html", id)
+}
+
+// StaticSite is the content of a static export of the timeline.
+type StaticSite struct {
+	Events    []timeline.Event
+	Relations []timeline.Relation
+	// Types are expected in display order and with their effective colors resolved.
+	Types []timeline.Type
+	// EventPages adds a permalink page per event.
+	EventPages bool
+	// BaseURL is the URL the site will be hosted at, for canonical URLs and the metadata of shared links.
+	BaseURL string
+	// Theme renders all pages, instead of the themes of their types.
+	Theme string
+	// Assets are the static files the pages refer to.
+	Assets fs.FS
+	// OpenMedia reads stored media by key.
+	OpenMedia func(key string) (io.ReadCloser, error)
+}
+
+// WriteStaticSite writes a self-contained site to dir: the timeline as index.html, a page per type showing
+// its events and those of its subtypes, optionally a page per event, and the assets of all themes and media
+// they refer to.
+// All links are relative, so that the site can be hosted under any path.
+func (r *Renderer) WriteStaticSite(dir string, site StaticSite) error {
+	links := SiteLinks{Static: true, EventPages: site.EventPages, BaseURL: site.BaseURL}
+	page, err := r.RenderSite(site.Events, SiteOptions{Relations: site.Relations, Types: site.Types, Theme: site.Theme, Links: links})
+	if err != nil {
+		return err
+	}
+	if err := writeStaticFile(dir, staticIndexPage, page); err != nil {
+		return err
+	}
+
+	links.Root = "../"
+	for _, t := range site.Types {
+		descendants := typeDescendants(site.Types, t.ID)
+		var events []timeline.Event
+		for _, e := range site.Events {
+			if descendants[e.TypeID] {
+				events = append(events, e)
+			}
+		}
+		page, err := r.RenderSite(events, SiteOptions{
+			Filter:    timeline.EventFilter{TypeIDs: []uint{t.ID}},
+			Relations: site.Relations,
+			Types:     site.Types,
+			Theme:     site.Theme,
+			Links:     links,
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeStaticFile(dir, staticTypePage(t.ID), page); err != nil {
+			return err
+		}
+	}
+
+	if site.EventPages {
+		for _, e := range site.Events {
+			page, err := r.RenderEvent(e, EventPageOptions{Types: site.Types, Events: site.Events, Theme: site.Theme, Links: links})
+			if err != nil {
+				return err
+			}
+			if err := writeStaticFile(dir, staticEventPage(e.ID), page); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := copyStaticAssets(filepath.Join(dir, staticAssetsDir), site.Assets); err != nil {
+		return err
+	}
+	for _, theme := range r.themes {
+		if theme.Assets == nil {
+			continue
+		}
+		if err := copyStaticAssets(filepath.Join(dir, filepath.FromSlash(themeAssetsPath(theme.Name))), theme.Assets); err != nil {
+			return err
+		}
+	}
+	return copyStaticMedia(dir, site.Events, site.OpenMedia)
+}
+
+// typeDescendants returns the IDs of a type and of all types below it.
+func typeDescendants(types []timeline.Type, id uint) map[uint]bool {
+	descendants := map[uint]bool{id: true}
+	for added := true; added; {
+		added = false
+		for _, t := range types {
+			if t.ParentID != nil && descendants[*t.ParentID] && !descendants[t.ID] {
+				descendants[t.ID] = true
+				added = true
+			}
+		}
+	}
+	return descendants
+}
+
+func writeStaticFile(dir, name string, content []byte) error {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+func copyStaticAssets(dir string, assets fs.FS) error {
+	return fs.WalkDir(assets, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+		return writeStaticFile(dir, name, content)
+	})
+}
+
+// copyStaticMedia copies the stored graphics of the events along with their variants. Graphics that are
+// not stored media, like data URLs, are part of the pages already. Missing media is skipped, the server
+// would not find it either.
+func copyStaticMedia(dir string, events []timeline.Event, open func(key string) (io.ReadCloser, error)) error {
+	copied := map[string]bool{}
+	for _, e := range events {
+		if !strings.HasPrefix(e.Graphic, timeline.MediaPathPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(e.Graphic, timeline.MediaPathPrefix)
+		keys := []string{
+			key,
+			timeline.MediaVariantKey(key, timeline.MediaVariantThumbnail),
+			timeline.MediaVariantKey(key, timeline.MediaVariantMedium),
+		}
+		for _, k := range keys {
+			if copied[k] {
+				continue
+			}
+			copied[k] = true
+			if err := copyStaticMediaFile(dir, k, open); err != nil && !errors.Is(err, timeline.ErrNotFound) {
+				return fmt.Errorf("cannot copy media %s: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+func copyStaticMediaFile(dir, key string, open func(key string) (io.ReadCloser, error)) (err error) {
+	content, err := open(key)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	path := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(timeline.MediaPath(key), "/")))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = io.Copy(f, content)
+	return err
+}
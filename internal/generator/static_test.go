@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"errors"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestSiteLinks(t *testing.T) {
+	served := SiteLinks{EventPages: true, BaseURL: "https://timeline.example.com/"}
+	require.Equal(t, "?type=3", served.TypeURL(3))
+	require.Equal(t, "?tag=moon", served.TagURL("moon"))
+	require.Equal(t, "?", served.AllURL())
+	require.Equal(t, "events/7-apollo-11", served.EventURL(7, "Apollo 11"))
+	require.Equal(t, jQueryURL, served.JQuery())
+	require.Equal(t, "/media/eagle.png", served.graphic("/media/eagle.png"))
+	require.Equal(t, "https://timeline.example.com/events/7", served.absolute("/events/7"))
+	require.Equal(t, "https://cdn.example.com/eagle.png", served.absolute("https://cdn.example.com/eagle.png"))
+
+	static := SiteLinks{Root: "../", Static: true, EventPages: true}
+	require.Equal(t, "../types/3.html", static.TypeURL(3))
+	require.Empty(t, static.TagURL("moon"))
+	require.Equal(t, "../index.html", static.AllURL())
+	require.Equal(t, "../events/7.html", static.EventURL(7, "Apollo 11"))
+	require.Equal(t, "../static/js/jquery.min.js", static.JQuery())
+	require.Equal(t, "../media/eagle.png", static.graphic("/media/eagle.png"))
+	require.Equal(t, "data:image/png;base64,AA==", static.graphic("data:image/png;base64,AA=="))
+	// there are no absolute URLs without a base URL
+	require.Empty(t, static.absolute("/events/7"))
+
+	static.EventPages = false
+	require.Empty(t, static.EventURL(7, "Apollo 11"))
+}
+
+func TestWriteStaticSite(t *testing.T) {
+	r, err := NewRenderer(RendererOptions{})
+	require.NoError(t, err)
+	parentID := uint(1)
+	types := []timeline.Type{
+		{ID: 1, Name: "Mission", EffectiveColor: "#1f77b4"},
+		{ID: 2, Name: "Landing", ParentID: &parentID, EffectiveColor: "#1f77b4"},
+	}
+	events := []timeline.Event{
+		{ID: 1, Name: "Apollo 8", EventTime: time.Date(1968, 12, 21, 0, 0, 0, 0, time.UTC), TypeID: 1},
+		{ID: 2, Name: "Apollo 11", EventTime: time.Date(1969, 7, 20, 0, 0, 0, 0, time.UTC), TypeID: 2, Graphic: "/media/eagle.png"},
+	}
+	media := map[string]string{"eagle.png": "eagle", "eagle-thumbnail.png": "thumbnail"}
+
+	dir := t.TempDir()
+	require.NoError(t, r.WriteStaticSite(dir, StaticSite{
+		Events:     events,
+		Types:      types,
+		EventPages: true,
+		Assets:     fstest.MapFS{"js/jquery.min.js": {Data: []byte("jquery")}, "css/timeline.css": {Data: []byte("css")}},
+		OpenMedia: func(key string) (io.ReadCloser, error) {
+			content, ok := media[key]
+			if !ok {
+				return nil, timeline.ErrNotFound
+			}
+			return io.NopCloser(strings.NewReader(content)), nil
+		},
+	}))
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		require.NoError(t, err, name)
+		return string(content)
+	}
+	index := read("index.html")
+	require.Contains(t, index, `src="static/js/jquery.min.js"`)
+	require.Contains(t, index, `href="types/2.html"`)
+	require.Contains(t, index, `href="events/2.html"`)
+	require.NotContains(t, index, jQueryURL)
+
+	// type pages show the events of subtypes and link back to the root
+	mission := read("types/1.html")
+	require.Contains(t, mission, "Apollo 8")
+	require.Contains(t, mission, "Apollo 11")
+	require.Contains(t, mission, `src="../static/js/jquery.min.js"`)
+	landing := read("types/2.html")
+	require.Contains(t, landing, "Apollo 11")
+	require.NotContains(t, landing, "Apollo 8")
+
+	require.Contains(t, read("events/2.html"), "Apollo 11")
+	require.Equal(t, "css", read("static/css/timeline.css"))
+	require.FileExists(t, filepath.Join(dir, "themes", "compact", "compact.css"))
+	require.Equal(t, "eagle", read("media/eagle.png"))
+	require.Equal(t, "thumbnail", read("media/eagle-thumbnail.png"))
+	require.NoFileExists(t, filepath.Join(dir, "media", "eagle-medium.png"))
+}
+
+func TestCopyStaticMedia(t *testing.T) {
+	events := []timeline.Event{
+		{ID: 1, Graphic: "/media/eagle.png"},
+		{ID: 2, Graphic: "/media/eagle.png"},
+		{ID: 3, Graphic: "data:image/png;base64,AA=="},
+		{ID: 4, Graphic: "https://cdn.example.com/crew.png"},
+	}
+
+	t.Run("copies each media once", func(t *testing.T) {
+		var opened []string
+		err := copyStaticMedia(t.TempDir(), events, func(key string) (io.ReadCloser, error) {
+			opened = append(opened, key)
+			return io.NopCloser(strings.NewReader(key)), nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"eagle.png", "eagle-thumbnail.png", "eagle-medium.png"}, opened)
+	})
+
+	t.Run("fails when media cannot be read", func(t *testing.T) {
+		err := copyStaticMedia(t.TempDir(), events, func(key string) (io.ReadCloser, error) {
+			return nil, errors.New("connection refused")
+		})
+		require.ErrorContains(t, err, "cannot copy media eagle.png")
+	})
+}
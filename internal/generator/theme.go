@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// DefaultTheme renders pages unless configured otherwise.
+const DefaultTheme = "vertical"
+
+const (
+	// siteTemplate renders the timeline page and is required in every theme.
+	siteTemplate = "site.gohtml"
+	// eventTemplate renders the permalink page of an event, themes fall back to the shared one.
+	eventTemplate = "event.gohtml"
+	// themeAssetsDir holds the assets of a theme within its directory.
+	themeAssetsDir = "assets"
+)
+
+// builtinFS holds the templates shared by all themes and the built-in themes, one per directory.
+//
+//go:embed templates themes
+var builtinFS embed.FS
+
+var themeNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Theme is a set of templates rendering the timeline and event pages, along with the assets they refer to.
+// Templates are executed with the fields of the data and eventData types.
+type Theme struct {
+	Name     string
+	template *template.Template
+	// Assets are served under themes/<name>/, nil for themes without assets of their own.
+	Assets fs.FS
+}
+
+// themeAssetsPath returns the path assets of a theme are served under, relative to the root of the site.
+func themeAssetsPath(theme string) string {
+	return "themes/" + theme + "/"
+}
+
+// loadThemes loads the built-in themes, then the themes found in dir.
+func (r *Renderer) loadThemes(dir string) error {
+	builtin, err := fs.Sub(builtinFS, "themes")
+	if err != nil {
+		return err
+	}
+	if err := r.loadThemesFrom(builtin); err != nil {
+		return err
+	}
+	if dir == "" {
+		return nil
+	}
+	return r.loadThemesFrom(os.DirFS(dir))
+}
+
+// loadThemesFrom loads every directory holding templates as a theme. Other directories, like .git, are skipped.
+func (r *Renderer) loadThemesFrom(themes fs.FS) error {
+	entries, err := fs.ReadDir(themes, ".")
+	if err != nil {
+		return fmt.Errorf("cannot read themes: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		themeFS, err := fs.Sub(themes, entry.Name())
+		if err != nil {
+			return err
+		}
+		templates, err := fs.Glob(themeFS, "*.gohtml")
+		if err != nil {
+			return err
+		}
+		if len(templates) == 0 {
+			continue
+		}
+		theme, err := loadTheme(entry.Name(), themeFS)
+		if err != nil {
+			return fmt.Errorf("theme %s: %w", entry.Name(), err)
+		}
+		r.themes[theme.Name] = theme
+	}
+	return nil
+}
+
+// loadTheme parses the templates of a theme on top of the shared ones and validates them by rendering sample pages,
+// so that broken themes are rejected at startup rather than when a page is requested.
+func loadTheme(name string, themeFS fs.FS) (*Theme, error) {
+	if !themeNamePattern.MatchString(name) {
+		return nil, errors.New("theme names must be lower case identifiers like \"dark-list\"")
+	}
+	if _, err := fs.Stat(themeFS, siteTemplate); err != nil {
+		return nil, fmt.Errorf("missing %s: %w", siteTemplate, err)
+	}
+
+	t, err := template.New(name).Funcs(templateFuncs).ParseFS(builtinFS, "templates/*.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	if t, err = t.ParseFS(themeFS, "*.gohtml"); err != nil {
+		return nil, err
+	}
+	theme := &Theme{Name: name, template: t}
+	if info, err := fs.Stat(themeFS, themeAssetsDir); err == nil && info.IsDir() {
+		if theme.Assets, err = fs.Sub(themeFS, themeAssetsDir); err != nil {
+			return nil, err
+		}
+	}
+
+	sample := sampleEvent()
+	links := SiteLinks{theme: name}
+	site := data{
+		Events: []Event{sample},
+		Groups: []Group{{Label: periodLabel(sample.EventTime.Year(), GroupByYear), Grouping: GroupByYear, Events: []Event{sample}}},
+		Legend: []LegendType{{ID: sample.TypeID, Name: sample.Marker.Title, Marker: sample.Marker}},
+		Layout: Layout{Grouping: GroupByYear, Order: OrderDescending},
+		Links:  links,
+		Theme:  name,
+		Themes: []string{name},
+	}
+	if err := t.ExecuteTemplate(io.Discard, siteTemplate, site); err != nil {
+		return nil, err
+	}
+	event := eventData{
+		Event:    sample,
+		Type:     &site.Legend[0],
+		Previous: &RelatedEvent{ID: 2, Name: "Previous event", Label: "Previous"},
+		Next:     &RelatedEvent{ID: 3, Name: "Next event", Label: "Next"},
+		Links:    links,
+		Theme:    name,
+		Meta: EventMeta{
+			Title:          sample.Name,
+			Description:    sample.ShortDescription,
+			URL:            "https://example.com/events/1-sample-event",
+			Image:          "https://example.com/media/sample-medium.png",
+			TwitterCard:    "summary_large_image",
+			StructuredData: `{"@type":"Event"}`,
+		},
+	}
+	if err := t.ExecuteTemplate(io.Discard, eventTemplate, event); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// sampleEvent fills every field that templates may refer to.
+func sampleEvent() Event {
+	return Event{
+		ID:                  1,
+		Name:                "Sample event",
+		EventTime:           time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC),
+		ShortDescription:    "Short description",
+		DetailedDescription: "Detailed description",
+		DescriptionHTML:     "<p>Detailed description</p>",
+		Graphic:             "/media/sample.png",
+		GraphicThumbnail:    "/media/sample-thumbnail.png",
+		GraphicMedium:       "/media/sample-medium.png",
+		TypeID:              1,
+		Marker:              Marker{Title: "Sample type", Color: "#7dbadf", Icon: "star", Glyph: iconGlyphs["star"]},
+		Tags:                []string{"sample"},
+		Related:             []RelatedEvent{{ID: 2, Name: "Related event", Label: "Follows"}},
+	}
+}
+
+// Themes returns the names of the available themes.
+func (r *Renderer) Themes() []string {
+	names := make([]string, 0, len(r.themes))
+	for name := range r.themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ThemeAssets returns the assets of a theme, or nil if the theme is unknown or has no assets.
+func (r *Renderer) ThemeAssets(name string) fs.FS {
+	if theme := r.themes[name]; theme != nil {
+		return theme.Assets
+	}
+	return nil
+}
+
+// pageTheme picks the requested theme, or the theme of the type or of its closest ancestor setting one, or the
+// default theme. Types may refer to themes that are no longer installed, they fall back to the default.
+func (r *Renderer) pageTheme(requested string, types []timeline.Type, typeID uint) (*Theme, error) {
+	if requested != "" {
+		theme := r.themes[requested]
+		if theme == nil {
+			return nil, fmt.Errorf("%w: unknown theme %q", timeline.ErrInvalid, requested)
+		}
+		return theme, nil
+	}
+
+	byID := map[uint]*timeline.Type{}
+	for i := range types {
+		byID[types[i].ID] = &types[i]
+	}
+	// the depth bound guards against cycles in inconsistent data
+	for t, depth := byID[typeID], 0; t != nil && depth < len(types); depth++ {
+		if theme := r.themes[t.Theme]; theme != nil {
+			return theme, nil
+		}
+		if t.ParentID == nil {
+			break
+		}
+		t = byID[*t.ParentID]
+	}
+	return r.themes[r.defaultTheme], nil
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTheme(t *testing.T, dir, name string, files map[string]string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, name, themeAssetsDir), 0o755))
+	for file, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, file), []byte(content), 0o644))
+	}
+}
+
+func TestNewRendererCustomThemes(t *testing.T) {
+	dir := t.TempDir()
+	writeTheme(t, dir, "plain", map[string]string{
+		siteTemplate:                           `{{ range .Events }}<p>{{ .Name }} {{ formatDate "2006" .EventTime }}</p>{{ end }}`,
+		filepath.Join(themeAssetsDir, "a.css"): `p {}`,
+	})
+	writeTheme(t, dir, ".git", map[string]string{"HEAD": "ref: refs/heads/main"})
+
+	r, err := NewRenderer(RendererOptions{ThemesDir: dir, DefaultTheme: "plain"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"compact", "horizontal", "plain", "vertical"}, r.Themes())
+	require.NotNil(t, r.ThemeAssets("plain"))
+	require.Nil(t, r.ThemeAssets("unknown"))
+
+	_, err = NewRenderer(RendererOptions{DefaultTheme: "plain"})
+	require.Error(t, err)
+}
+
+func TestNewRendererInvalidThemes(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing site template": {eventTemplate: `{{ .Event.Name }}`},
+		"unknown field":         {siteTemplate: `{{ .Nope }}`},
+		"unknown function":      {siteTemplate: `{{ nope }}`},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTheme(t, dir, "broken", files)
+			_, err := NewRenderer(RendererOptions{ThemesDir: dir})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPageTheme(t *testing.T) {
+	r, err := NewRenderer(RendererOptions{})
+	require.NoError(t, err)
+
+	parent := uint(1)
+	types := []timeline.Type{
+		{ID: 1, Theme: "compact"},
+		{ID: 2, ParentID: &parent},
+		{ID: 3, Theme: "removed"},
+	}
+
+	theme, err := r.pageTheme("", types, 2)
+	require.NoError(t, err)
+	require.Equal(t, "compact", theme.Name)
+
+	theme, err = r.pageTheme("", types, 3)
+	require.NoError(t, err)
+	require.Equal(t, DefaultTheme, theme.Name)
+
+	theme, err = r.pageTheme("horizontal", types, 2)
+	require.NoError(t, err)
+	require.Equal(t, "horizontal", theme.Name)
+
+	_, err = r.pageTheme("removed", types, 0)
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
+
+func TestColorFuncs(t *testing.T) {
+	require.Equal(t, "#131313", textColor("#ffff00"))
+	require.Equal(t, "#eeefef", textColor("#003"))
+	require.Equal(t, "#131313", textColor("navy"))
+	require.Equal(t, "rgba(170, 187, 204, 0.25)", alphaColor("#abc", 0.25))
+	require.Equal(t, "navy", alphaColor("navy", 0.25))
+}
@@ -0,0 +1,102 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+)
+
+var ErrUnsupported = errors.New("unsupported image format")
+
+var allowed = map[string]bool{
+	JPEG: true,
+	PNG:  true,
+	GIF:  true,
+	WebP: true,
+}
+
+// Sniff detects the content type of content from its magic bytes and checks it against the allowlist.
+func Sniff(content []byte) (string, error) {
+	contentType := http.DetectContentType(content)
+	if !allowed[contentType] {
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+	}
+	return contentType, nil
+}
+
+// Dimensions reads the image size from its header without decoding the pixels.
+func Dimensions(content []byte) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot decode image config: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// VariantType is the content type a resized variant of contentType is encoded as.
+func VariantType(contentType string) string {
+	if contentType == JPEG {
+		return JPEG
+	}
+	return PNG
+}
+
+// Resize scales the image down to fit into maxWidth x maxHeight, keeping the
+// aspect ratio. Images that already fit are re-encoded without scaling.
+// Animated GIFs are reduced to their first frame.
+func Resize(content []byte, maxWidth, maxHeight int) ([]byte, error) {
+	src, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	if format == "jpeg" {
+		src = orient(src, jpegOrientation(content))
+	}
+
+	b := src.Bounds()
+	w, h := fit(b.Dx(), b.Dy(), maxWidth, maxHeight)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func fit(w, h, maxWidth, maxHeight int) (int, int) {
+	if w <= maxWidth && h <= maxHeight {
+		return w, h
+	}
+	if w*maxHeight > h*maxWidth {
+		return maxWidth, maxInt(1, h*maxWidth/w)
+	}
+	return maxInt(1, w*maxHeight/h), maxHeight
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,123 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 EXIF segment holding only the orientation tag right after SOI.
+func withEXIF(content []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.BigEndian.PutUint16(tiff[18:], orientation)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, content[:2]...)
+	out = append(out, segment...)
+	return append(out, content[2:]...)
+}
+
+func TestSniff(t *testing.T) {
+	contentType, err := Sniff(encodeJPEG(t, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	require.NoError(t, err)
+	require.Equal(t, JPEG, contentType)
+
+	_, err = Sniff([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"><script/></svg>"))
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestStripJPEG(t *testing.T) {
+	original := encodeJPEG(t, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	tagged := withEXIF(original, 1)
+	require.Equal(t, 1, jpegOrientation(tagged))
+	require.True(t, bytes.Contains(tagged, []byte("Exif")))
+
+	stripped, err := StripMetadata(JPEG, tagged)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(stripped, []byte("Exif")))
+	require.Equal(t, original, stripped)
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	original := buf.Bytes()
+
+	// insert a tEXt chunk after IHDR (signature 8 bytes + IHDR 25 bytes)
+	text := []byte("Author\x00someone")
+	chunk := make([]byte, 8, 12+len(text))
+	binary.BigEndian.PutUint32(chunk, uint32(len(text)))
+	copy(chunk[4:], "tEXt")
+	chunk = append(chunk, text...)
+	chunk = append(chunk, 0, 0, 0, 0)
+	tagged := append(append(append([]byte{}, original[:33]...), chunk...), original[33:]...)
+
+	stripped, err := StripMetadata(PNG, tagged)
+	require.NoError(t, err)
+	require.Equal(t, original, stripped)
+}
+
+func TestStripWebP(t *testing.T) {
+	vp8x := append([]byte("VP8X\x0a\x00\x00\x00"), webpFlagEXIF, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	exif := []byte("EXIF\x03\x00\x00\x00abc\x00")
+	body := append(append([]byte("WEBP"), vp8x...), exif...)
+	content := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+	binary.LittleEndian.PutUint32(content[4:], uint32(len(body)))
+
+	stripped, err := StripMetadata(WebP, content)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(stripped, []byte("EXIF")))
+	require.Equal(t, byte(0), stripped[20])
+	require.Equal(t, uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:]))
+}
+
+func TestSanitizeAppliesOrientation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	// orientation 6 means the camera was rotated, the upright image is 20x40
+	sanitized, err := Sanitize(JPEG, withEXIF(encodeJPEG(t, img), 6))
+	require.NoError(t, err)
+	require.Equal(t, 1, jpegOrientation(sanitized))
+
+	w, h, err := Dimensions(sanitized)
+	require.NoError(t, err)
+	require.Equal(t, 20, w)
+	require.Equal(t, 40, h)
+}
+
+func TestResize(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1600, 900))))
+
+	resized, err := Resize(buf.Bytes(), 240, 180)
+	require.NoError(t, err)
+	w, h, err := Dimensions(resized)
+	require.NoError(t, err)
+	require.Equal(t, 240, w)
+	require.Equal(t, 135, h)
+
+	small, err := Resize(resized, 800, 600)
+	require.NoError(t, err)
+	w, h, err = Dimensions(small)
+	require.NoError(t, err)
+	require.Equal(t, 240, w)
+	require.Equal(t, 135, h)
+}
@@ -0,0 +1,233 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+)
+
+// Sanitize strips metadata from the image. JPEGs whose EXIF orientation
+// would be lost by stripping are re-encoded upright instead.
+func Sanitize(contentType string, content []byte) ([]byte, error) {
+	if contentType == JPEG && jpegOrientation(content) != 1 {
+		w, h, err := Dimensions(content)
+		if err != nil {
+			return nil, err
+		}
+		// orientations 5-8 swap the axes
+		return Resize(content, w+h, w+h)
+	}
+	return StripMetadata(contentType, content)
+}
+
+// StripMetadata removes EXIF, XMP and textual metadata from the encoded image
+// without re-encoding the pixel data. GIF has no EXIF and is returned as is.
+func StripMetadata(contentType string, content []byte) ([]byte, error) {
+	switch contentType {
+	case JPEG:
+		return stripJPEG(content)
+	case PNG:
+		return stripPNG(content)
+	case WebP:
+		return stripWebP(content)
+	case GIF:
+		return content, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+	}
+}
+
+const (
+	jpegAPP1 = 0xE1 // EXIF, XMP
+	jpegAPPD = 0xED // IPTC
+	jpegCOM  = 0xFE
+	jpegSOS  = 0xDA
+)
+
+func stripJPEG(content []byte) ([]byte, error) {
+	if len(content) < 2 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, fmt.Errorf("invalid jpeg")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.Write(content[:2])
+	for i := 2; ; {
+		for i < len(content) && content[i] == 0xFF && i+1 < len(content) && content[i+1] == 0xFF {
+			i++ // fill bytes
+		}
+		if i+4 > len(content) || content[i] != 0xFF {
+			return nil, fmt.Errorf("invalid jpeg segment at %d", i)
+		}
+		marker := content[i+1]
+		if marker == jpegSOS {
+			// entropy coded data follows, no metadata beyond this point
+			out.Write(content[i:])
+			return out.Bytes(), nil
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:]))
+		if end > len(content) {
+			return nil, fmt.Errorf("invalid jpeg segment length at %d", i)
+		}
+		if marker != jpegAPP1 && marker != jpegAPPD && marker != jpegCOM {
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+}
+
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(content []byte) ([]byte, error) {
+	const sigLen = 8
+	if len(content) < sigLen {
+		return nil, fmt.Errorf("invalid png")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.Write(content[:sigLen])
+	for i := sigLen; i < len(content); {
+		if i+8 > len(content) {
+			return nil, fmt.Errorf("invalid png chunk at %d", i)
+		}
+		// length, type, data, crc
+		end := i + 12 + int(binary.BigEndian.Uint32(content[i:]))
+		if end > len(content) || end < i {
+			return nil, fmt.Errorf("invalid png chunk length at %d", i)
+		}
+		if !pngMetadataChunks[string(content[i+4:i+8])] {
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func stripWebP(content []byte) ([]byte, error) {
+	const headerLen = 12
+	if len(content) < headerLen || string(content[:4]) != "RIFF" || string(content[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("invalid webp")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(content)))
+	out.Write(content[:headerLen])
+	for i := headerLen; i < len(content); {
+		if i+8 > len(content) {
+			return nil, fmt.Errorf("invalid webp chunk at %d", i)
+		}
+		size := int(binary.LittleEndian.Uint32(content[i+4:]))
+		end := i + 8 + size + size%2 // chunks are padded to even size
+		if end > len(content) || end < i {
+			return nil, fmt.Errorf("invalid webp chunk length at %d", i)
+		}
+		switch string(content[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), content[i:end]...)
+			chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			out.Write(chunk)
+		default:
+			out.Write(content[i:end])
+		}
+		i = end
+	}
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, nil
+}
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, 1 if unknown.
+func jpegOrientation(content []byte) int {
+	for i := 2; i+4 <= len(content) && content[i] == 0xFF; {
+		marker := content[i+1]
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:]))
+		if marker == jpegSOS || end > len(content) {
+			break
+		}
+		if marker == jpegAPP1 && bytes.HasPrefix(content[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(content[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	const orientationTag = 0x0112
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[off:]) == orientationTag {
+			if o := int(order.Uint16(tiff[off+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient applies an EXIF orientation so that the returned image is upright.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	transposed := orientation >= 5
+	dw, dh := w, h
+	if transposed {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -35,13 +35,13 @@ func (_m *EventRepository) CreateEvent(ctx context.Context, event *timeline.Even
 	return r0, r1
 }
 
-// DeleteEvent provides a mock function with given fields: ctx, id
-func (_m *EventRepository) DeleteEvent(ctx context.Context, id uint) error {
-	ret := _m.Called(ctx, id)
+// DeleteEvent provides a mock function with given fields: ctx, id, revision
+func (_m *EventRepository) DeleteEvent(ctx context.Context, id uint, revision uint) error {
+	ret := _m.Called(ctx, id, revision)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, id, revision)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -70,13 +70,66 @@ func (_m *EventRepository) GetEvent(ctx context.Context, id uint) (timeline.Even
 	return r0, r1
 }
 
-// ListEvents provides a mock function with given fields: ctx
-func (_m *EventRepository) ListEvents(ctx context.Context) ([]timeline.Event, error) {
-	ret := _m.Called(ctx)
+// GetEventByExternalID provides a mock function with given fields: ctx, externalID
+func (_m *EventRepository) GetEventByExternalID(ctx context.Context, externalID string) (timeline.Event, error) {
+	ret := _m.Called(ctx, externalID)
+
+	var r0 timeline.Event
+	if rf, ok := ret.Get(0).(func(context.Context, string) timeline.Event); ok {
+		r0 = rf(ctx, externalID)
+	} else {
+		r0 = ret.Get(0).(timeline.Event)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, externalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNeighbourEvents provides a mock function with given fields: ctx, event
+func (_m *EventRepository) GetNeighbourEvents(ctx context.Context, event timeline.Event) (*timeline.Event, *timeline.Event, error) {
+	ret := _m.Called(ctx, event)
+
+	var r0 *timeline.Event
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.Event) *timeline.Event); ok {
+		r0 = rf(ctx, event)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*timeline.Event)
+		}
+	}
+
+	var r1 *timeline.Event
+	if rf, ok := ret.Get(1).(func(context.Context, timeline.Event) *timeline.Event); ok {
+		r1 = rf(ctx, event)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*timeline.Event)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, timeline.Event) error); ok {
+		r2 = rf(ctx, event)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListEvents provides a mock function with given fields: ctx, filter
+func (_m *EventRepository) ListEvents(ctx context.Context, filter timeline.EventFilter) ([]timeline.Event, error) {
+	ret := _m.Called(ctx, filter)
 
 	var r0 []timeline.Event
-	if rf, ok := ret.Get(0).(func(context.Context) []timeline.Event); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.EventFilter) []timeline.Event); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]timeline.Event)
@@ -84,8 +137,8 @@ func (_m *EventRepository) ListEvents(ctx context.Context) ([]timeline.Event, er
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, timeline.EventFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -93,6 +146,43 @@ func (_m *EventRepository) ListEvents(ctx context.Context) ([]timeline.Event, er
 	return r0, r1
 }
 
+// ListRecentEvents provides a mock function with given fields: ctx, filter, limit
+func (_m *EventRepository) ListRecentEvents(ctx context.Context, filter timeline.EventFilter, limit int) ([]timeline.Event, error) {
+	ret := _m.Called(ctx, filter, limit)
+
+	var r0 []timeline.Event
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.EventFilter, int) []timeline.Event); ok {
+		r0 = rf(ctx, filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]timeline.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, timeline.EventFilter, int) error); ok {
+		r1 = rf(ctx, filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamEvents provides a mock function with given fields: ctx, filter, fn
+func (_m *EventRepository) StreamEvents(ctx context.Context, filter timeline.EventFilter, fn func(timeline.Event) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.EventFilter, func(timeline.Event) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateEvent provides a mock function with given fields: ctx, id, event
 func (_m *EventRepository) UpdateEvent(ctx context.Context, id uint, event *timeline.Event) error {
 	ret := _m.Called(ctx, id, event)
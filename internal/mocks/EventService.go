@@ -35,13 +35,13 @@ func (_m *EventService) CreateEvent(ctx context.Context, event *timeline.Event)
 	return r0, r1
 }
 
-// DeleteEvent provides a mock function with given fields: ctx, id
-func (_m *EventService) DeleteEvent(ctx context.Context, id uint) error {
-	ret := _m.Called(ctx, id)
+// DeleteEvent provides a mock function with given fields: ctx, id, revision
+func (_m *EventService) DeleteEvent(ctx context.Context, id uint, revision uint) error {
+	ret := _m.Called(ctx, id, revision)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, id, revision)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -70,13 +70,68 @@ func (_m *EventService) GetEvent(ctx context.Context, id uint) (timeline.Event,
 	return r0, r1
 }
 
-// ListEvents provides a mock function with given fields: ctx
-func (_m *EventService) ListEvents(ctx context.Context) ([]timeline.Event, error) {
-	ret := _m.Called(ctx)
+// GetNeighbourEvents provides a mock function with given fields: ctx, event
+func (_m *EventService) GetNeighbourEvents(ctx context.Context, event timeline.Event) (*timeline.Event, *timeline.Event, error) {
+	ret := _m.Called(ctx, event)
+
+	var r0 *timeline.Event
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.Event) *timeline.Event); ok {
+		r0 = rf(ctx, event)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*timeline.Event)
+		}
+	}
+
+	var r1 *timeline.Event
+	if rf, ok := ret.Get(1).(func(context.Context, timeline.Event) *timeline.Event); ok {
+		r1 = rf(ctx, event)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*timeline.Event)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, timeline.Event) error); ok {
+		r2 = rf(ctx, event)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListEvents provides a mock function with given fields: ctx, filter
+func (_m *EventService) ListEvents(ctx context.Context, filter timeline.EventFilter) ([]timeline.Event, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []timeline.Event
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.EventFilter) []timeline.Event); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]timeline.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, timeline.EventFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRecentEvents provides a mock function with given fields: ctx, filter, limit
+func (_m *EventService) ListRecentEvents(ctx context.Context, filter timeline.EventFilter, limit int) ([]timeline.Event, error) {
+	ret := _m.Called(ctx, filter, limit)
 
 	var r0 []timeline.Event
-	if rf, ok := ret.Get(0).(func(context.Context) []timeline.Event); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, timeline.EventFilter, int) []timeline.Event); ok {
+		r0 = rf(ctx, filter, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]timeline.Event)
@@ -84,8 +139,31 @@ func (_m *EventService) ListEvents(ctx context.Context) ([]timeline.Event, error
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, timeline.EventFilter, int) error); ok {
+		r1 = rf(ctx, filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchEvents provides a mock function with given fields: ctx, query, limit
+func (_m *EventService) SearchEvents(ctx context.Context, query string, limit int) ([]timeline.SearchResult, error) {
+	ret := _m.Called(ctx, query, limit)
+
+	var r0 []timeline.SearchResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []timeline.SearchResult); ok {
+		r0 = rf(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]timeline.SearchResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, query, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +35,13 @@ func (_m *TypeRepository) CreateType(ctx context.Context, t *timeline.Type) (uin
 	return r0, r1
 }
 
-// DeleteType provides a mock function with given fields: ctx, id
-func (_m *TypeRepository) DeleteType(ctx context.Context, id uint) error {
-	ret := _m.Called(ctx, id)
+// DeleteType provides a mock function with given fields: ctx, id, policy, revision
+func (_m *TypeRepository) DeleteType(ctx context.Context, id uint, policy timeline.TypeDeletePolicy, revision uint) error {
+	ret := _m.Called(ctx, id, policy, revision)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, timeline.TypeDeletePolicy, uint) error); ok {
+		r0 = rf(ctx, id, policy, revision)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,20 +49,20 @@ func (_m *TypeRepository) DeleteType(ctx context.Context, id uint) error {
 	return r0
 }
 
-// GetType provides a mock function with given fields: ctx, id
-func (_m *TypeRepository) GetType(ctx context.Context, id uint) (timeline.Type, error) {
-	ret := _m.Called(ctx, id)
+// GetType provides a mock function with given fields: ctx, id, opts
+func (_m *TypeRepository) GetType(ctx context.Context, id uint, opts timeline.TypeOptions) (timeline.Type, error) {
+	ret := _m.Called(ctx, id, opts)
 
 	var r0 timeline.Type
-	if rf, ok := ret.Get(0).(func(context.Context, uint) timeline.Type); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, timeline.TypeOptions) timeline.Type); ok {
+		r0 = rf(ctx, id, opts)
 	} else {
 		r0 = ret.Get(0).(timeline.Type)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, timeline.TypeOptions) error); ok {
+		r1 = rf(ctx, id, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
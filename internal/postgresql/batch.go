@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type Transactor struct {
+	log *zap.Logger
+
+	db             *gorm.DB
+	searchLanguage string
+}
+
+func NewTransactor(log *zap.Logger, db *gorm.DB, searchLanguage string) *Transactor {
+	return &Transactor{log: log, db: db, searchLanguage: searchLanguage}
+}
+
+// InTransaction opens a transaction, or a savepoint when the transactor is already bound to one.
+func (t Transactor) InTransaction(ctx context.Context, fn func(repos timeline2.Repositories) error) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(timeline2.Repositories{
+			Events:    NewEventRepository(t.log, tx, t.searchLanguage),
+			Types:     NewTypeRepository(t.log, tx),
+			Relations: NewRelationRepository(t.log, tx),
+			Tx:        NewTransactor(t.log, tx, t.searchLanguage),
+		})
+	})
+}
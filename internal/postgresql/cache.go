@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// pageCacheVersionID is the ID of the row holding the generation.
+const pageCacheVersionID = 1
+
+// PageCache keeps pages in the database, so that all instances of the server share them and their invalidation.
+// Beyond maxEntries pages or maxBytes of page bodies the pages closest to expiring are dropped.
+type PageCache struct {
+	log *zap.Logger
+
+	db         *gorm.DB
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+}
+
+func NewPageCache(log *zap.Logger, db *gorm.DB, maxEntries int, maxBytes int64, ttl time.Duration) *PageCache {
+	return &PageCache{log: log, db: db, ttl: ttl, maxEntries: maxEntries, maxBytes: maxBytes}
+}
+
+func (pc PageCache) Version(ctx context.Context) (timeline2.PageCacheVersion, error) {
+	// the row is created by the first instance asking, the timeline may have changed before
+	v := pageCacheVersion{ID: pageCacheVersionID}
+	err := pc.db.WithContext(ctx).
+		Attrs(pageCacheVersion{Generation: 1, ModifiedAt: time.Now()}).
+		FirstOrCreate(&v, pageCacheVersion{ID: pageCacheVersionID}).Error
+	if err != nil {
+		return timeline2.PageCacheVersion{}, fmt.Errorf("cannot read page cache version: %w", err)
+	}
+	return timeline2.PageCacheVersion{Generation: v.Generation, Modified: v.ModifiedAt}, nil
+}
+
+func (pc PageCache) Get(ctx context.Context, key string) (timeline2.CachedPage, bool, error) {
+	var e pageCacheEntry
+	if err := pc.db.WithContext(ctx).Where("key = ? AND expires_at > ?", key, time.Now()).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return timeline2.CachedPage{}, false, nil
+		}
+		return timeline2.CachedPage{}, false, fmt.Errorf("db error on select query: %w", err)
+	}
+	page := timeline2.CachedPage{Body: e.Body}
+	if err := json.Unmarshal([]byte(e.Header), &page.Header); err != nil {
+		return timeline2.CachedPage{}, false, fmt.Errorf("cannot decode cached page header: %w", err)
+	}
+	return page, true, nil
+}
+
+// Set stores the page and drops the expired ones, then the oldest ones beyond the limits.
+func (pc PageCache) Set(ctx context.Context, key string, page timeline2.CachedPage) error {
+	if pc.maxEntries <= 0 || int64(len(page.Body)) > pc.maxBytes {
+		return nil
+	}
+	header, err := json.Marshal(page.Header)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e := pageCacheEntry{Key: key, Body: page.Body, Header: string(header), ExpiresAt: now.Add(pc.ttl)}
+	return pc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", now).Delete(&pageCacheEntry{}).Error; err != nil {
+			return fmt.Errorf("cannot delete expired pages: %w", err)
+		}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&e).Error; err != nil {
+			return fmt.Errorf("cannot cache page: %w", err)
+		}
+		// pages share the TTL, so the ones expiring first are the ones cached first
+		err := tx.Exec(`DELETE FROM page_cache_entries WHERE key IN (
+				SELECT key FROM (
+					SELECT key, ROW_NUMBER() OVER newest AS position, SUM(octet_length(body)) OVER newest AS size
+					FROM page_cache_entries WINDOW newest AS (ORDER BY expires_at DESC, key)
+				) ranked WHERE position > ? OR size > ?
+			)`, pc.maxEntries, pc.maxBytes).Error
+		if err != nil {
+			return fmt.Errorf("cannot delete pages beyond the cache limits: %w", err)
+		}
+		return nil
+	})
+}
+
+// Invalidate starts a new generation and drops the pages of the previous ones.
+func (pc PageCache) Invalidate(ctx context.Context) error {
+	return pc.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO page_cache_versions (id, generation, modified_at) VALUES (?, 2, ?)
+			ON CONFLICT (id) DO UPDATE SET generation = page_cache_versions.generation + 1, modified_at = EXCLUDED.modified_at`,
+			pageCacheVersionID, time.Now()).Error
+		if err != nil {
+			return fmt.Errorf("cannot invalidate page cache: %w", err)
+		}
+		if err := tx.Where("1 = 1").Delete(&pageCacheEntry{}).Error; err != nil {
+			return fmt.Errorf("cannot delete cached pages: %w", err)
+		}
+		return nil
+	})
+}
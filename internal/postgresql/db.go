@@ -3,8 +3,11 @@ package postgresql
 import (
 	"fmt"
 	"github.com/kamkali/go-timeline/internal/config"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"regexp"
 )
 
 func NewDB(c *config.Config) (*gorm.DB, error) {
@@ -30,9 +33,77 @@ func NewDB(c *config.Config) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&eventType{},
 		&event{},
+		&tag{},
+		&eventRelation{},
+		&media{},
 		&user{},
+		&pageCacheEntry{},
+		&pageCacheVersion{},
 	)
+	if err != nil {
+		return err
+	}
+	// the external ID index covered deleted events before it was replaced by idx_events_live_external_id
+	if db.Migrator().HasIndex(&event{}, "idx_events_external_id") {
+		if err := db.Migrator().DropIndex(&event{}, "idx_events_external_id"); err != nil {
+			return fmt.Errorf("cannot drop index idx_events_external_id: %w", err)
+		}
+	}
+	return nil
+}
+
+var searchLanguagePattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// MigrateSearch adds the generated full-text search column and its GIN index
+// to the events table. The column is recreated when the text search
+// configuration (language) changes.
+func MigrateSearch(db *gorm.DB, language string) error {
+	if !searchLanguagePattern.MatchString(language) {
+		return fmt.Errorf("invalid search language %q", language)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var current string
+		err := tx.Raw(`SELECT coalesce(col_description('events'::regclass, attnum), '')
+			FROM pg_attribute WHERE attrelid = 'events'::regclass AND attname = 'search_vector'`).
+			Scan(&current).Error
+		if err != nil {
+			return fmt.Errorf("cannot inspect search column: %w", err)
+		}
+		if current == language {
+			return nil
+		}
+
+		statements := []string{
+			`ALTER TABLE events DROP COLUMN IF EXISTS search_vector`,
+			fmt.Sprintf(`ALTER TABLE events ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('%[1]s', coalesce(name, '')), 'A') ||
+				setweight(to_tsvector('%[1]s', coalesce(short_description, '')), 'B') ||
+				setweight(to_tsvector('%[1]s', coalesce(detailed_description, '')), 'C')
+			) STORED`, language),
+			fmt.Sprintf(`COMMENT ON COLUMN events.search_vector IS '%s'`, language),
+			`CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector)`,
+		}
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("cannot migrate search column: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// lockForUpdate makes the selected rows wait for concurrent writers and block them until the transaction ends.
+func lockForUpdate(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// checkRevision rejects writes based on a stale revision. Revision 0 skips the check.
+func checkRevision(current, expected uint) error {
+	if expected != 0 && expected != current {
+		return fmt.Errorf("%w: revision %d is stale, the current revision is %d", timeline2.ErrPreconditionFailed, expected, current)
+	}
+	return nil
 }
@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+	"sync"
+	"testing"
+)
+
+func TestCheckRevision(t *testing.T) {
+	require.NoError(t, checkRevision(3, 3))
+	// revision 0 is sent with If-Match: *
+	require.NoError(t, checkRevision(3, 0))
+
+	err := checkRevision(4, 3)
+	require.ErrorIs(t, err, timeline2.ErrPreconditionFailed)
+	require.Contains(t, err.Error(), "revision 3 is stale, the current revision is 4")
+}
+
+func TestEventExternalIDIndex(t *testing.T) {
+	s, err := schema.Parse(&event{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+	idx := s.LookIndex("idx_events_live_external_id")
+	require.NotNil(t, idx)
+	require.Equal(t, "UNIQUE", idx.Class)
+	// deleted events keep their external IDs, importing them again creates new events
+	require.Equal(t, "deleted_at IS NULL", idx.Where)
+	require.Len(t, idx.Fields, 1)
+	require.Equal(t, "external_id", idx.Fields[0].DBName)
+}
@@ -12,26 +12,33 @@ import (
 type EventRepository struct {
 	log *zap.Logger
 
-	db *gorm.DB
+	db             *gorm.DB
+	searchLanguage string
 }
 
-func NewEventRepository(log *zap.Logger, db *gorm.DB) *EventRepository {
-	return &EventRepository{log: log, db: db}
+func NewEventRepository(log *zap.Logger, db *gorm.DB, searchLanguage string) *EventRepository {
+	return &EventRepository{log: log, db: db, searchLanguage: searchLanguage}
 }
 
 func toDBEvent(de *timeline2.Event) (*event, error) {
-	return &event{
+	e := &event{
 		Name:                de.Name,
 		EventTime:           de.EventTime,
 		ShortDescription:    de.ShortDescription,
 		DetailedDescription: de.DetailedDescription,
+		DescriptionFormat:   string(de.DescriptionFormat),
 		Graphic:             de.Graphic,
-	}, nil
+	}
+	if de.ExternalID != "" {
+		externalID := de.ExternalID
+		e.ExternalID = &externalID
+	}
+	return e, nil
 }
 
 func (t EventRepository) GetEvent(ctx context.Context, id uint) (timeline2.Event, error) {
 	var event event
-	if err := t.db.WithContext(ctx).First(&event, id).Error; err != nil {
+	if err := t.db.WithContext(ctx).Preload("Tags").First(&event, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return timeline2.Event{}, timeline2.ErrNotFound
 		}
@@ -44,32 +51,99 @@ func (t EventRepository) GetEvent(ctx context.Context, id uint) (timeline2.Event
 	return domainEvent, nil
 }
 
-func (t EventRepository) UpdateEvent(ctx context.Context, id uint, domainEvent *timeline2.Event) error {
-	var e event
-	r := t.db.WithContext(ctx).Find(&e, id)
-	if r.Error != nil {
-		return fmt.Errorf("db error on select query: %w", r.Error)
+func (t EventRepository) GetEventByExternalID(ctx context.Context, externalID string) (timeline2.Event, error) {
+	var event event
+	if err := t.db.WithContext(ctx).Preload("Tags").Where("external_id = ?", externalID).First(&event).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return timeline2.Event{}, timeline2.ErrNotFound
+		}
+		return timeline2.Event{}, fmt.Errorf("db error on select query: %w", err)
+	}
+	domainEvent, err := toDomainEvent(event)
+	if err != nil {
+		return timeline2.Event{}, fmt.Errorf("cannot translate db model to domain")
 	}
+	return domainEvent, nil
+}
 
-	e.Name = domainEvent.Name
-	e.EventTime = domainEvent.EventTime
-	e.ShortDescription = domainEvent.ShortDescription
-	e.DetailedDescription = domainEvent.DetailedDescription
-	e.Graphic = domainEvent.Graphic
-	e.TypeID = domainEvent.TypeID
+func (t EventRepository) GetNeighbourEvents(ctx context.Context, e timeline2.Event) (previous, next *timeline2.Event, err error) {
+	if previous, err = t.neighbourEvent(ctx, "(events.event_time, events.id) < (?, ?)", "events.event_time DESC, events.id DESC", e); err != nil {
+		return nil, nil, err
+	}
+	if next, err = t.neighbourEvent(ctx, "(events.event_time, events.id) > (?, ?)", "events.event_time, events.id", e); err != nil {
+		return nil, nil, err
+	}
+	return previous, next, nil
+}
 
-	if err := t.db.WithContext(ctx).Save(&e).Error; err != nil {
-		return fmt.Errorf("db error on update query: %w", r.Error)
+// neighbourEvent loads the first event past e in the given order, or nil if there is none.
+func (t EventRepository) neighbourEvent(ctx context.Context, where, order string, e timeline2.Event) (*timeline2.Event, error) {
+	var events []event
+	r := t.db.WithContext(ctx).Select("id", "name", "event_time").
+		Where(where, e.EventTime, e.ID).Order(order).Limit(1).Find(&events)
+	if r.Error != nil {
+		return nil, fmt.Errorf("db error on select query: %w", r.Error)
+	}
+	if len(events) == 0 {
+		return nil, nil
 	}
+	return &timeline2.Event{ID: events[0].ID, Name: events[0].Name, EventTime: events[0].EventTime}, nil
+}
+
+func (t EventRepository) UpdateEvent(ctx context.Context, id uint, domainEvent *timeline2.Event) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var e event
+		if err := lockForUpdate(tx).First(&e, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return timeline2.ErrNotFound
+			}
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		if err := checkRevision(e.Revision, domainEvent.Revision); err != nil {
+			return err
+		}
+
+		e.Name = domainEvent.Name
+		e.EventTime = domainEvent.EventTime
+		e.ShortDescription = domainEvent.ShortDescription
+		e.DetailedDescription = domainEvent.DetailedDescription
+		e.DescriptionFormat = string(domainEvent.DescriptionFormat)
+		e.Graphic = domainEvent.Graphic
+		e.TypeID = domainEvent.TypeID
+		e.Revision++
 
-	return nil
+		if err := tx.Save(&e).Error; err != nil {
+			return fmt.Errorf("db error on update query: %w", err)
+		}
+		tags, err := resolveTags(tx, domainEvent.Tags)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&e).Association("Tags").Replace(tags); err != nil {
+			return fmt.Errorf("cannot update event tags: %w", err)
+		}
+		domainEvent.Revision = e.Revision
+		return nil
+	})
 }
 
-func (t EventRepository) DeleteEvent(ctx context.Context, id uint) error {
-	if err := t.db.WithContext(ctx).Delete(&event{}, id).Error; err != nil {
-		return fmt.Errorf("error while deleting: %w", err)
-	}
-	return nil
+func (t EventRepository) DeleteEvent(ctx context.Context, id uint, revision uint) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var e event
+		if err := lockForUpdate(tx).First(&e, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return timeline2.ErrNotFound
+			}
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		if err := checkRevision(e.Revision, revision); err != nil {
+			return err
+		}
+		if err := tx.Delete(&e).Error; err != nil {
+			return fmt.Errorf("error while deleting: %w", err)
+		}
+		return nil
+	})
 }
 
 func (t EventRepository) CreateEvent(ctx context.Context, event *timeline2.Event) (uint, error) {
@@ -85,16 +159,26 @@ func (t EventRepository) CreateEvent(ctx context.Context, event *timeline2.Event
 	}
 	dbEvent.TypeID = typ.ID
 
-	result = t.db.WithContext(ctx).Create(dbEvent)
-	if result.Error != nil {
-		return 0, fmt.Errorf("cannot create event: %w", result.Error)
+	err = t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tags, err := resolveTags(tx, event.Tags)
+		if err != nil {
+			return err
+		}
+		dbEvent.Tags = tags
+		if err := tx.Omit("Tags.*").Create(dbEvent).Error; err != nil {
+			return fmt.Errorf("cannot create event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 	return dbEvent.ID, nil
 }
 
-func (t EventRepository) ListEvents(ctx context.Context) ([]timeline2.Event, error) {
+func (t EventRepository) ListEvents(ctx context.Context, filter timeline2.EventFilter) ([]timeline2.Event, error) {
 	var events []event
-	r := t.db.WithContext(ctx).Find(&events)
+	r := t.filterEvents(t.db.WithContext(ctx), filter).Preload("Tags").Find(&events)
 	if r.Error != nil {
 		return nil, fmt.Errorf("db error on select query: %w", r.Error)
 	}
@@ -111,15 +195,151 @@ func (t EventRepository) ListEvents(ctx context.Context) ([]timeline2.Event, err
 	return domainEvents, nil
 }
 
+func (t EventRepository) ListRecentEvents(ctx context.Context, filter timeline2.EventFilter, limit int) ([]timeline2.Event, error) {
+	var events []event
+	r := t.filterEvents(t.db.WithContext(ctx), filter).Preload("Tags").
+		Order("events.updated_at DESC, events.id DESC").Limit(limit).Find(&events)
+	if r.Error != nil {
+		return nil, fmt.Errorf("db error on select query: %w", r.Error)
+	}
+
+	domainEvents := []timeline2.Event{}
+	for _, e := range events {
+		domainEvent, err := toDomainEvent(e)
+		if err != nil {
+			return nil, fmt.Errorf("cannot translate db model to domain")
+		}
+		domainEvents = append(domainEvents, domainEvent)
+	}
+	return domainEvents, nil
+}
+
+// streamBatchSize is the number of events StreamEvents loads at once.
+const streamBatchSize = 500
+
+func (t EventRepository) StreamEvents(ctx context.Context, filter timeline2.EventFilter, fn func(timeline2.Event) error) error {
+	var last *event
+	for {
+		var events []event
+		q := t.filterEvents(t.db.WithContext(ctx), filter).Preload("Tags").Order("events.event_time, events.id").Limit(streamBatchSize)
+		if last != nil {
+			// keyset pagination stays consistent and fast however far the export got
+			q = q.Where("(events.event_time, events.id) > (?, ?)", last.EventTime, last.ID)
+		}
+		if err := q.Find(&events).Error; err != nil {
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		for _, e := range events {
+			domainEvent, err := toDomainEvent(e)
+			if err != nil {
+				return fmt.Errorf("cannot translate db model to domain")
+			}
+			if err := fn(domainEvent); err != nil {
+				return err
+			}
+		}
+		if len(events) < streamBatchSize {
+			return nil
+		}
+		last = &events[len(events)-1]
+	}
+}
+
+func (t EventRepository) filterEvents(q *gorm.DB, filter timeline2.EventFilter) *gorm.DB {
+	if len(filter.Tags) > 0 {
+		tagged := q.Session(&gorm.Session{NewDB: true}).
+			Table("event_tags").
+			Select("event_tags.event_id").
+			Joins("JOIN tags ON tags.id = event_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags).
+			Group("event_tags.event_id")
+		if filter.TagMode == timeline2.TagModeAll {
+			tagged = tagged.Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+		q = q.Where("events.id IN (?)", tagged)
+	}
+	if len(filter.TypeIDs) > 0 {
+		q = q.Where(`events.type_id IN (
+			WITH RECURSIVE descendants AS (
+				SELECT id FROM event_types WHERE id IN ? AND deleted_at IS NULL
+				UNION
+				SELECT t.id FROM event_types t JOIN descendants d ON t.parent_id = d.id WHERE t.deleted_at IS NULL
+			)
+			SELECT id FROM descendants
+		)`, filter.TypeIDs)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("events.event_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("events.event_time <= ?", filter.To)
+	}
+	if filter.Query != "" {
+		q = q.Where("events.search_vector @@ websearch_to_tsquery(?::regconfig, ?)", t.searchLanguage, filter.Query)
+	}
+	return q
+}
+
 func toDomainEvent(e event) (timeline2.Event, error) {
+	var tags []string
+	for _, t := range e.Tags {
+		tags = append(tags, t.Name)
+	}
 	domainEvent := timeline2.Event{
 		ID:                  e.ID,
 		Name:                e.Name,
 		EventTime:           e.EventTime,
 		ShortDescription:    e.ShortDescription,
 		DetailedDescription: e.DetailedDescription,
+		DescriptionFormat:   timeline2.DescriptionFormat(e.DescriptionFormat),
 		Graphic:             e.Graphic,
 		TypeID:              e.TypeID,
+		Tags:                tags,
+		Revision:            e.Revision,
+		CreatedAt:           e.CreatedAt,
+		UpdatedAt:           e.UpdatedAt,
+	}
+	if e.ExternalID != nil {
+		domainEvent.ExternalID = *e.ExternalID
 	}
 	return domainEvent, nil
 }
+
+type searchRow struct {
+	Event   event `gorm:"embedded"`
+	Rank    float64
+	Snippet string
+}
+
+func (t EventRepository) SearchEvents(ctx context.Context, query string, limit int) ([]timeline2.SearchResult, error) {
+	var rows []searchRow
+	headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2",
+		timeline2.HighlightStart, timeline2.HighlightStop)
+	r := t.db.WithContext(ctx).
+		Table("events, websearch_to_tsquery(?::regconfig, ?) query", t.searchLanguage, query).
+		Select(`events.*,
+			ts_rank_cd(events.search_vector, query, 32) AS rank,
+			ts_headline(?::regconfig, coalesce(events.short_description, '') || ' ' || coalesce(events.detailed_description, ''), query, ?) AS snippet`,
+			t.searchLanguage, headlineOptions).
+		Where("events.search_vector @@ query AND events.deleted_at IS NULL").
+		Order("rank DESC").
+		Limit(limit).
+		Scan(&rows)
+	if r.Error != nil {
+		return nil, fmt.Errorf("db error on search query: %w", r.Error)
+	}
+
+	results := make([]timeline2.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		domainEvent, err := toDomainEvent(row.Event)
+		if err != nil {
+			return nil, fmt.Errorf("cannot translate db model to domain")
+		}
+		results = append(results, timeline2.SearchResult{
+			Event:   domainEvent,
+			Rank:    row.Rank,
+			Snippet: row.Snippet,
+		})
+	}
+	return results, nil
+}
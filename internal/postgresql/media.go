@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	log *zap.Logger
+
+	db *gorm.DB
+}
+
+func NewMediaRepository(log *zap.Logger, db *gorm.DB) *MediaRepository {
+	return &MediaRepository{log: log, db: db}
+}
+
+func toDBMedia(dm *timeline2.Media) (*media, error) {
+	return &media{
+		Key:         dm.Key,
+		ContentType: dm.ContentType,
+		Size:        dm.Size,
+		Checksum:    dm.Checksum,
+	}, nil
+}
+
+func (mr MediaRepository) CreateMedia(ctx context.Context, dm *timeline2.Media) error {
+	dbMedia, err := toDBMedia(dm)
+	if err != nil {
+		return err
+	}
+
+	// media keys are content addressed, so uploading the same content twice reuses the row
+	if err := mr.db.WithContext(ctx).Where(media{Key: dm.Key}).FirstOrCreate(dbMedia).Error; err != nil {
+		return fmt.Errorf("cannot create media: %w", err)
+	}
+	dm.CreatedAt = dbMedia.CreatedAt
+	return nil
+}
+
+func (mr MediaRepository) GetMedia(ctx context.Context, key string) (timeline2.Media, error) {
+	var m media
+	if err := mr.db.WithContext(ctx).Where("key = ?", key).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return timeline2.Media{}, timeline2.ErrNotFound
+		}
+		return timeline2.Media{}, fmt.Errorf("db error on select query: %w", err)
+	}
+	domainMedia, err := toDomainMedia(m)
+	if err != nil {
+		return timeline2.Media{}, fmt.Errorf("cannot translate db model to domain")
+	}
+	return domainMedia, nil
+}
+
+func toDomainMedia(m media) (timeline2.Media, error) {
+	domainMedia := timeline2.Media{
+		Key:         m.Key,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		Checksum:    m.Checksum,
+		CreatedAt:   m.CreatedAt,
+	}
+	return domainMedia, nil
+}
@@ -13,20 +13,71 @@ type event struct {
 	EventTime           time.Time
 	ShortDescription    string
 	DetailedDescription string
+	DescriptionFormat   string
 	Graphic             string
 	TypeID              uint
 	Type                eventType `gorm:"foreignKey:TypeID"`
+	Tags                []tag     `gorm:"many2many:event_tags"`
+	Revision            uint      `gorm:"not null;default:1"`
+	// ExternalID is unique among events that are not deleted, so that deleted events can be imported again.
+	ExternalID *string `gorm:"uniqueIndex:idx_events_live_external_id,where:deleted_at IS NULL"`
+}
+
+type tag struct {
+	gorm.Model
+
+	Name string `gorm:"uniqueIndex;not null"`
 }
 
 type eventType struct {
 	gorm.Model
 
-	Name  string `gorm:"uniqueIndex;not null"`
-	Color string
+	Name         string `gorm:"uniqueIndex;not null"`
+	Color        string
+	Icon         string
+	Theme        string
+	DisplayOrder int        `gorm:"not null;default:0"`
+	ParentID     *uint      `gorm:"index"`
+	Parent       *eventType `gorm:"foreignKey:ParentID"`
+	Revision     uint       `gorm:"not null;default:1"`
 
 	Events []event `gorm:"foreignKey:TypeID"`
 }
 
+type eventRelation struct {
+	gorm.Model
+
+	FromID uint   `gorm:"uniqueIndex:idx_event_relation;not null"`
+	From   event  `gorm:"foreignKey:FromID;constraint:OnDelete:CASCADE"`
+	ToID   uint   `gorm:"uniqueIndex:idx_event_relation;not null"`
+	To     event  `gorm:"foreignKey:ToID;constraint:OnDelete:CASCADE"`
+	Kind   string `gorm:"uniqueIndex:idx_event_relation;not null"`
+}
+
+type media struct {
+	gorm.Model
+
+	Key         string `gorm:"uniqueIndex;not null"`
+	ContentType string
+	Size        int64
+	Checksum    string
+}
+
+// pageCacheEntry is a page cached for all instances of the server, its key holds the generation it belongs to.
+type pageCacheEntry struct {
+	Key       string `gorm:"primaryKey"`
+	Body      []byte
+	Header    string
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// pageCacheVersion is the single row holding the current generation of cached pages.
+type pageCacheVersion struct {
+	ID         uint `gorm:"primaryKey"`
+	Generation uint64
+	ModifiedAt time.Time
+}
+
 type user struct {
 	gorm.Model
 
@@ -0,0 +1,136 @@
+package postgresql
+
+import (
+	"fmt"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type RelationRepository struct {
+	log *zap.Logger
+
+	db *gorm.DB
+}
+
+func NewRelationRepository(log *zap.Logger, db *gorm.DB) *RelationRepository {
+	return &RelationRepository{log: log, db: db}
+}
+
+// liveRelations limits the query to relations between events that were not deleted.
+func liveRelations(q *gorm.DB) *gorm.DB {
+	return q.
+		Joins("JOIN events from_events ON from_events.id = event_relations.from_id AND from_events.deleted_at IS NULL").
+		Joins("JOIN events to_events ON to_events.id = event_relations.to_id AND to_events.deleted_at IS NULL")
+}
+
+func (rr RelationRepository) ListRelations(ctx context.Context, kind timeline2.RelationKind) ([]timeline2.Relation, error) {
+	var relations []eventRelation
+	q := liveRelations(rr.db.WithContext(ctx).Model(&eventRelation{}))
+	if kind != "" {
+		q = q.Where("event_relations.kind = ?", kind)
+	}
+	if err := q.Order("event_relations.id").Find(&relations).Error; err != nil {
+		return nil, fmt.Errorf("db error on select query: %w", err)
+	}
+
+	domainRelations := []timeline2.Relation{}
+	for _, r := range relations {
+		domainRelations = append(domainRelations, toDomainRelation(r))
+	}
+	return domainRelations, nil
+}
+
+func (rr RelationRepository) ListRelatedEvents(ctx context.Context, eventID uint) ([]timeline2.RelatedEvent, error) {
+	var count int64
+	if err := rr.db.WithContext(ctx).Model(&event{}).Where("id = ?", eventID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("db error on select query: %w", err)
+	}
+	if count == 0 {
+		return nil, timeline2.ErrNotFound
+	}
+
+	var relations []eventRelation
+	err := liveRelations(rr.db.WithContext(ctx).Model(&eventRelation{})).
+		Preload("From").
+		Preload("To").
+		Where("event_relations.from_id = ? OR event_relations.to_id = ?", eventID, eventID).
+		Order("event_relations.id").
+		Find(&relations).Error
+	if err != nil {
+		return nil, fmt.Errorf("db error on select query: %w", err)
+	}
+
+	related := []timeline2.RelatedEvent{}
+	for _, r := range relations {
+		other := r.To
+		if r.ToID == eventID {
+			other = r.From
+		}
+		domainEvent, err := toDomainEvent(other)
+		if err != nil {
+			return nil, fmt.Errorf("cannot translate db model to domain")
+		}
+		related = append(related, timeline2.RelatedEvent{
+			Relation: toDomainRelation(r),
+			Event:    domainEvent,
+		})
+	}
+	return related, nil
+}
+
+func (rr RelationRepository) CreateRelation(ctx context.Context, r *timeline2.Relation) (uint, error) {
+	var count int64
+	err := rr.db.WithContext(ctx).Model(&event{}).Where("id IN ?", []uint{r.FromID, r.ToID}).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("db error on select query: %w", err)
+	}
+	if count != 2 {
+		return 0, timeline2.ErrNotFound
+	}
+
+	dbRelation := &eventRelation{FromID: r.FromID, ToID: r.ToID, Kind: string(r.Kind)}
+	err = rr.db.WithContext(ctx).Model(&eventRelation{}).Where(dbRelation).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("db error on select query: %w", err)
+	}
+	if count > 0 {
+		return 0, fmt.Errorf("%w: relation already exists", timeline2.ErrConflict)
+	}
+	if err := rr.db.WithContext(ctx).Omit("From", "To").Create(dbRelation).Error; err != nil {
+		return 0, fmt.Errorf("cannot create relation: %w", err)
+	}
+	return dbRelation.ID, nil
+}
+
+func (rr RelationRepository) DeleteRelation(ctx context.Context, eventID, id uint) error {
+	r := rr.db.WithContext(ctx).
+		Where("from_id = ? OR to_id = ?", eventID, eventID).
+		Unscoped().
+		Delete(&eventRelation{}, id)
+	if r.Error != nil {
+		return fmt.Errorf("error while deleting: %w", r.Error)
+	}
+	if r.RowsAffected == 0 {
+		return timeline2.ErrNotFound
+	}
+	return nil
+}
+
+func (rr RelationRepository) LockRelations(ctx context.Context) error {
+	// the mode conflicts with itself and with writes, but not with reads
+	if err := rr.db.WithContext(ctx).Exec("LOCK TABLE event_relations IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return fmt.Errorf("cannot lock relations: %w", err)
+	}
+	return nil
+}
+
+func toDomainRelation(r eventRelation) timeline2.Relation {
+	return timeline2.Relation{
+		ID:     r.ID,
+		FromID: r.FromID,
+		ToID:   r.ToID,
+		Kind:   timeline2.RelationKind(r.Kind),
+	}
+}
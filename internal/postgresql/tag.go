@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	log *zap.Logger
+
+	db *gorm.DB
+}
+
+func NewTagRepository(log *zap.Logger, db *gorm.DB) *TagRepository {
+	return &TagRepository{log: log, db: db}
+}
+
+type tagWithCount struct {
+	Tag        tag `gorm:"embedded"`
+	EventCount int
+}
+
+func (tr TagRepository) ListTags(ctx context.Context) ([]timeline2.Tag, error) {
+	var tags []tagWithCount
+	r := tr.db.WithContext(ctx).
+		Model(&tag{}).
+		Select("tags.*, COUNT(events.id) AS event_count").
+		Joins("LEFT JOIN event_tags ON event_tags.tag_id = tags.id").
+		Joins("LEFT JOIN events ON events.id = event_tags.event_id AND events.deleted_at IS NULL").
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&tags)
+	if r.Error != nil {
+		return nil, fmt.Errorf("db error on select query: %w", r.Error)
+	}
+
+	domainTags := []timeline2.Tag{}
+	for _, t := range tags {
+		domainTags = append(domainTags, timeline2.Tag{
+			ID:         t.Tag.ID,
+			Name:       t.Tag.Name,
+			EventCount: t.EventCount,
+		})
+	}
+	return domainTags, nil
+}
+
+func (tr TagRepository) GetTagByName(ctx context.Context, name string) (timeline2.Tag, error) {
+	var t tag
+	if err := tr.db.WithContext(ctx).Where("name = ?", name).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return timeline2.Tag{}, timeline2.ErrNotFound
+		}
+		return timeline2.Tag{}, fmt.Errorf("db error on select query: %w", err)
+	}
+	return timeline2.Tag{ID: t.ID, Name: t.Name}, nil
+}
+
+func (tr TagRepository) RenameTag(ctx context.Context, id uint, name string) error {
+	r := tr.db.WithContext(ctx).Model(&tag{}).Where("id = ?", id).Update("name", name)
+	if r.Error != nil {
+		return fmt.Errorf("db error on update query: %w", r.Error)
+	}
+	if r.RowsAffected == 0 {
+		return timeline2.ErrNotFound
+	}
+	return nil
+}
+
+// MergeTags moves all events tagged with the source tag to the target tag and deletes the source tag.
+func (tr TagRepository) MergeTags(ctx context.Context, sourceID, targetID uint) error {
+	return tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&tag{}).Where("id IN ?", []uint{sourceID, targetID}).Count(&count).Error; err != nil {
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		if count != 2 {
+			return timeline2.ErrNotFound
+		}
+
+		err := tx.Exec(`INSERT INTO event_tags (event_id, tag_id)
+			SELECT event_id, ? FROM event_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+		if err != nil {
+			return fmt.Errorf("cannot retag events: %w", err)
+		}
+		if err := tx.Exec(`DELETE FROM event_tags WHERE tag_id = ?`, sourceID).Error; err != nil {
+			return fmt.Errorf("cannot untag events: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&tag{}, sourceID).Error; err != nil {
+			return fmt.Errorf("error while deleting: %w", err)
+		}
+		return nil
+	})
+}
+
+// resolveTags returns the tags with the given names, creating the missing ones.
+func resolveTags(tx *gorm.DB, names []string) ([]tag, error) {
+	tags := make([]tag, 0, len(names))
+	for _, name := range names {
+		t := tag{Name: name}
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Where(tag{Name: name}).
+			FirstOrCreate(&t).Error
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve tag %q: %w", name, err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
 	"gorm.io/gorm"
+	"time"
 )
 
 type TypeRepository struct {
@@ -21,20 +22,54 @@ func NewTypeRepository(log *zap.Logger, db *gorm.DB) *TypeRepository {
 
 func toDBType(dt *timeline2.Type) (*eventType, error) {
 	return &eventType{
-		Name:  dt.Name,
-		Color: dt.Color,
+		Name:         dt.Name,
+		Color:        dt.Color,
+		Icon:         dt.Icon,
+		Theme:        dt.Theme,
+		DisplayOrder: dt.DisplayOrder,
+		ParentID:     dt.ParentID,
 	}, nil
 }
 
-func (tr TypeRepository) GetType(ctx context.Context, id uint) (timeline2.Type, error) {
-	var t eventType
-	if err := tr.db.WithContext(ctx).First(&t, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return timeline2.Type{}, timeline2.ErrNotFound
+type typeWithStats struct {
+	Type           eventType `gorm:"embedded"`
+	EventCount     int
+	FirstEventTime *time.Time
+	LastEventTime  *time.Time
+}
+
+// withStats aggregates the live events of every selected type.
+func withStats(q *gorm.DB) *gorm.DB {
+	return q.Model(&eventType{}).
+		Select("event_types.*, COUNT(events.id) AS event_count, " +
+			"MIN(events.event_time) AS first_event_time, MAX(events.event_time) AS last_event_time").
+		Joins("LEFT JOIN events ON events.type_id = event_types.id AND events.deleted_at IS NULL").
+		Group("event_types.id")
+}
+
+func (tr TypeRepository) GetType(ctx context.Context, id uint, opts timeline2.TypeOptions) (timeline2.Type, error) {
+	var t typeWithStats
+	r := withStats(tr.db.WithContext(ctx)).Where("event_types.id = ?", id).Scan(&t)
+	if r.Error != nil {
+		return timeline2.Type{}, fmt.Errorf("db error on select query: %w", r.Error)
+	}
+	if r.RowsAffected == 0 {
+		return timeline2.Type{}, timeline2.ErrNotFound
+	}
+
+	if opts.IncludeEvents {
+		err := tr.db.WithContext(ctx).
+			Preload("Events", func(db *gorm.DB) *gorm.DB {
+				return db.Order("events.event_time, events.id").Limit(opts.EventsLimit).Offset(opts.EventsOffset)
+			}).
+			Preload("Events.Tags").
+			First(&t.Type, id).Error
+		if err != nil {
+			return timeline2.Type{}, fmt.Errorf("db error on select query: %w", err)
 		}
-		return timeline2.Type{}, fmt.Errorf("db error on select query: %w", err)
 	}
-	domainType, err := toDomainType(t)
+
+	domainType, err := toDomainTypeWithStats(t)
 	if err != nil {
 		return timeline2.Type{}, fmt.Errorf("cannot translate db model to domain")
 	}
@@ -42,27 +77,65 @@ func (tr TypeRepository) GetType(ctx context.Context, id uint) (timeline2.Type,
 }
 
 func (tr TypeRepository) UpdateType(ctx context.Context, id uint, dt *timeline2.Type) error {
-	var t eventType
-	r := tr.db.WithContext(ctx).Find(&t, id)
-	if r.Error != nil {
-		return fmt.Errorf("db error on select query: %w", r.Error)
-	}
-
-	t.Name = dt.Name
-	t.Color = dt.Color
+	return tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t eventType
+		if err := lockForUpdate(tx).First(&t, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return timeline2.ErrNotFound
+			}
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		if err := checkRevision(t.Revision, dt.Revision); err != nil {
+			return err
+		}
 
-	if err := tr.db.WithContext(ctx).Save(&t).Error; err != nil {
-		return fmt.Errorf("db error on update query: %w", r.Error)
-	}
+		t.Name = dt.Name
+		t.Color = dt.Color
+		t.Icon = dt.Icon
+		t.Theme = dt.Theme
+		t.DisplayOrder = dt.DisplayOrder
+		t.ParentID = dt.ParentID
+		t.Revision++
 
-	return nil
+		if err := tx.Save(&t).Error; err != nil {
+			return fmt.Errorf("db error on update query: %w", err)
+		}
+		dt.Revision = t.Revision
+		return nil
+	})
 }
 
-func (tr TypeRepository) DeleteType(ctx context.Context, id uint) error {
-	if err := tr.db.WithContext(ctx).Delete(&eventType{}, id).Error; err != nil {
-		return fmt.Errorf("error while deleting: %w", err)
-	}
-	return nil
+func (tr TypeRepository) DeleteType(ctx context.Context, id uint, policy timeline2.TypeDeletePolicy, revision uint) error {
+	return tr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t eventType
+		if err := lockForUpdate(tx).First(&t, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return timeline2.ErrNotFound
+			}
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		if err := checkRevision(t.Revision, revision); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&eventType{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("db error on select query: %w", err)
+		}
+		if count > 0 {
+			if policy != timeline2.TypeDeleteReparent {
+				return fmt.Errorf("%w: type %d has %d child types", timeline2.ErrConflict, id, count)
+			}
+			if err := tx.Model(&eventType{}).Where("parent_id = ?", id).Update("parent_id", t.ParentID).Error; err != nil {
+				return fmt.Errorf("db error on update query: %w", err)
+			}
+		}
+
+		if err := tx.Delete(&t).Error; err != nil {
+			return fmt.Errorf("error while deleting: %w", err)
+		}
+		return nil
+	})
 }
 
 func (tr TypeRepository) CreateType(ctx context.Context, dt *timeline2.Type) (uint, error) {
@@ -78,15 +151,15 @@ func (tr TypeRepository) CreateType(ctx context.Context, dt *timeline2.Type) (ui
 }
 
 func (tr TypeRepository) ListTypes(ctx context.Context) ([]timeline2.Type, error) {
-	var types []eventType
-	r := tr.db.WithContext(ctx).Find(&types)
+	var types []typeWithStats
+	r := withStats(tr.db.WithContext(ctx)).Order("event_types.display_order, event_types.name").Scan(&types)
 	if r.Error != nil {
 		return nil, fmt.Errorf("db error on select query: %w", r.Error)
 	}
 
 	domainTypes := []timeline2.Type{}
 	for _, e := range types {
-		domainType, err := toDomainType(e)
+		domainType, err := toDomainTypeWithStats(e)
 		if err != nil {
 			return nil, fmt.Errorf("cannot translate db model to domain")
 		}
@@ -98,9 +171,32 @@ func (tr TypeRepository) ListTypes(ctx context.Context) ([]timeline2.Type, error
 
 func toDomainType(mt eventType) (timeline2.Type, error) {
 	domainType := timeline2.Type{
-		ID:    mt.ID,
-		Name:  mt.Name,
-		Color: mt.Color,
+		ID:           mt.ID,
+		Name:         mt.Name,
+		Color:        mt.Color,
+		Icon:         mt.Icon,
+		Theme:        mt.Theme,
+		DisplayOrder: mt.DisplayOrder,
+		ParentID:     mt.ParentID,
+		Revision:     mt.Revision,
+	}
+	for _, e := range mt.Events {
+		domainEvent, err := toDomainEvent(e)
+		if err != nil {
+			return timeline2.Type{}, err
+		}
+		domainType.Events = append(domainType.Events, domainEvent)
+	}
+	return domainType, nil
+}
+
+func toDomainTypeWithStats(t typeWithStats) (timeline2.Type, error) {
+	domainType, err := toDomainType(t.Type)
+	if err != nil {
+		return timeline2.Type{}, err
 	}
+	domainType.EventCount = t.EventCount
+	domainType.FirstEventTime = t.FirstEventTime
+	domainType.LastEventTime = t.LastEventTime
 	return domainType, nil
 }
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"net/http"
+)
+
+func (s *Server) executeBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// the size of the body bounds the memory taken by the operations, their number is checked once decoded
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.Server.BatchMaxBytes)
+		var req schema2.BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				s.writeErrResponse(w, err, http.StatusRequestEntityTooLarge, schema2.ErrTooLarge)
+				return
+			}
+			s.writeErrResponse(w, fmt.Errorf("cannot unmarshal body: %w", err), http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		mode := timeline2.BatchMode(req.Mode)
+		if mode == "" {
+			mode = timeline2.BatchAtomic
+		}
+		if len(req.Operations) > s.config.Server.BatchMaxSize {
+			err := fmt.Errorf("batch of %d operations exceeds the limit of %d", len(req.Operations), s.config.Server.BatchMaxSize)
+			s.writeErrResponse(w, err, http.StatusRequestEntityTooLarge, schema2.ErrTooLarge)
+			return
+		}
+
+		// operations that cannot be decoded fail on their own, the rest is executed
+		results := make([]*schema2.BatchResult, len(req.Operations))
+		var (
+			ops     []timeline2.BatchOperation
+			indices []int
+		)
+		for i, op := range req.Operations {
+			domainOp, err := toDomainBatchOperation(op)
+			if err != nil {
+				results[i] = batchResult(i, 0, err)
+				continue
+			}
+			ops = append(ops, domainOp)
+			indices = append(indices, i)
+		}
+
+		var domainResults []timeline2.BatchResult
+		if mode != timeline2.BatchAtomic || len(ops) == len(req.Operations) {
+			var err error
+			domainResults, err = s.batchService.ExecuteBatch(ctx, mode, ops)
+			if err != nil && domainResults == nil {
+				s.writeDomainErrResponse(w, err)
+				return
+			}
+		}
+		for j, res := range domainResults {
+			results[indices[j]] = batchResult(indices[j], res.ID, res.Err)
+			if res.Err == nil && ops[j].Action == timeline2.BatchCreate {
+				results[indices[j]].Status = http.StatusCreated
+			}
+		}
+
+		status := http.StatusOK
+		for i := range results {
+			if results[i] == nil {
+				results[i] = batchResult(i, 0, timeline2.ErrBatchAborted)
+			}
+			if mode == timeline2.BatchAtomic && results[i].Status >= http.StatusBadRequest && results[i].Status != http.StatusFailedDependency {
+				status = results[i].Status
+			}
+		}
+
+		resp, err := json.Marshal(schema2.BatchResponse{Mode: string(mode), Results: results})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if _, err := w.Write(resp); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func batchResult(index int, id uint, err error) *schema2.BatchResult {
+	if err == nil {
+		return &schema2.BatchResult{Index: index, Status: http.StatusOK, ID: id}
+	}
+	status, _ := domainErrStatus(err)
+	return &schema2.BatchResult{Index: index, Status: status, ID: id, Error: err.Error()}
+}
+
+func toDomainBatchOperation(op *schema2.BatchOperation) (timeline2.BatchOperation, error) {
+	if op == nil {
+		return timeline2.BatchOperation{}, fmt.Errorf("%w: empty operation", timeline2.ErrInvalid)
+	}
+	domainOp := timeline2.BatchOperation{
+		Action:   timeline2.BatchAction(op.Action),
+		Resource: timeline2.BatchResource(op.Resource),
+		ID:       op.ID,
+		Policy:   timeline2.TypeDeletePolicy(op.Children),
+	}
+
+	if domainOp.Action == timeline2.BatchUpdate || domainOp.Action == timeline2.BatchDelete {
+		revision, err := parseIfMatch(op.IfMatch)
+		if errors.Is(err, errPreconditionRequired) {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: if_match is required to %s a resource", timeline2.ErrInvalid, op.Action)
+		}
+		if err != nil {
+			return timeline2.BatchOperation{}, err
+		}
+		domainOp.Revision = revision
+	}
+	if domainOp.Action == timeline2.BatchDelete {
+		return domainOp, nil
+	}
+
+	var required []string
+	switch domainOp.Resource {
+	case timeline2.BatchEvent:
+		if domainOp.Action == timeline2.BatchUpdate {
+			required = eventFields
+		}
+		if err := requireFields(op.Data, required); err != nil {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: %v", timeline2.ErrInvalid, err)
+		}
+		var event schema2.Event
+		if err := json.Unmarshal(op.Data, &event); err != nil {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: cannot unmarshal event: %v", timeline2.ErrInvalid, err)
+		}
+		domainEvent, err := codec.HTTPToDomainEvent(&event)
+		if err != nil {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: %v", timeline2.ErrInvalid, err)
+		}
+		domainOp.Event = domainEvent
+	case timeline2.BatchType:
+		if domainOp.Action == timeline2.BatchUpdate {
+			required = typeFields
+		}
+		if err := requireFields(op.Data, required); err != nil {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: %v", timeline2.ErrInvalid, err)
+		}
+		var dt schema2.Type
+		if err := json.Unmarshal(op.Data, &dt); err != nil {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: cannot unmarshal type: %v", timeline2.ErrInvalid, err)
+		}
+		domainType, err := codec.HTTPToDomainType(&dt)
+		if err != nil {
+			return timeline2.BatchOperation{}, fmt.Errorf("%w: %v", timeline2.ErrInvalid, err)
+		}
+		domainOp.Type = domainType
+	}
+	return domainOp, nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const batchCreateEvent = `{"action":"create","resource":"event","data":{"name":"Apollo 11","event_time":"1969-07-16T13:32:00Z",` +
+	`"short_description":"Launch","detailed_description":"","graphic":"","type_id":1,"tags":["apollo"]}}`
+
+func batchBody(ops int) string {
+	return `{"mode":"atomic","operations":[` + strings.Repeat(batchCreateEvent+",", ops-1) + batchCreateEvent + `]}`
+}
+
+func TestExecuteBatch(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.BatchMaxSize = 500
+	cfg.Server.BatchMaxBytes = 1024
+	batchService := mocks.NewBatchService(t)
+	s := &Server{config: cfg, log: zap.NewNop(), batchService: batchService}
+
+	batchService.On("ExecuteBatch", mock.Anything, timeline.BatchAtomic, mock.MatchedBy(func(ops []timeline.BatchOperation) bool {
+		return len(ops) == 2 && ops[0].Event.Name == "Apollo 11" && ops[1].Event.TypeID == 1
+	})).Return([]timeline.BatchResult{{ID: 7}, {ID: 8}}, nil).Once()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(batchBody(2)))
+	w := httptest.NewRecorder()
+	s.executeBatch()(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"mode":"atomic","results":[{"index":0,"status":201,"id":7},{"index":1,"status":201,"id":8}]}`, w.Body.String())
+
+	r = httptest.NewRequest(http.MethodPost, "/api/batch", strings.NewReader(batchBody(21)))
+	w = httptest.NewRecorder()
+	s.executeBatch()(w, r)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// The query parameters cached pages are rendered from, other parameters do not change the page.
+var (
+	eventFilterParams = []string{"tag", "tag_mode", "type", "from", "to", "q"}
+	sitePageParams    = append([]string{"group", "order", "event", "theme"}, eventFilterParams...)
+	embedPageParams   = append([]string{"height"}, sitePageParams...)
+)
+
+// withPageCache serves the page from the page cache, rendering and caching it if it is not there. Pages are keyed
+// by their URL with the given query parameters, the ones the page is rendered from, and tagged with a digest of
+// their body. Failing caches are logged and the page is rendered as if there was no cache. Pages are served as last
+// modified when the cache was last invalidated.
+func (s *Server) withPageCache(params []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.pageCache == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := r.Context()
+		version, err := s.pageCache.Version(ctx)
+		if err != nil {
+			s.log.Error(fmt.Sprintf("cannot read page cache version: %v", err))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := s.pageCacheKey(version.Generation, r, params)
+		page, ok, err := s.pageCache.Get(ctx, key)
+		if err != nil {
+			s.log.Error(fmt.Sprintf("cannot read page cache: %v", err))
+		}
+		if !ok {
+			// the whole page is rendered, whether the client already has it is decided below
+			inner := r.Clone(ctx)
+			inner.Header.Del("If-None-Match")
+			inner.Header.Del("If-Modified-Since")
+			rec := &pageRecorder{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, inner)
+			if rec.status != http.StatusOK {
+				s.writeRecorded(w, rec)
+				return
+			}
+			page = rec.page()
+			if err := s.pageCache.Set(ctx, key, page); err != nil {
+				s.log.Error(fmt.Sprintf("cannot write page cache: %v", err))
+			}
+		}
+
+		for name, values := range page.Header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Cache-Control", s.pageCacheControl())
+		s.writeCacheable(w, r, collectionETag(page.Body), version.Modified, page.Header.Get("Content-Type"), page.Body)
+	}
+}
+
+// pageCacheControl lets clients keep pages, but unless CACHE_MAX_AGE_SECONDS is set they revalidate them on
+// every use.
+func (s *Server) pageCacheControl() string {
+	if s.config.Cache.MaxAgeSeconds <= 0 {
+		return "public, no-cache"
+	}
+	return "public, max-age=" + strconv.Itoa(s.config.Cache.MaxAgeSeconds)
+}
+
+// pageCacheKey holds the base URL as pages link to themselves, and the encoded query with its parameters sorted.
+// Only the non-empty values of the given parameters are kept, so that junk parameters cannot fill the cache.
+func (s *Server) pageCacheKey(generation uint64, r *http.Request, params []string) string {
+	q := r.URL.Query()
+	query := url.Values{}
+	for _, name := range params {
+		for _, value := range q[name] {
+			if value != "" {
+				query.Add(name, value)
+			}
+		}
+	}
+	return fmt.Sprintf("%d %s%s?%s", generation, s.baseURL(r), r.URL.Path, query.Encode())
+}
+
+// pageRecorder keeps the response of a handler to cache it.
+type pageRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (p *pageRecorder) Header() http.Header {
+	return p.header
+}
+
+func (p *pageRecorder) WriteHeader(status int) {
+	p.status = status
+}
+
+func (p *pageRecorder) Write(b []byte) (int, error) {
+	return p.body.Write(b)
+}
+
+// page returns the recorded response without the validators, which are derived from the cached page instead.
+func (p *pageRecorder) page() timeline.CachedPage {
+	header := p.header.Clone()
+	header.Del("Etag")
+	header.Del("Last-Modified")
+	header.Del("Cache-Control")
+	return timeline.CachedPage{Body: p.body.Bytes(), Header: header}
+}
+
+// writeRecorded passes responses that are not cached, like errors, on to the client.
+func (s *Server) writeRecorded(w http.ResponseWriter, rec *pageRecorder) {
+	for name, values := range rec.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(rec.status)
+	if _, err := w.Write(rec.body.Bytes()); err != nil {
+		s.log.Error("cannot write response")
+		return
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"github.com/kamkali/go-timeline/internal/cache"
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithPageCache(t *testing.T) {
+	s := &Server{
+		config:    &config.Config{},
+		log:       zap.NewNop(),
+		siteURL:   "https://timeline.example.com",
+		pageCache: cache.NewMemoryCache(10, 1024, time.Minute),
+	}
+	rendered := 0
+	handler := s.withPageCache([]string{"tag"}, func(w http.ResponseWriter, r *http.Request) {
+		rendered++
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Write([]byte("<p>" + r.URL.Query().Get("tag") + "</p>"))
+	})
+
+	for _, target := range []string{"/?tag=apollo", "/?tag=apollo&x=1", "/?x=2&tag=apollo&tag=", "/?tag=gemini"} {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Host = "attacker.example.com"
+		w := httptest.NewRecorder()
+		handler(w, r)
+		require.Equal(t, http.StatusOK, w.Code, target)
+		require.Equal(t, []string{"Accept", "Accept-Encoding"}, w.Header().Values("Vary"), target)
+	}
+	require.Equal(t, 2, rendered)
+}
+
+func TestPageCacheKey(t *testing.T) {
+	s := &Server{config: &config.Config{}, siteURL: "https://timeline.example.com"}
+	r := httptest.NewRequest(http.MethodGet, "/api/events?type=3&x=1&tag=nasa&tag=apollo&q=", nil)
+	r.Host = "attacker.example.com"
+	require.Equal(t, "7 https://timeline.example.com/api/events?tag=nasa&tag=apollo&type=3",
+		s.pageCacheKey(7, r, eventFilterParams))
+}
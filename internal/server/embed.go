@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/generator"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"net/http"
+	"strconv"
+)
+
+var errEmbedTypeFilter = errors.New("the timeline of a type cannot be filtered by type")
+
+// renderEmbed renders the minimal page of a timeline shown in frames on other sites, e.g.
+// /embed/3?height=400&theme=compact&from=1960-01-01. The query takes the filters and layout of the timeline page.
+func (s *Server) renderEmbed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := s.getEventFilterFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		typeID, err := s.getEmbedTimeline(r)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		if typeID != 0 {
+			if len(filter.TypeIDs) > 0 {
+				s.writeErrResponse(w, errEmbedTypeFilter, http.StatusBadRequest, schema2.ErrBadRequest)
+				return
+			}
+			filter.TypeIDs = []uint{typeID}
+		}
+		height, err := getEmbedHeight(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Security-Policy", s.frameAncestors)
+		s.writeSite(w, r, filter, generator.SiteOptions{
+			// embedded pages are served under /embed/, links to assets and pages must not be relative to it
+			Links:  generator.SiteLinks{Root: "/", EventPages: true, BaseURL: s.baseURL(r)},
+			Embed:  true,
+			Height: height,
+		})
+	}
+}
+
+// embedSnippet returns the HTML embedding a timeline, e.g. /api/embed?timeline=3&height=400&theme=compact.
+// The other parameters are passed on to the embedded page.
+func (s *Server) embedSnippet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		name := q.Get("timeline")
+		if name == "" {
+			name = generator.EmbedAll
+		}
+		typeID, err := s.embedTimelineType(r, name)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		if typeID != 0 && len(nonEmpty(q["type"])) > 0 {
+			s.writeErrResponse(w, errEmbedTypeFilter, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		height, err := getEmbedHeight(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		// the filters are checked here rather than when the embedded page fails to load
+		if _, err := s.getEventFilterFromRequest(r); err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		q.Del("timeline")
+		q.Del("height")
+
+		snippet := generator.NewEmbedSnippet(s.baseURL(r), name, q, height)
+		response, err := json.Marshal(schema2.EmbedSnippetResponse{
+			URL:    snippet.URL,
+			Script: snippet.Script,
+			IFrame: snippet.IFrame,
+		})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(response); err != nil {
+			s.log.Error("cannot write response")
+		}
+	}
+}
+
+// getEmbedTimeline returns the type of the timeline named in the path, 0 for the timeline of all events.
+func (s *Server) getEmbedTimeline(r *http.Request) (uint, error) {
+	return s.embedTimelineType(r, mux.Vars(r)["timeline"])
+}
+
+// embedTimelineType checks that a timeline name is "all" or the ID of an existing type and returns the type ID.
+func (s *Server) embedTimelineType(r *http.Request, name string) (uint, error) {
+	if name == generator.EmbedAll {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(name, 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: unknown timeline %q, expected %q or a type ID", timeline2.ErrInvalid, name, generator.EmbedAll)
+	}
+	if _, err := s.typeService.GetType(r.Context(), uint(id), timeline2.TypeOptions{}); err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// getEmbedHeight reads the height of an embedded timeline in pixels.
+func getEmbedHeight(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("height")
+	if raw == "" {
+		return generator.DefaultEmbedHeight, nil
+	}
+	height, err := strconv.Atoi(raw)
+	if err != nil || height < generator.MinEmbedHeight || height > generator.MaxEmbedHeight {
+		return 0, fmt.Errorf("invalid height %q, expected pixels between %d and %d",
+			raw, generator.MinEmbedHeight, generator.MaxEmbedHeight)
+	}
+	return height, nil
+}
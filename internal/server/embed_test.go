@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/kamkali/go-timeline/internal/generator"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRenderEmbed(t *testing.T) {
+	renderer, err := generator.NewRenderer(generator.RendererOptions{})
+	require.NoError(t, err)
+	frameAncestors, err := generator.FrameAncestors([]string{"https://docs.example.com"})
+	require.NoError(t, err)
+	eventService := mocks.NewEventService(t)
+	typeService := mocks.NewTypeService(t)
+	relationService := mocks.NewRelationService(t)
+	s := &Server{
+		config:          &config.Config{},
+		log:             zap.NewNop(),
+		siteURL:         "https://timeline.example.com",
+		eventService:    eventService,
+		typeService:     typeService,
+		relationService: relationService,
+		renderer:        renderer,
+		frameAncestors:  frameAncestors,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/embed/{timeline}", s.renderEmbed())
+
+	event := timeline.Event{ID: 42, Name: "Apollo 11", TypeID: 3, EventTime: time.Date(1969, 7, 16, 13, 32, 0, 0, time.UTC)}
+	typeService.On("GetType", mock.Anything, uint(3), timeline.TypeOptions{}).Return(timeline.Type{ID: 3, Name: "Missions"}, nil)
+
+	t.Run("may be framed by the embed origins", func(t *testing.T) {
+		eventService.On("ListEvents", mock.Anything, mock.MatchedBy(func(filter timeline.EventFilter) bool {
+			return len(filter.TypeIDs) == 1 && filter.TypeIDs[0] == 3
+		})).Return([]timeline.Event{event}, nil).Once()
+		relationService.On("ListRelations", mock.Anything).Return([]timeline.Relation{}, nil).Once()
+		typeService.On("ListTypes", mock.Anything).Return([]timeline.Type{{ID: 3, Name: "Missions"}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/embed/3?height=400", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "frame-ancestors 'self' https://docs.example.com", w.Header().Get("Content-Security-Policy"))
+		require.Contains(t, w.Body.String(), "Apollo 11")
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		for _, path := range []string{
+			"/embed/3?type=4",
+			"/embed/all?height=149",
+			"/embed/all?height=4001",
+			"/embed/all?height=tall",
+			"/embed/timeline",
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusBadRequest, w.Code, path)
+			require.Empty(t, w.Header().Get("Content-Security-Policy"), path)
+		}
+	})
+}
+
+func TestEmbedSnippet(t *testing.T) {
+	typeService := mocks.NewTypeService(t)
+	s := &Server{config: &config.Config{}, log: zap.NewNop(), siteURL: "https://timeline.example.com", typeService: typeService}
+	typeService.On("GetType", mock.Anything, uint(3), timeline.TypeOptions{}).Return(timeline.Type{ID: 3, Name: "Missions"}, nil)
+
+	t.Run("embeds the timeline", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.embedSnippet()(w, httptest.NewRequest(http.MethodGet, "/api/embed?timeline=3&height=4000&theme=compact", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var snippet schema2.EmbedSnippetResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &snippet))
+		require.Equal(t, "https://timeline.example.com/embed/3?height=4000&theme=compact", snippet.URL)
+		require.Contains(t, snippet.IFrame, `height="4000"`)
+	})
+
+	t.Run("defaults the height", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.embedSnippet()(w, httptest.NewRequest(http.MethodGet, "/api/embed", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "https://timeline.example.com/embed/all?height=600")
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		for _, target := range []string{
+			"/api/embed?timeline=3&type=4",
+			"/api/embed?height=149",
+			"/api/embed?height=4001",
+			"/api/embed?from=yesterday",
+		} {
+			w := httptest.NewRecorder()
+			s.embedSnippet()(w, httptest.NewRequest(http.MethodGet, target, nil))
+			require.Equal(t, http.StatusBadRequest, w.Code, target)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errPreconditionRequired = errors.New("the If-Match header is required, use the ETag of the resource or \"*\"")
+
+// entityETag tags a representation of an entity with its revision, which guards writes made with If-Match,
+// and a digest of the body, which keeps conditional GETs correct when derived data like relations changes.
+func entityETag(revision uint, body []byte) string {
+	return fmt.Sprintf(`"%d.%s"`, revision, digest(body))
+}
+
+func collectionETag(body []byte) string {
+	return fmt.Sprintf(`"%s"`, digest(body))
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8])
+}
+
+// etagListed reports whether an If-None-Match header lists etag, using the weak comparison.
+func etagListed(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCacheableJSON writes the body tagged with etag, or 304 Not Modified if the client already has it.
+func (s *Server) writeCacheableJSON(w http.ResponseWriter, r *http.Request, etag string, body []byte) {
+	s.writeCacheable(w, r, etag, time.Time{}, "application/json", body)
+}
+
+// writeCacheable writes the body tagged with etag and, unless it is zero, the time it was last modified.
+// If-Modified-Since is only considered without If-None-Match, as RFC 7232 has it.
+func (s *Server) writeCacheable(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time, contentType string, body []byte) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" {
+		if etagListed(noneMatch, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.IsZero() {
+		// the header has a resolution of seconds
+		if !lastModified.Truncate(time.Second).After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		s.log.Error("cannot write response")
+		return
+	}
+}
+
+// getIfMatchRevision returns the revision a write is based on. "*" matches any revision and yields 0.
+func (s *Server) getIfMatchRevision(r *http.Request) (uint, error) {
+	return parseIfMatch(r.Header.Get("If-Match"))
+}
+
+// parseIfMatch takes the revision from the entity tags listed. If-Match compares tags strongly, so weak tags never
+// match, and neither do tags that were not issued for an entity. A write is checked against a single revision, so
+// the tags listed must agree on it.
+func parseIfMatch(header string) (uint, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, errPreconditionRequired
+	}
+
+	var revision uint
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return 0, nil
+		}
+		rev, ok := etagRevision(tag)
+		switch {
+		case !ok:
+			continue
+		case revision != 0 && rev != revision:
+			return 0, fmt.Errorf("%w: If-Match lists entity tags of several revisions", timeline.ErrInvalid)
+		}
+		revision = rev
+	}
+	if revision == 0 {
+		return 0, fmt.Errorf("%w: %s is not a current entity tag", timeline.ErrPreconditionFailed, header)
+	}
+	return revision, nil
+}
+
+// etagRevision returns the revision of a strong tag made by entityETag.
+func etagRevision(tag string) (uint, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	rev, _, _ := strings.Cut(tag[1:len(tag)-1], ".")
+	revision, err := strconv.ParseUint(rev, 10, 32)
+	if err != nil || revision == 0 {
+		return 0, false
+	}
+	return uint(revision), true
+}
+
+func (s *Server) writePreconditionErrResponse(w http.ResponseWriter, err error) {
+	if errors.Is(err, errPreconditionRequired) {
+		s.writeErrResponse(w, err, http.StatusPreconditionRequired, schema2.ErrPreconditionRequired)
+		return
+	}
+	s.writeDomainErrResponse(w, err)
+}
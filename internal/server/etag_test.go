@@ -0,0 +1,61 @@
+package server
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		revision uint
+		wantErr  error
+	}{
+		"missing":               {header: " ", wantErr: errPreconditionRequired},
+		"any":                   {header: "*", revision: 0},
+		"entity tag":            {header: `"3.2d711642b726b044"`, revision: 3},
+		"revision only":         {header: ` "3" `, revision: 3},
+		"list":                  {header: `"3.2d711642b726b044", W/"4.2d711642b726b044", "3.0000000000000000"`, revision: 3},
+		"list with any":         {header: `"3.2d711642b726b044", *`, revision: 0},
+		"weak":                  {header: `W/"3.2d711642b726b044"`, wantErr: timeline.ErrPreconditionFailed},
+		"several revisions":     {header: `"3.2d711642b726b044", "4.2d711642b726b044"`, wantErr: timeline.ErrInvalid},
+		"unquoted":              {header: `3.2d711642b726b044`, wantErr: timeline.ErrPreconditionFailed},
+		"unterminated":          {header: `"3.2d711642b726b044`, wantErr: timeline.ErrPreconditionFailed},
+		"collection tag":        {header: `"2d711642b726b044"`, wantErr: timeline.ErrPreconditionFailed},
+		"zero revision":         {header: `"0.2d711642b726b044"`, wantErr: timeline.ErrPreconditionFailed},
+		"revision out of range": {header: `"4294967296.2d711642b726b044"`, wantErr: timeline.ErrPreconditionFailed},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			revision, err := parseIfMatch(tt.header)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.revision, revision)
+		})
+	}
+}
+
+func TestETagListed(t *testing.T) {
+	etag := `"3.2d711642b726b044"`
+	tests := map[string]struct {
+		header string
+		listed bool
+	}{
+		"same":      {header: etag, listed: true},
+		"any":       {header: "*", listed: true},
+		"weak":      {header: "W/" + etag, listed: true},
+		"list":      {header: `"2.2d711642b726b044", ` + etag, listed: true},
+		"other":     {header: `"2.2d711642b726b044"`},
+		"unquoted":  {header: "3.2d711642b726b044"},
+		"no header": {header: ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.listed, etagListed(tt.header, etag))
+		})
+	}
+}
@@ -31,22 +31,23 @@ func (s *Server) getEvent() http.HandlerFunc {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		httpEvent, err := codec.HTTPFromDomainEvent(&event)
+		httpEvent, err := s.httpEventWithDescription(&event)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
-		eventResponse, err := json.Marshal(schema2.EventResponse{Event: httpEvent})
+		httpEvent.Related, err = s.getRelatedEvents(ctx, id)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
-		if _, err := w.Write(eventResponse); err != nil {
-			s.log.Error("cannot write response")
+
+		eventResponse, err := json.Marshal(schema2.EventResponse{Event: httpEvent})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
+		s.writeCacheableJSON(w, r, entityETag(event.Revision, eventResponse), eventResponse)
 	}
 }
 
@@ -54,26 +55,77 @@ func (s *Server) updateEvent() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		domainEvent, err := s.getEventPayload(r)
+		id, err := s.getIDFromRequest(r)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
 			return
 		}
+		revision, err := s.getIfMatchRevision(r)
+		if err != nil {
+			s.writePreconditionErrResponse(w, err)
+			return
+		}
 
-		id, err := s.getIDFromRequest(r)
+		domainEvent, err := s.getEventPayload(r, eventFields...)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
 			return
 		}
+		domainEvent.Revision = revision
 
 		if err := s.eventService.UpdateEvent(ctx, id, domainEvent); err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
-				return
-			}
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) patchEvent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		revision, err := s.getIfMatchRevision(r)
+		if err != nil {
+			s.writePreconditionErrResponse(w, err)
+			return
+		}
+		patch, err := s.getMergePatch(r)
+		if err != nil {
+			s.writeMergePatchErrResponse(w, err)
+			return
+		}
+
+		event, err := s.eventService.GetEvent(ctx, id)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		current, err := codec.HTTPFromDomainEvent(&event)
+		if err != nil {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
+		var patched schema2.Event
+		if err := applyMergePatch(current, patch, &patched); err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		domainEvent, err := codec.HTTPToDomainEvent(&patched)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		domainEvent.Revision = revision
+
+		if err := s.eventService.UpdateEvent(ctx, id, domainEvent); err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	}
 }
@@ -87,8 +139,14 @@ func (s *Server) deleteEvent() http.HandlerFunc {
 			return
 		}
 
-		if err := s.eventService.DeleteEvent(ctx, id); err != nil {
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+		revision, err := s.getIfMatchRevision(r)
+		if err != nil {
+			s.writePreconditionErrResponse(w, err)
+			return
+		}
+
+		if err := s.eventService.DeleteEvent(ctx, id, revision); err != nil {
+			s.writeDomainErrResponse(w, err)
 			return
 		}
 
@@ -100,8 +158,18 @@ func (s *Server) listEvents() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		events, err := s.eventService.ListEvents(ctx)
+		filter, err := s.getEventFilterFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		events, err := s.eventService.ListEvents(ctx, filter)
 		if err != nil {
+			if errors.Is(err, timeline2.ErrInvalid) {
+				s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+				return
+			}
 			if errors.Is(err, context.DeadlineExceeded) {
 				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
 				return
@@ -110,12 +178,9 @@ func (s *Server) listEvents() http.HandlerFunc {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
 		var httpEvents []*schema2.Event
 		for i := range events {
-			httpEvent, err := codec.HTTPFromDomainEvent(&events[i])
+			httpEvent, err := s.httpEventWithDescription(&events[i])
 			if err != nil {
 				s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 				return
@@ -128,10 +193,7 @@ func (s *Server) listEvents() http.HandlerFunc {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
-		if _, err := w.Write(eventsResponse); err != nil {
-			s.log.Error("cannot write response")
-			return
-		}
+		s.writeCacheableJSON(w, r, collectionETag(eventsResponse), eventsResponse)
 	}
 }
 
@@ -147,11 +209,7 @@ func (s *Server) createEvent() http.HandlerFunc {
 
 		created, err := s.eventService.CreateEvent(ctx, domainEvent)
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
-				return
-			}
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			s.writeDomainErrResponse(w, err)
 			return
 		}
 
@@ -170,11 +228,31 @@ func (s *Server) createEvent() http.HandlerFunc {
 	}
 }
 
-func (s *Server) getEventPayload(r *http.Request) (*timeline2.Event, error) {
+// httpEventWithDescription converts an event for responses, along with its detailed description rendered to HTML.
+func (s *Server) httpEventWithDescription(event *timeline2.Event) (*schema2.Event, error) {
+	httpEvent, err := codec.HTTPFromDomainEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	description, err := s.renderer.DescriptionHTML(event.DescriptionFormat, event.DetailedDescription)
+	if err != nil {
+		return nil, err
+	}
+	httpEvent.DetailedDescriptionHTML = string(description)
+	return httpEvent, nil
+}
+
+// eventFields are the writable fields of an event, all of which a full replacement has to provide.
+var eventFields = []string{"name", "event_time", "short_description", "detailed_description", "description_format", "graphic", "type_id", "tags"}
+
+func (s *Server) getEventPayload(r *http.Request, required ...string) (*timeline2.Event, error) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read body")
 	}
+	if err := requireFields(body, required); err != nil {
+		return nil, err
+	}
 	var event schema2.Event
 	if err := json.Unmarshal(body, &event); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal body")
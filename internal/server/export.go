@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"net/http"
+)
+
+// exportTimeline streams exports in the format of the format parameter. Feeds pass their format instead,
+// they are served inline rather than as attachments. SVG and PDF exports are headed by the title parameter.
+func (s *Server) exportTimeline(feedFormat string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		filter, err := s.getEventFilterFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		format := feedFormat
+		if format == "" {
+			format = r.URL.Query().Get("format")
+		}
+		if format == "" {
+			format = codec.FormatJSON
+		}
+
+		out := &exportResponseWriter{ResponseWriter: w, format: format, attachment: feedFormat == ""}
+		exportWriter, err := codec.NewExportWriter(format, out, codec.ExportOptions{
+			BaseURL: s.baseURL(r),
+			Title:   r.URL.Query().Get("title"),
+		})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		if err := s.exportService.ExportTimeline(ctx, filter, exportWriter); err != nil {
+			if out.started {
+				// the status is already sent, the client is left with a truncated export
+				s.log.Error(fmt.Sprintf("export aborted: %v", err))
+				return
+			}
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+	}
+}
+
+// exportResponseWriter sends the headers of the export along with its first bytes, so that
+// errors occurring before anything is written still get a proper error response.
+type exportResponseWriter struct {
+	http.ResponseWriter
+	format     string
+	attachment bool
+	started    bool
+}
+
+func (e *exportResponseWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.Header().Set("Content-Type", codec.ExportContentType(e.format))
+		if e.attachment {
+			e.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"timeline.%s\"", codec.ExportFileExtension(e.format)))
+		}
+		e.WriteHeader(http.StatusOK)
+	}
+	return e.ResponseWriter.Write(p)
+}
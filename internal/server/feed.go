@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"net/http"
+	"strings"
+)
+
+const (
+	feedAtom = "atom"
+	feedRSS  = "rss"
+
+	defaultFeedLimit = 50
+	maxFeedLimit     = 200
+)
+
+// getFeed serves the recently created or updated events matching the filter of the request.
+func (s *Server) getFeed(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		filter, err := s.getEventFilterFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		limit, err := s.getLimitFromRequest(r, defaultFeedLimit, maxFeedLimit)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		types, err := s.typeService.ListTypes(ctx)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		feed := codec.Feed{
+			Title:     "Timeline",
+			SiteURL:   s.baseURL(r) + "/",
+			SelfURL:   s.baseURL(r) + r.URL.RequestURI(),
+			TypeNames: make(map[uint]string),
+		}
+		for _, t := range types {
+			feed.TypeNames[t.ID] = t.Name
+		}
+		var names []string
+		for _, id := range filter.TypeIDs {
+			name, ok := feed.TypeNames[id]
+			if !ok {
+				s.writeDomainErrResponse(w, fmt.Errorf("type %d: %w", id, timeline2.ErrNotFound))
+				return
+			}
+			names = append(names, name)
+		}
+		if len(names) > 0 {
+			feed.Title += ": " + strings.Join(names, ", ")
+		}
+
+		feed.Events, err = s.eventService.ListRecentEvents(ctx, filter, limit)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+
+		var (
+			body        []byte
+			contentType string
+		)
+		switch format {
+		case feedAtom:
+			body, err = codec.EncodeAtomFeed(feed)
+			contentType = "application/atom+xml; charset=utf-8"
+		default:
+			body, err = codec.EncodeRSSFeed(feed)
+			contentType = "application/rss+xml; charset=utf-8"
+		}
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		s.writeCacheable(w, r, collectionETag(body), feed.Updated(), contentType, body)
+	}
+}
+
+// baseURL returns the scheme and host the site is served at, which absolute links of pages, feeds and exports
+// start with. The Host header is up to clients, so it is only used behind a trusted proxy and unless the base URL
+// is configured.
+func (s *Server) baseURL(r *http.Request) string {
+	if s.config.Site.BaseURL != "" || !s.config.Server.TrustProxyHeaders {
+		return s.siteURL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSiteBaseURL(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Port = "8080"
+	got, err := siteBaseURL(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080", got)
+
+	cfg.Site.BaseURL = "https://timeline.example.com/"
+	got, err = siteBaseURL(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "https://timeline.example.com", got)
+
+	for _, invalid := range []string{"timeline.example.com", "ftp://timeline.example.com", "https://example.com/timeline", "https://example.com/?a=1"} {
+		cfg.Site.BaseURL = invalid
+		_, err = siteBaseURL(cfg)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/events.atom", nil)
+	r.Host = "attacker.example.com"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "proxy.example.com")
+
+	cfg := &config.Config{}
+	cfg.Site.BaseURL = "https://timeline.example.com"
+	s := &Server{config: cfg, siteURL: "https://timeline.example.com"}
+	require.Equal(t, "https://timeline.example.com", s.baseURL(r))
+
+	// the configured URL wins over the headers of trusted proxies
+	cfg.Server.TrustProxyHeaders = true
+	require.Equal(t, "https://timeline.example.com", s.baseURL(r))
+
+	cfg.Site.BaseURL = ""
+	s.siteURL = "http://localhost:8080"
+	require.Equal(t, "https://proxy.example.com", s.baseURL(r))
+
+	cfg.Server.TrustProxyHeaders = false
+	require.Equal(t, "http://localhost:8080", s.baseURL(r))
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getEventFilterFromRequest reads the event listing filters from the query string,
+// e.g. ?tag=apollo&tag=nasa&tag_mode=all&type=2&type=3&from=1960-01-01&to=1969-12-31&q=moon
+func (s *Server) getEventFilterFromRequest(r *http.Request) (timeline.EventFilter, error) {
+	q := r.URL.Query()
+	filter := timeline.EventFilter{
+		Tags:    nonEmpty(q["tag"]),
+		TagMode: timeline.TagMode(q.Get("tag_mode")),
+		Query:   strings.TrimSpace(q.Get("q")),
+	}
+	for _, typeID := range nonEmpty(q["type"]) {
+		id, err := strconv.ParseUint(typeID, 10, 32)
+		if err != nil {
+			return timeline.EventFilter{}, fmt.Errorf("%w: invalid type %q", timeline.ErrInvalid, typeID)
+		}
+		filter.TypeIDs = append(filter.TypeIDs, uint(id))
+	}
+	var err error
+	if filter.From, err = parseFilterTime(q.Get("from"), false); err != nil {
+		return timeline.EventFilter{}, err
+	}
+	if filter.To, err = parseFilterTime(q.Get("to"), true); err != nil {
+		return timeline.EventFilter{}, err
+	}
+	return filter, nil
+}
+
+// parseFilterTime reads a bound of a time range, either a date or an RFC 3339 time.
+// Ends of ranges are inclusive, so a date ending a range stands for the last moment of that day.
+func parseFilterTime(raw string, end bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(timeline.FilterDateLayout, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid time %q, expected a date like 1969-07-20 or an RFC 3339 time", timeline.ErrInvalid, raw)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// nonEmpty drops empty values, which forms send for fields left blank.
+func nonEmpty(values []string) []string {
+	var kept []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
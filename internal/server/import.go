@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *Server) importEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		opts, parseOpts, err := s.getImportOptionsFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.Server.ImportMaxBytes)
+		format, content, err := s.getImportPayload(r)
+		if err != nil {
+			s.writeImportErrResponse(w, err)
+			return
+		}
+		defer content.Close()
+
+		file, err := codec.ParseImport(format, content, parseOpts)
+		if err != nil {
+			s.writeImportErrResponse(w, err)
+			return
+		}
+		report, err := s.importService.ImportEvents(ctx, file, opts)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+
+		resp, err := json.Marshal(codec.HTTPFromImportReport(report))
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		status := http.StatusOK
+		if report.Failed > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if _, err := w.Write(resp); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func (s *Server) getImportOptionsFromRequest(r *http.Request) (timeline2.ImportOptions, codec.ImportParseOptions, error) {
+	query := r.URL.Query()
+	opts := timeline2.ImportOptions{DefaultType: query.Get("default_type")}
+	for name, value := range map[string]*bool{"dry_run": &opts.DryRun, "create_types": &opts.CreateTypes} {
+		if query.Get(name) == "" {
+			continue
+		}
+		v, err := strconv.ParseBool(query.Get(name))
+		if err != nil {
+			return opts, codec.ImportParseOptions{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*value = v
+	}
+
+	var (
+		parseOpts codec.ImportParseOptions
+		err       error
+	)
+	parseOpts.Mapping, err = codec.ParseImportMapping(query["map"])
+	if err != nil {
+		return opts, parseOpts, err
+	}
+	for name, value := range map[string]*time.Time{"from": &parseOpts.Window.From, "until": &parseOpts.Window.Until} {
+		if query.Get(name) == "" {
+			continue
+		}
+		if *value, err = codec.ParseImportTime(query.Get(name)); err != nil {
+			return opts, parseOpts, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return opts, parseOpts, nil
+}
+
+// getImportPayload accepts either a multipart form with a "file" or the raw file as the request body.
+// The format is taken from the format query parameter, falling back to the content type.
+func (s *Server) getImportPayload(r *http.Request) (string, io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	content := r.Body
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return "", nil, fmt.Errorf("cannot read import form file: %w", err)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: cannot read import form file: %v", timeline2.ErrInvalid, err)
+		}
+		content = file
+		mediaType, _, _ = mime.ParseMediaType(header.Header.Get("Content-Type"))
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		switch mediaType {
+		case "text/csv":
+			format = codec.FormatCSV
+		case "application/json":
+			format = codec.FormatJSON
+		case "application/x-ndjson", "application/jsonl":
+			format = codec.FormatNDJSON
+		case "text/calendar":
+			format = codec.FormatICS
+		default:
+			content.Close()
+			return "", nil, fmt.Errorf("%w: cannot tell the import format, set the format parameter", timeline2.ErrInvalid)
+		}
+	}
+	return format, content, nil
+}
+
+func (s *Server) writeImportErrResponse(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		s.writeErrResponse(w, err, http.StatusRequestEntityTooLarge, schema2.ErrTooLarge)
+		return
+	}
+	s.writeDomainErrResponse(w, err)
+}
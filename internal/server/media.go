@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	timeline2 "github.com/kamkali/go-timeline/internal/timeline"
+	"golang.org/x/net/context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+func (s *Server) uploadEventGraphic() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.Media.MaxUploadBytes)
+		content, err := s.getMediaPayload(r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				s.writeErrResponse(w, err, http.StatusRequestEntityTooLarge, schema2.ErrTooLarge)
+				return
+			}
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		defer content.Close()
+
+		media, err := s.mediaService.UploadEventGraphic(ctx, id, content)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &maxBytesErr), errors.Is(err, timeline2.ErrTooLarge):
+				s.writeErrResponse(w, err, http.StatusRequestEntityTooLarge, schema2.ErrTooLarge)
+			case errors.Is(err, timeline2.ErrInvalidMedia):
+				s.writeErrResponse(w, err, http.StatusUnsupportedMediaType, schema2.ErrUnsupportedMedia)
+			case errors.Is(err, timeline2.ErrNotFound):
+				s.writeErrResponse(w, err, http.StatusNotFound, schema2.ErrNotFound)
+			case errors.Is(err, context.DeadlineExceeded):
+				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
+			default:
+				s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			}
+			return
+		}
+
+		url := timeline2.MediaPath(media.Key)
+		resp, err := json.Marshal(schema2.MediaResponse{Media: &schema2.Media{
+			URL:          url,
+			ThumbnailURL: timeline2.MediaVariantPath(url, timeline2.MediaVariantThumbnail),
+			MediumURL:    timeline2.MediaVariantPath(url, timeline2.MediaVariantMedium),
+			ContentType:  media.ContentType,
+			Size:         media.Size,
+		}})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if _, err := w.Write(resp); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func (s *Server) serveMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		key := mux.Vars(r)["key"]
+
+		media, content, err := s.mediaService.GetMedia(ctx, key)
+		if err != nil {
+			if errors.Is(err, timeline2.ErrNotFound) {
+				s.writeErrResponse(w, err, http.StatusNotFound, schema2.ErrNotFound)
+				return
+			}
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		defer content.Close()
+
+		etag := `"` + media.Checksum + `"`
+		w.Header().Set("Cache-Control", mediaCacheControl)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", media.CreatedAt.UTC().Format(http.TimeFormat))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", media.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(media.Size, 10))
+		w.WriteHeader(http.StatusOK)
+		if _, err := io.Copy(w, content); err != nil {
+			s.log.Error(fmt.Errorf("cannot write media: %w", err).Error())
+			return
+		}
+	}
+}
+
+// getMediaPayload accepts either a multipart form with a "graphic" file or the raw file as the request body.
+func (s *Server) getMediaPayload(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	file, _, err := r.FormFile("graphic")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read graphic form file: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"bytes"
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadEventGraphicTooLarge(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Media.MaxUploadBytes = 1024
+	s := &Server{config: cfg, log: zap.NewNop(), mediaService: mocks.NewMediaService(t)}
+
+	// the form is parsed before the service is called
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("graphic", "apollo.png")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte{0}, 4096))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/events/1/graphic", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	r = mux.SetURLVars(r, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	s.uploadEventGraphic()(w, r)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var errNotMergePatch = errors.New("expected " + mergePatchContentType + " content")
+
+// getMergePatch reads an RFC 7396 merge patch from the request body.
+func (s *Server) getMergePatch(r *http.Request) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergePatchContentType && mediaType != "application/json" {
+		return nil, fmt.Errorf("%w, got %q", errNotMergePatch, mediaType)
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read body")
+	}
+	return patch, nil
+}
+
+func (s *Server) writeMergePatchErrResponse(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotMergePatch) {
+		w.Header().Set("Accept-Patch", mergePatchContentType)
+		s.writeErrResponse(w, err, http.StatusUnsupportedMediaType, schema2.ErrUnsupportedMedia)
+		return
+	}
+	s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+}
+
+// applyMergePatch patches the JSON representation of current and decodes the result into patched.
+func applyMergePatch(current interface{}, patch []byte, patched interface{}) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("cannot marshal current representation: %w", err)
+	}
+	merged, err := codec.MergePatch(doc, patch)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, patched); err != nil {
+		return fmt.Errorf("patched representation is invalid: %w", err)
+	}
+	return nil
+}
+
+func requireFields(body []byte, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	missing, err := codec.MissingFields(body, fields...)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal body")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("incomplete representation, missing %s; use PATCH for partial updates", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"golang.org/x/net/context"
+	"net/http"
+)
+
+func (s *Server) listEventRelations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		related, err := s.getRelatedEvents(ctx, id)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		resp, err := json.Marshal(schema2.RelatedEventsResponse{Related: related})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(resp); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func (s *Server) createEventRelation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		var relation schema2.Relation
+		if err := s.getJSONPayload(r, &relation); err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		domainRelation, err := codec.HTTPToDomainRelation(id, &relation)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		created, err := s.relationService.CreateRelation(ctx, domainRelation)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		resp, err := json.Marshal(schema2.RelationCreatedResponse{RelationID: created})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if _, err := w.Write(resp); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func (s *Server) deleteEventRelation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		relationID, err := s.getUintVarFromRequest(r, "relation_id")
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		if err := s.relationService.DeleteRelation(ctx, id, relationID); err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) getRelatedEvents(ctx context.Context, eventID uint) ([]*schema2.RelatedEvent, error) {
+	related, err := s.relationService.ListRelatedEvents(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	httpRelated := []*schema2.RelatedEvent{}
+	for i := range related {
+		httpRelatedEvent, err := codec.HTTPFromDomainRelatedEvent(eventID, &related[i])
+		if err != nil {
+			return nil, err
+		}
+		httpRelated = append(httpRelated, httpRelatedEvent)
+	}
+	return httpRelated, nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListEventRelations(t *testing.T) {
+	relationService := mocks.NewRelationService(t)
+	s := &Server{config: &config.Config{}, log: zap.NewNop(), relationService: relationService}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/events/{id:[0-9]+}/relations", s.listEventRelations())
+
+	relationService.On("ListRelatedEvents", mock.Anything, uint(1)).Return([]timeline.RelatedEvent{}, nil)
+	relationService.On("ListRelatedEvents", mock.Anything, uint(2)).Return(nil, timeline.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/events/1/relations", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"related":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/events/2/relations", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,24 +1,148 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/generator"
 	"github.com/kamkali/go-timeline/internal/server/schema"
+	"github.com/kamkali/go-timeline/internal/timeline"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 func (s *Server) renderTimeline() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := s.getEventFilterFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema.ErrBadRequest)
+			return
+		}
+		s.writeSite(w, r, filter, generator.SiteOptions{
+			Links: generator.SiteLinks{EventPages: true, BaseURL: s.baseURL(r)},
+		})
+	}
+}
+
+// writeSite renders the page of the events matching the filter, in the layout and theme the request asks for.
+func (s *Server) writeSite(w http.ResponseWriter, r *http.Request, filter timeline.EventFilter, opts generator.SiteOptions) {
+	ctx := r.Context()
+	layout, err := generator.ParseLayout(r.URL.Query().Get("group"), r.URL.Query().Get("order"))
+	if err != nil {
+		s.writeErrResponse(w, err, http.StatusBadRequest, schema.ErrBadRequest)
+		return
+	}
+	// ?event=42 opens an event, links shared from the page point to it along with its #event42 anchor
+	if raw := r.URL.Query().Get("event"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			s.writeErrResponse(w, fmt.Errorf("invalid event %q", raw), http.StatusBadRequest, schema.ErrBadRequest)
+			return
+		}
+		opts.OpenEvent = uint(id)
+	}
+
+	events, err := s.eventService.ListEvents(ctx, filter)
+	if err != nil {
+		if errors.Is(err, timeline.ErrInvalid) {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema.ErrBadRequest)
+			return
+		}
+		s.writeErrResponse(w, err, http.StatusInternalServerError, schema.ErrInternal)
+		return
+	}
+
+	relations, err := s.relationService.ListRelations(ctx)
+	if err != nil {
+		s.writeErrResponse(w, err, http.StatusInternalServerError, schema.ErrInternal)
+		return
+	}
+
+	types, err := s.typeService.ListTypes(ctx)
+	if err != nil {
+		s.writeErrResponse(w, err, http.StatusInternalServerError, schema.ErrInternal)
+		return
+	}
+
+	opts.Filter, opts.Relations, opts.Types, opts.Layout = filter, relations, types, layout
+	opts.Theme = r.URL.Query().Get("theme")
+	site, err := s.renderer.RenderSite(events, opts)
+	if err != nil {
+		if errors.Is(err, timeline.ErrInvalid) {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema.ErrBadRequest)
+			return
+		}
+		s.writeErrResponse(w, err, http.StatusInternalServerError, schema.ErrInternal)
+		return
+	}
+	w.Write(site)
+}
+
+// renderEventPage renders the permalink page of an event.
+func (s *Server) renderEventPage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		events, err := s.eventService.ListEvents(ctx)
+		id, err := s.getIDFromRequest(r)
 		if err != nil {
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema.ErrInternal)
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema.ErrBadRequest)
 			return
 		}
 
-		site, err := s.renderer.RenderSite(events)
+		event, err := s.eventService.GetEvent(ctx, id)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		// links with an outdated or mistyped slug are moved to the page under the event's current name
+		canonical := "/" + generator.EventPagePath(event.ID, event.Name)
+		if r.URL.Path != canonical {
+			target := &url.URL{Path: canonical, RawQuery: r.URL.RawQuery}
+			http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+			return
+		}
+		previous, next, err := s.eventService.GetNeighbourEvents(ctx, event)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		types, err := s.typeService.ListTypes(ctx)
 		if err != nil {
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema.ErrInternal)
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+
+		// the page links to the events right before and after the event
+		events := []timeline.Event{event}
+		if previous != nil {
+			events = append(events, *previous)
+		}
+		if next != nil {
+			events = append(events, *next)
+		}
+		page, err := s.renderer.RenderEvent(event, generator.EventPageOptions{
+			Types:  types,
+			Events: events,
+			Theme:  r.URL.Query().Get("theme"),
+			Links:  generator.SiteLinks{Root: "/", EventPages: true, BaseURL: s.baseURL(r)},
+		})
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		w.Write(page)
+	}
+}
+
+// serveThemeAsset serves the stylesheets, scripts and images of a theme under /themes/{theme}/.
+func (s *Server) serveThemeAsset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		theme := mux.Vars(r)["theme"]
+		assets := s.renderer.ThemeAssets(theme)
+		if assets == nil {
+			http.NotFound(w, r)
 			return
 		}
-		w.Write(site)
+		http.StripPrefix("/themes/"+theme+"/", http.FileServer(http.FS(assets))).ServeHTTP(w, r)
 	}
 }
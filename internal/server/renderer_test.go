@@ -0,0 +1,62 @@
+package server
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/kamkali/go-timeline/internal/generator"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRenderEventPage(t *testing.T) {
+	renderer, err := generator.NewRenderer(generator.RendererOptions{})
+	require.NoError(t, err)
+	eventService := mocks.NewEventService(t)
+	typeService := mocks.NewTypeService(t)
+	s := &Server{
+		config:       &config.Config{},
+		log:          zap.NewNop(),
+		siteURL:      "https://timeline.example.com",
+		eventService: eventService,
+		typeService:  typeService,
+		renderer:     renderer,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/events/{id:[0-9]+}", s.renderEventPage())
+	router.HandleFunc("/events/{id:[0-9]+}-{slug}", s.renderEventPage())
+
+	event := timeline.Event{ID: 42, Name: "Apollo 11", EventTime: time.Date(1969, 7, 16, 13, 32, 0, 0, time.UTC)}
+	eventService.On("GetEvent", mock.Anything, uint(42)).Return(event, nil)
+
+	t.Run("redirects to the current slug", func(t *testing.T) {
+		redirects := map[string]string{
+			"/events/42":                         "/events/42-apollo-11",
+			"/events/42-apollo-10?theme=compact": "/events/42-apollo-11?theme=compact",
+		}
+		for path, location := range redirects {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusMovedPermanently, w.Code, path)
+			require.Equal(t, location, w.Header().Get("Location"))
+		}
+	})
+
+	t.Run("links to the neighbours", func(t *testing.T) {
+		previous := &timeline.Event{ID: 41, Name: "Apollo 10", EventTime: event.EventTime.AddDate(0, -2, 0)}
+		eventService.On("GetNeighbourEvents", mock.Anything, event).Return(previous, nil, nil)
+		typeService.On("ListTypes", mock.Anything).Return([]timeline.Type{}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/42-apollo-11", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "events/41-apollo-10")
+		require.Contains(t, w.Body.String(), "https://timeline.example.com/events/42-apollo-11")
+	})
+}
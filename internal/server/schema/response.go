@@ -6,6 +6,14 @@ const (
 	ErrNotFound     = "Not Found"
 	ErrTimedOut     = "Timed out"
 	ErrUnauthorized = "Unauthorized"
+	ErrTooLarge     = "Payload too large"
+
+	ErrUnsupportedMedia = "Unsupported media type"
+	ErrConflict         = "Conflict"
+
+	ErrPreconditionFailed   = "Precondition failed"
+	ErrPreconditionRequired = "Precondition required"
+	ErrBatchAborted         = "Not applied, the batch was aborted"
 )
 
 type ServerError struct {
@@ -28,11 +36,26 @@ type (
 	EventsResponse struct {
 		Events []*Event `json:"events"`
 	}
+
+	RelatedEventsResponse struct {
+		Related []*RelatedEvent `json:"related"`
+	}
+
+	RelationCreatedResponse struct {
+		RelationID uint `json:"relation_id,omitempty"`
+	}
+
+	SearchResponse struct {
+		Query   string          `json:"query"`
+		Results []*SearchResult `json:"results"`
+	}
 )
 
 type (
 	TypeResponse struct {
 		Type *Type `json:"type"`
+		// EventsPage is set when the type's events were included.
+		EventsPage *Page `json:"events_page,omitempty"`
 	}
 
 	TypesResponse struct {
@@ -43,3 +66,23 @@ type (
 		TypeID uint `json:"type_id,omitempty"`
 	}
 )
+
+type TagsResponse struct {
+	Tags []*Tag `json:"tags"`
+}
+
+type MediaResponse struct {
+	Media *Media `json:"media"`
+}
+
+type BatchResponse struct {
+	Mode    string         `json:"mode"`
+	Results []*BatchResult `json:"results"`
+}
+
+// EmbedSnippetResponse holds the HTML embedding a timeline into other pages.
+type EmbedSnippetResponse struct {
+	URL    string `json:"url"`
+	Script string `json:"script"`
+	IFrame string `json:"iframe"`
+}
@@ -1,19 +1,92 @@
 package schema
 
+import "encoding/json"
+
 type Event struct {
 	ID                  uint   `json:"id,omitempty"`
-	Name                string `json:"name,omitempty"`
+	Name                string `json:"name"`
 	EventTime           string `json:"event_time"`
-	ShortDescription    string `json:"short_description,omitempty"`
-	DetailedDescription string `json:"detailed_description,omitempty"`
-	Graphic             string `json:"graphic,omitempty"`
-	TypeID              uint   `json:"type_id,omitempty"`
+	ShortDescription    string `json:"short_description"`
+	DetailedDescription string `json:"detailed_description"`
+	DescriptionFormat   string `json:"description_format,omitempty"`
+	// DetailedDescriptionHTML is the detailed description rendered to sanitized HTML, it is ignored in requests.
+	DetailedDescriptionHTML string   `json:"detailed_description_html,omitempty"`
+	Graphic                 string   `json:"graphic"`
+	GraphicThumbnail        string   `json:"graphic_thumbnail,omitempty"`
+	GraphicMedium           string   `json:"graphic_medium,omitempty"`
+	TypeID                  uint     `json:"type_id"`
+	Tags                    []string `json:"tags"`
+	ExternalID              string   `json:"external_id,omitempty"`
+	CreatedAt               string   `json:"created_at,omitempty"`
+	UpdatedAt               string   `json:"updated_at,omitempty"`
+
+	Related []*RelatedEvent `json:"related,omitempty"`
+}
+
+const (
+	RelationOutgoing = "outgoing"
+	RelationIncoming = "incoming"
+)
+
+type RelatedEvent struct {
+	RelationID uint   `json:"relation_id"`
+	Kind       string `json:"kind"`
+	Direction  string `json:"direction"`
+	EventID    uint   `json:"event_id"`
+	Name       string `json:"name,omitempty"`
+	EventTime  string `json:"event_time,omitempty"`
+}
+
+type Relation struct {
+	EventID uint   `json:"event_id"`
+	Kind    string `json:"kind"`
+}
+
+type Tag struct {
+	ID         uint   `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	EventCount int    `json:"event_count"`
+}
+
+type TagMerge struct {
+	Into uint `json:"into"`
+}
+
+type SearchResult struct {
+	Event   *Event  `json:"event"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet,omitempty"`
 }
 
 type Type struct {
-	ID    uint   `json:"id,omitempty"`
-	Name  string `json:"name,omitempty"`
-	Color string `json:"color,omitempty"`
+	ID             uint     `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	Color          string   `json:"color"`
+	Icon           string   `json:"icon"`
+	Theme          string   `json:"theme"`
+	DisplayOrder   int      `json:"display_order"`
+	ParentID       *uint    `json:"parent_id"`
+	EffectiveColor string   `json:"effective_color,omitempty"`
+	EventCount     int      `json:"event_count"`
+	FirstEventTime string   `json:"first_event_time,omitempty"`
+	LastEventTime  string   `json:"last_event_time,omitempty"`
+	Children       []*Type  `json:"children,omitempty"`
+	Events         []*Event `json:"events,omitempty"`
+}
+
+// Page describes the slice of a collection that was returned.
+type Page struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+type Media struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	MediumURL    string `json:"medium_url,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Size         int64  `json:"size,omitempty"`
 }
 
 type User struct {
@@ -25,3 +98,85 @@ type User struct {
 type PasswordChange struct {
 	NewPassword string `json:"new_password,omitempty"`
 }
+
+type BatchRequest struct {
+	// Mode is either "atomic" (the default) or "partial".
+	Mode       string            `json:"mode"`
+	Operations []*BatchOperation `json:"operations"`
+}
+
+type BatchOperation struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	ID       uint   `json:"id,omitempty"`
+	// IfMatch is required for updates and deletes, like the If-Match header of single requests.
+	IfMatch string `json:"if_match,omitempty"`
+	// Children is the delete policy for the children of deleted types.
+	Children string `json:"children,omitempty"`
+	// Data is the complete event or type to create or update.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	ID     uint   `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	// Committed is false for dry runs and for imports with failed rows, which are not applied at all.
+	Committed    bool         `json:"committed"`
+	Created      int          `json:"created"`
+	Updated      int          `json:"updated"`
+	Unchanged    int          `json:"unchanged"`
+	Failed       int          `json:"failed"`
+	CreatedTypes []string     `json:"created_types"`
+	Rows         []*ImportRow `json:"rows"`
+}
+
+type ImportRow struct {
+	Line       int    `json:"line"`
+	ExternalID string `json:"external_id,omitempty"`
+	// Action is one of "create", "update" or "unchanged", empty for failed rows.
+	Action  string `json:"action,omitempty"`
+	EventID uint   `json:"event_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ExportType, ExportTag and ExportEvent are the records of exports. Kind is only set in NDJSON exports,
+// which mix all records in a single stream.
+type ExportType struct {
+	Kind         string `json:"kind,omitempty"`
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Color        string `json:"color"`
+	Icon         string `json:"icon"`
+	Theme        string `json:"theme,omitempty"`
+	DisplayOrder int    `json:"display_order"`
+	ParentID     *uint  `json:"parent_id"`
+	// Parent is the name of the parent type, so that imports do not depend on identifiers.
+	Parent string `json:"parent,omitempty"`
+}
+
+type ExportTag struct {
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name"`
+	EventCount int    `json:"event_count"`
+}
+
+type ExportEvent struct {
+	Kind                string   `json:"kind,omitempty"`
+	ID                  uint     `json:"id"`
+	ExternalID          string   `json:"external_id,omitempty"`
+	Name                string   `json:"name"`
+	EventTime           string   `json:"event_time"`
+	ShortDescription    string   `json:"short_description"`
+	DetailedDescription string   `json:"detailed_description"`
+	DescriptionFormat   string   `json:"description_format,omitempty"`
+	Graphic             string   `json:"graphic"`
+	Type                string   `json:"type"`
+	TypeID              uint     `json:"type_id"`
+	Tags                []string `json:"tags"`
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"golang.org/x/net/context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+func (s *Server) searchEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			s.writeErrResponse(w, fmt.Errorf("missing search query"), http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		limit, err := s.getLimitFromRequest(r, defaultSearchLimit, maxSearchLimit)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		results, err := s.eventService.SearchEvents(ctx, query, limit)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
+				return
+			}
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+
+		httpResults := []*schema2.SearchResult{}
+		for i := range results {
+			httpResult, err := codec.HTTPFromDomainSearchResult(&results[i])
+			if err != nil {
+				s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+				return
+			}
+			httpResults = append(httpResults, httpResult)
+		}
+
+		resp, err := json.Marshal(schema2.SearchResponse{Query: query, Results: httpResults})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(resp); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func (s *Server) getLimitFromRequest(r *http.Request, def, max int) (int, error) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return def, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("invalid limit %q", raw)
+	}
+	if limit > max {
+		limit = max
+	}
+	return limit, nil
+}
+
+func (s *Server) getOffsetFromRequest(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("offset")
+	if raw == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(raw)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid offset %q", raw)
+	}
+	return offset, nil
+}
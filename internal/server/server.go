@@ -3,10 +3,12 @@ package server
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/kamkali/go-timeline/internal/auth"
+	"github.com/kamkali/go-timeline/internal/codec"
 	"github.com/kamkali/go-timeline/internal/config"
 	"github.com/kamkali/go-timeline/internal/generator"
 	"github.com/kamkali/go-timeline/internal/server/schema"
@@ -17,9 +19,11 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -27,6 +31,11 @@ import (
 //go:embed static
 var staticFS embed.FS
 
+// StaticAssets returns the stylesheets, scripts and images the rendered pages refer to under static/.
+func StaticAssets() (fs.FS, error) {
+	return fs.Sub(staticFS, "static")
+}
+
 type Server struct {
 	router       *mux.Router
 	config       *config.Config
@@ -36,10 +45,22 @@ type Server struct {
 	log        *zap.Logger
 	jwtManager *auth.JWTManager
 
-	eventService timeline.EventService
-	typeService  timeline.TypeService
-	userService  timeline.UserService
-	renderer     *generator.Renderer
+	eventService    timeline.EventService
+	typeService     timeline.TypeService
+	userService     timeline.UserService
+	mediaService    timeline.MediaService
+	tagService      timeline.TagService
+	relationService timeline.RelationService
+	batchService    timeline.BatchService
+	importService   timeline.ImportService
+	exportService   timeline.ExportService
+	renderer        *generator.Renderer
+	// frameAncestors is the Content-Security-Policy of embedded timelines, naming the sites that may frame them.
+	frameAncestors string
+	// siteURL is the base URL of the site, configured or made of the address the server listens on.
+	siteURL string
+	// pageCache keeps rendered pages and list responses, nil if caching is disabled.
+	pageCache timeline.PageCache
 }
 
 func New(
@@ -49,16 +70,35 @@ func New(
 	eventService timeline.EventService,
 	typesService timeline.TypeService,
 	userService timeline.UserService,
+	mediaService timeline.MediaService,
+	tagService timeline.TagService,
+	relationService timeline.RelationService,
+	batchService timeline.BatchService,
+	importService timeline.ImportService,
+	exportService timeline.ExportService,
+	pageCache timeline.PageCache,
 ) (*Server, error) {
 	r := mux.NewRouter()
-	siteRenderer, err := generator.NewRenderer()
+	siteRenderer, err := generator.NewRenderer(generator.RendererOptions{
+		ThemesDir:    cfg.Site.ThemesDir,
+		DefaultTheme: cfg.Site.Theme,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("cannot instantiate site renderer: %w", err)
 	}
+	frameAncestors, err := generator.FrameAncestors(cfg.Site.EmbedOrigins)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SITE_EMBED_ORIGINS: %w", err)
+	}
+	siteURL, err := siteBaseURL(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SITE_BASE_URL: %w", err)
+	}
 	handler := handlers.CORS(
 		handlers.AllowedOrigins([]string{"http://localhost:3000", "https://apollo11timeline.herokuapp.com"}),
-		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "DELETE", "PUT", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Origin", "Content-Type", "Authorization"}),
+		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "DELETE", "PUT", "PATCH", "OPTIONS"}),
+		handlers.AllowedHeaders([]string{"Origin", "Content-Type", "Authorization", "If-Match", "If-None-Match", "If-Modified-Since"}),
+		handlers.ExposedHeaders([]string{"ETag", "Last-Modified"}),
 		handlers.AllowCredentials(),
 	)(r)
 	s := &Server{
@@ -68,15 +108,24 @@ func New(
 			Addr:    net.JoinHostPort(cfg.Server.Host, cfg.Server.Port),
 			Handler: handler,
 		},
-		log:          log,
-		jwtManager:   manager,
-		eventService: eventService,
-		typeService:  typesService,
-		userService:  userService,
-		renderer:     siteRenderer,
+		log:             log,
+		jwtManager:      manager,
+		eventService:    eventService,
+		typeService:     typesService,
+		userService:     userService,
+		mediaService:    mediaService,
+		tagService:      tagService,
+		relationService: relationService,
+		batchService:    batchService,
+		importService:   importService,
+		exportService:   exportService,
+		renderer:        siteRenderer,
+		frameAncestors:  frameAncestors,
+		siteURL:         siteURL,
+		pageCache:       pageCache,
 	}
 
-	fSys, err := fs.Sub(staticFS, "static")
+	fSys, err := StaticAssets()
 	if err != nil {
 		return nil, err
 	}
@@ -90,12 +139,22 @@ func New(
 func (s *Server) registerRoutes() {
 	{ // public routes
 		s.router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", s.staticServer))
-		s.router.HandleFunc("/", s.renderTimeline()).Methods("GET")
+		s.router.HandleFunc("/", s.withPageCache(sitePageParams, s.renderTimeline())).Methods("GET")
+		// permalinks name the event after its ID, e.g. /events/42-apollo-11, the name is not needed to find it
+		s.router.HandleFunc("/events/{id:[0-9]+}", s.withPageCache([]string{"theme"}, s.renderEventPage())).Methods("GET")
+		s.router.HandleFunc("/events/{id:[0-9]+}-{slug}", s.withPageCache([]string{"theme"}, s.renderEventPage())).Methods("GET")
+		// embedded timelines show all events or the events of a type, e.g. /embed/all or /embed/3
+		s.router.HandleFunc("/embed/{timeline}", s.withPageCache(embedPageParams, s.renderEmbed())).Methods("GET")
+		s.router.HandleFunc("/api/embed", s.embedSnippet()).Methods("GET")
+		s.router.PathPrefix("/themes/{theme}/").HandlerFunc(s.serveThemeAsset()).Methods("GET", "HEAD")
+		s.router.HandleFunc(timeline.MediaPathPrefix+"{key}",
+			s.withTimeout(s.config.Server.TimeoutSeconds, s.serveMedia()),
+		).Methods("GET", "HEAD")
 	}
 
 	{ // Events routes
 		s.router.HandleFunc("/api/events",
-			s.withTimeout(s.config.Server.TimeoutSeconds, s.listEvents()),
+			s.withPageCache(eventFilterParams, s.withTimeout(s.config.Server.TimeoutSeconds, s.listEvents())),
 		).Methods("GET")
 
 		s.router.HandleFunc("/api/events/{id}",
@@ -106,6 +165,10 @@ func (s *Server) registerRoutes() {
 			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.updateEvent())),
 		).Methods("PUT")
 
+		s.router.HandleFunc("/api/events/{id}",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.patchEvent())),
+		).Methods("PATCH")
+
 		s.router.HandleFunc("/api/events/{id}",
 			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.deleteEvent())),
 		).Methods("DELETE")
@@ -113,11 +176,31 @@ func (s *Server) registerRoutes() {
 		s.router.HandleFunc("/api/events",
 			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.createEvent())),
 		).Methods("POST")
+
+		s.router.HandleFunc("/api/events/{id}/relations",
+			s.withTimeout(s.config.Server.TimeoutSeconds, s.listEventRelations()),
+		).Methods("GET")
+
+		s.router.HandleFunc("/api/events/{id}/relations",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.createEventRelation())),
+		).Methods("POST")
+
+		s.router.HandleFunc("/api/events/{id}/relations/{relation_id}",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.deleteEventRelation())),
+		).Methods("DELETE")
+
+		s.router.HandleFunc("/api/search",
+			s.withTimeout(s.config.Server.TimeoutSeconds, s.searchEvents()),
+		).Methods("GET")
+
+		s.router.HandleFunc("/api/events/{id}/graphic",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.uploadEventGraphic())),
+		).Methods("POST")
 	}
 
 	{ // Types routes
 		s.router.HandleFunc("/api/types",
-			s.withTimeout(s.config.Server.TimeoutSeconds, s.listTypes()),
+			s.withPageCache([]string{"tree"}, s.withTimeout(s.config.Server.TimeoutSeconds, s.listTypes())),
 		).Methods("GET")
 
 		s.router.HandleFunc("/api/types/{id}",
@@ -128,6 +211,10 @@ func (s *Server) registerRoutes() {
 			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.updateType())),
 		).Methods("PUT")
 
+		s.router.HandleFunc("/api/types/{id}",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.patchType())),
+		).Methods("PATCH")
+
 		s.router.HandleFunc("/api/types/{id}",
 			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.deleteType())),
 		).Methods("DELETE")
@@ -137,6 +224,50 @@ func (s *Server) registerRoutes() {
 		).Methods("POST")
 	}
 
+	{ // Tags routes
+		s.router.HandleFunc("/api/tags",
+			s.withPageCache(nil, s.withTimeout(s.config.Server.TimeoutSeconds, s.listTags())),
+		).Methods("GET")
+
+		s.router.HandleFunc("/api/tags/{id}",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.renameTag())),
+		).Methods("PUT")
+
+		s.router.HandleFunc("/api/tags/{id}/merge",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.mergeTag())),
+		).Methods("POST")
+	}
+
+	{ // Batch routes
+		s.router.HandleFunc("/api/batch",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.executeBatch())),
+		).Methods("POST")
+	}
+
+	{ // Import, export and feed routes
+		s.router.HandleFunc("/api/import",
+			s.withAuth(s.withTimeout(s.config.Server.TimeoutSeconds, s.importEvents())),
+		).Methods("POST")
+
+		// exports stream the whole timeline, they get a timeout of their own as they take longer than other requests
+		s.router.HandleFunc("/api/export",
+			s.withTimeout(s.config.Server.ExportTimeoutSeconds, s.exportTimeline("")),
+		).Methods("GET")
+		s.router.HandleFunc("/api/events.ics",
+			s.withTimeout(s.config.Server.ExportTimeoutSeconds, s.exportTimeline(codec.FormatICS)),
+		).Methods("GET")
+		// TimelineJS embeds load their document from a URL
+		s.router.HandleFunc("/api/timelinejs.json",
+			s.withTimeout(s.config.Server.ExportTimeoutSeconds, s.exportTimeline(codec.FormatTimelineJS)),
+		).Methods("GET")
+		s.router.HandleFunc("/api/events.atom",
+			s.withTimeout(s.config.Server.TimeoutSeconds, s.getFeed(feedAtom)),
+		).Methods("GET")
+		s.router.HandleFunc("/api/events.rss",
+			s.withTimeout(s.config.Server.TimeoutSeconds, s.getFeed(feedRSS)),
+		).Methods("GET")
+	}
+
 	{ // User routes
 		s.router.HandleFunc("/api/login",
 			s.withTimeout(s.config.Server.TimeoutSeconds, s.login()),
@@ -192,11 +323,66 @@ func (s *Server) writeErrResponse(w http.ResponseWriter, err error, code int, de
 	}
 }
 
+// writeDomainErrResponse maps errors returned by the timeline services to HTTP responses.
+func (s *Server) writeDomainErrResponse(w http.ResponseWriter, err error) {
+	code, desc := domainErrStatus(err)
+	s.writeErrResponse(w, err, code, desc)
+}
+
+// domainErrStatus maps errors of the domain to HTTP status codes and their descriptions.
+func domainErrStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, timeline.ErrNotFound):
+		return http.StatusNotFound, schema.ErrNotFound
+	case errors.Is(err, timeline.ErrConflict):
+		return http.StatusConflict, schema.ErrConflict
+	case errors.Is(err, timeline.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, schema.ErrPreconditionFailed
+	case errors.Is(err, timeline.ErrInvalid):
+		return http.StatusBadRequest, schema.ErrBadRequest
+	case errors.Is(err, timeline.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge, schema.ErrTooLarge
+	case errors.Is(err, timeline.ErrInvalidMedia):
+		return http.StatusUnsupportedMediaType, schema.ErrUnsupportedMedia
+	case errors.Is(err, timeline.ErrBatchAborted):
+		return http.StatusFailedDependency, schema.ErrBatchAborted
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusRequestTimeout, schema.ErrTimedOut
+	default:
+		return http.StatusInternalServerError, schema.ErrInternal
+	}
+}
+
+// siteBaseURL returns the configured base URL without a trailing slash, or the address the server listens on.
+func siteBaseURL(cfg *config.Config) (string, error) {
+	if cfg.Site.BaseURL == "" {
+		host := cfg.Server.Host
+		if host == "" {
+			host = "localhost"
+		}
+		return "http://" + net.JoinHostPort(host, cfg.Server.Port), nil
+	}
+	u, err := url.Parse(cfg.Site.BaseURL)
+	switch {
+	case err != nil:
+		return "", err
+	case u.Scheme != "http" && u.Scheme != "https", u.Host == "":
+		return "", fmt.Errorf("%q is not an absolute HTTP URL", cfg.Site.BaseURL)
+	case strings.Trim(u.Path, "/") != "", u.RawQuery != "", u.Fragment != "":
+		return "", fmt.Errorf("%q has a path, the site is served at the root", cfg.Site.BaseURL)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
 func (s *Server) getIDFromRequest(r *http.Request) (uint, error) {
+	return s.getUintVarFromRequest(r, "id")
+}
+
+func (s *Server) getUintVarFromRequest(r *http.Request, name string) (uint, error) {
 	vars := mux.Vars(r)
-	id, ok := vars["id"]
+	id, ok := vars[name]
 	if !ok {
-		return 0, fmt.Errorf("invalid id")
+		return 0, fmt.Errorf("invalid %s", name)
 	}
 	parseInt, err := strconv.ParseInt(id, 10, 32)
 	if err != nil {
@@ -0,0 +1,19 @@
+/*
+* Inserts an embedded timeline where the script is included, e.g.
+* <script src="https://example.com/static/js/embed.js" data-timeline="https://example.com/embed/all?height=600" data-height="600" async></script>
+* Snippets are generated by /api/embed.
+*/
+;(function() {
+    var script = document.currentScript;
+    if (!script || !script.getAttribute("data-timeline")) {
+        return;
+    }
+    var frame = document.createElement("iframe");
+    frame.src = script.getAttribute("data-timeline");
+    frame.title = script.getAttribute("data-title") || "Timeline";
+    frame.width = "100%";
+    frame.height = script.getAttribute("data-height") || "600";
+    frame.style.border = "0";
+    frame.setAttribute("loading", "lazy");
+    script.parentNode.insertBefore(frame, script);
+})();
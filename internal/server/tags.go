@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/codec"
+	schema2 "github.com/kamkali/go-timeline/internal/server/schema"
+	"golang.org/x/net/context"
+	"io"
+	"net/http"
+)
+
+func (s *Server) listTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tags, err := s.tagService.ListTags(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
+				return
+			}
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+
+		httpTags := []*schema2.Tag{}
+		for i := range tags {
+			httpTag, err := codec.HTTPFromDomainTag(&tags[i])
+			if err != nil {
+				s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+				return
+			}
+			httpTags = append(httpTags, httpTag)
+		}
+		tagsResponse, err := json.Marshal(schema2.TagsResponse{Tags: httpTags})
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(tagsResponse); err != nil {
+			s.log.Error("cannot write response")
+			return
+		}
+	}
+}
+
+func (s *Server) renameTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		var tag schema2.Tag
+		if err := s.getJSONPayload(r, &tag); err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		if err := s.tagService.RenameTag(ctx, id, tag.Name); err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) mergeTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		var merge schema2.TagMerge
+		if err := s.getJSONPayload(r, &merge); err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+
+		if err := s.tagService.MergeTags(ctx, id, merge.Into); err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) getJSONPayload(r *http.Request, v any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read body")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("cannot unmarshal body")
+	}
+	return nil
+}
@@ -10,6 +10,8 @@ import (
 	"golang.org/x/net/context"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (s *Server) getType() http.HandlerFunc {
@@ -21,33 +23,33 @@ func (s *Server) getType() http.HandlerFunc {
 			return
 		}
 
-		dt, err := s.typeService.GetType(ctx, id)
+		opts, err := s.getTypeOptionsFromRequest(r)
 		if err != nil {
-			if errors.Is(err, timeline2.ErrNotFound) {
-				s.writeErrResponse(w, err, http.StatusNotFound, schema2.ErrNotFound)
-				return
-			}
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		dt, err := s.typeService.GetType(ctx, id, opts)
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
 
 		httpType, err := codec.HTTPFromDomainType(&dt)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
-		typeResponse, err := json.Marshal(schema2.TypeResponse{Type: httpType})
+		resp := schema2.TypeResponse{Type: httpType}
+		if opts.IncludeEvents {
+			resp.EventsPage = &schema2.Page{Limit: opts.EventsLimit, Offset: opts.EventsOffset, Total: dt.EventCount}
+		}
+		typeResponse, err := json.Marshal(resp)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
-		if _, err := w.Write(typeResponse); err != nil {
-			s.log.Error("cannot write response")
-			return
-		}
+		s.writeCacheableJSON(w, r, entityETag(dt.Revision, typeResponse), typeResponse)
 	}
 }
 
@@ -55,26 +57,77 @@ func (s *Server) updateType() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		domainType, err := s.getTypePayload(r)
+		id, err := s.getIDFromRequest(r)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
 			return
 		}
+		revision, err := s.getIfMatchRevision(r)
+		if err != nil {
+			s.writePreconditionErrResponse(w, err)
+			return
+		}
 
-		id, err := s.getIDFromRequest(r)
+		domainType, err := s.getTypePayload(r, typeFields...)
 		if err != nil {
 			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
 			return
 		}
+		domainType.Revision = revision
 
 		if err := s.typeService.UpdateType(ctx, id, domainType); err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
-				return
-			}
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) patchType() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := s.getIDFromRequest(r)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		revision, err := s.getIfMatchRevision(r)
+		if err != nil {
+			s.writePreconditionErrResponse(w, err)
+			return
+		}
+		patch, err := s.getMergePatch(r)
+		if err != nil {
+			s.writeMergePatchErrResponse(w, err)
+			return
+		}
+
+		dt, err := s.typeService.GetType(ctx, id, timeline2.TypeOptions{})
+		if err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
+		current, err := codec.HTTPFromDomainType(&dt)
+		if err != nil {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
+		var patched schema2.Type
+		if err := applyMergePatch(current, patch, &patched); err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		domainType, err := codec.HTTPToDomainType(&patched)
+		if err != nil {
+			s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+			return
+		}
+		domainType.Revision = revision
+
+		if err := s.typeService.UpdateType(ctx, id, domainType); err != nil {
+			s.writeDomainErrResponse(w, err)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	}
 }
@@ -88,12 +141,15 @@ func (s *Server) deleteType() http.HandlerFunc {
 			return
 		}
 
-		if err := s.typeService.DeleteType(ctx, id); err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
-				return
-			}
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+		revision, err := s.getIfMatchRevision(r)
+		if err != nil {
+			s.writePreconditionErrResponse(w, err)
+			return
+		}
+
+		policy := timeline2.TypeDeletePolicy(r.URL.Query().Get("children"))
+		if err := s.typeService.DeleteType(ctx, id, policy, revision); err != nil {
+			s.writeDomainErrResponse(w, err)
 			return
 		}
 
@@ -105,7 +161,19 @@ func (s *Server) listTypes() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		types, err := s.typeService.ListTypes(ctx)
+		listTypes := s.typeService.ListTypes
+		if tree := r.URL.Query().Get("tree"); tree != "" {
+			asTree, err := strconv.ParseBool(tree)
+			if err != nil {
+				s.writeErrResponse(w, err, http.StatusBadRequest, schema2.ErrBadRequest)
+				return
+			}
+			if asTree {
+				listTypes = s.typeService.ListTypeTree
+			}
+		}
+
+		types, err := listTypes(ctx)
 		if err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
 				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
@@ -115,8 +183,6 @@ func (s *Server) listTypes() http.HandlerFunc {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
 		var httpTypes []*schema2.Type
 		for i := range types {
 			httpType, err := codec.HTTPFromDomainType(&types[i])
@@ -131,10 +197,7 @@ func (s *Server) listTypes() http.HandlerFunc {
 			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
 			return
 		}
-		if _, err := w.Write(typesResponse); err != nil {
-			s.log.Error("cannot write response")
-			return
-		}
+		s.writeCacheableJSON(w, r, collectionETag(typesResponse), typesResponse)
 	}
 }
 
@@ -150,11 +213,7 @@ func (s *Server) createType() http.HandlerFunc {
 
 		created, err := s.typeService.CreateType(ctx, domainType)
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				s.writeErrResponse(w, err, http.StatusRequestTimeout, schema2.ErrTimedOut)
-				return
-			}
-			s.writeErrResponse(w, err, http.StatusInternalServerError, schema2.ErrInternal)
+			s.writeDomainErrResponse(w, err)
 			return
 		}
 
@@ -173,11 +232,48 @@ func (s *Server) createType() http.HandlerFunc {
 	}
 }
 
-func (s *Server) getTypePayload(r *http.Request) (*timeline2.Type, error) {
+const (
+	defaultTypeEventsLimit = 20
+	maxTypeEventsLimit     = 100
+)
+
+// getTypeOptionsFromRequest reads e.g. ?include=events&limit=10&offset=20
+func (s *Server) getTypeOptionsFromRequest(r *http.Request) (timeline2.TypeOptions, error) {
+	var opts timeline2.TypeOptions
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "":
+		case "events":
+			opts.IncludeEvents = true
+		default:
+			return timeline2.TypeOptions{}, fmt.Errorf("cannot include %q", include)
+		}
+	}
+	if !opts.IncludeEvents {
+		return opts, nil
+	}
+
+	var err error
+	if opts.EventsLimit, err = s.getLimitFromRequest(r, defaultTypeEventsLimit, maxTypeEventsLimit); err != nil {
+		return timeline2.TypeOptions{}, err
+	}
+	if opts.EventsOffset, err = s.getOffsetFromRequest(r); err != nil {
+		return timeline2.TypeOptions{}, err
+	}
+	return opts, nil
+}
+
+// typeFields are the writable fields of a type, all of which a full replacement has to provide.
+var typeFields = []string{"name", "color", "icon", "theme", "display_order", "parent_id"}
+
+func (s *Server) getTypePayload(r *http.Request, required ...string) (*timeline2.Type, error) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read body")
 	}
+	if err := requireFields(body, required); err != nil {
+		return nil, err
+	}
 	var dt schema2.Type
 	if err := json.Unmarshal(body, &dt); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal body")
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/kamkali/go-timeline/internal/config"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpdateType(t *testing.T) {
+	typeService := mocks.NewTypeService(t)
+	s := &Server{config: &config.Config{}, log: zap.NewNop(), typeService: typeService}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/types/{id:[0-9]+}", s.updateType())
+
+	typeService.On("UpdateType", mock.Anything, uint(3), mock.MatchedBy(func(dt *timeline.Type) bool {
+		return dt.Name == "Missions" && dt.Theme == "compact" && dt.Revision == 2
+	})).Return(nil).Once()
+
+	put := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPut, "/api/types/3", strings.NewReader(body))
+		r.Header.Set("If-Match", `"2"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := put(`{"name":"Missions","color":"#ff0000","icon":"rocket","theme":"compact","display_order":1,"parent_id":null}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = put(`{"name":"Missions","color":"#ff0000","icon":"rocket","display_order":1,"parent_id":null}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
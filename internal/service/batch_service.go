@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+type BatchService struct {
+	log *zap.Logger
+
+	maxSize  int
+	graphics MediaLimits
+	tx       timeline.Transactor
+}
+
+// ExecuteBatch applies the operations in order within a single transaction. Results are reported
+// per operation; for a failed atomic batch the error of the failing operation is returned as well.
+func (b BatchService) ExecuteBatch(ctx context.Context, mode timeline.BatchMode, ops []timeline.BatchOperation) ([]timeline.BatchResult, error) {
+	switch mode {
+	case "":
+		mode = timeline.BatchAtomic
+	case timeline.BatchAtomic, timeline.BatchPartial:
+	default:
+		return nil, fmt.Errorf("%w: unknown batch mode %q", timeline.ErrInvalid, mode)
+	}
+	if len(ops) > b.maxSize {
+		return nil, fmt.Errorf("%w: batch of %d operations exceeds the limit of %d", timeline.ErrTooLarge, len(ops), b.maxSize)
+	}
+
+	results := make([]timeline.BatchResult, len(ops))
+	err := b.tx.InTransaction(ctx, func(repos timeline.Repositories) error {
+		for i, op := range ops {
+			if mode == timeline.BatchAtomic {
+				results[i] = b.apply(ctx, repos, op)
+				if results[i].Err != nil {
+					return fmt.Errorf("operation %d: %w", i, results[i].Err)
+				}
+				continue
+			}
+			// a savepoint per operation lets the transaction carry on after a failed statement
+			_ = repos.Tx.InTransaction(ctx, func(item timeline.Repositories) error {
+				results[i] = b.apply(ctx, item, op)
+				return results[i].Err
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = timeline.ErrBatchAborted
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+// apply runs the operation through the regular services, so that batches are validated like single requests.
+func (b BatchService) apply(ctx context.Context, repos timeline.Repositories, op timeline.BatchOperation) timeline.BatchResult {
+	events := NewEventService(b.log, b.graphics, repos.Events)
+	types := NewTypeService(b.log, repos.Types)
+
+	var (
+		id  = op.ID
+		err error
+	)
+	switch {
+	case op.Resource == timeline.BatchEvent && op.Event != nil && op.Action == timeline.BatchCreate:
+		id, err = events.CreateEvent(ctx, op.Event)
+	case op.Resource == timeline.BatchEvent && op.Event != nil && op.Action == timeline.BatchUpdate:
+		op.Event.Revision = op.Revision
+		err = events.UpdateEvent(ctx, op.ID, op.Event)
+	case op.Resource == timeline.BatchEvent && op.Action == timeline.BatchDelete:
+		err = events.DeleteEvent(ctx, op.ID, op.Revision)
+	case op.Resource == timeline.BatchType && op.Type != nil && op.Action == timeline.BatchCreate:
+		id, err = types.CreateType(ctx, op.Type)
+	case op.Resource == timeline.BatchType && op.Type != nil && op.Action == timeline.BatchUpdate:
+		op.Type.Revision = op.Revision
+		err = types.UpdateType(ctx, op.ID, op.Type)
+	case op.Resource == timeline.BatchType && op.Action == timeline.BatchDelete:
+		err = types.DeleteType(ctx, op.ID, op.Policy, op.Revision)
+	default:
+		err = fmt.Errorf("%w: cannot %s %s", timeline.ErrInvalid, op.Action, op.Resource)
+	}
+	return timeline.BatchResult{ID: id, Err: err}
+}
+
+func NewBatchService(log *zap.Logger, maxSize int, graphics MediaLimits, tx timeline.Transactor) *BatchService {
+	return &BatchService{log: log, maxSize: maxSize, graphics: graphics, tx: tx}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"testing"
+	"time"
+)
+
+func newTestTransactor(t *testing.T, repos *timeline.Repositories) *mocks.Transactor {
+	tx := mocks.NewTransactor(t)
+	repos.Tx = tx
+	tx.On("InTransaction", mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(repos timeline.Repositories) error) error {
+			return fn(*repos)
+		}).
+		Maybe()
+	return tx
+}
+
+func TestExecuteBatch(t *testing.T) {
+	ctx := context.Background()
+	eventTime := time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC)
+	ops := []timeline.BatchOperation{
+		{Action: timeline.BatchCreate, Resource: timeline.BatchEvent, Event: &timeline.Event{Name: "Apollo 11", EventTime: eventTime, TypeID: 1}},
+		{Action: timeline.BatchDelete, Resource: timeline.BatchEvent, ID: 5, Revision: 2},
+		{Action: timeline.BatchDelete, Resource: timeline.BatchType, ID: 3},
+	}
+
+	t.Run("atomic batch aborts on the first failure", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		repos := timeline.Repositories{Events: events, Types: mocks.NewTypeRepository(t)}
+		batchService := NewBatchService(nil, 10, MediaLimits{}, newTestTransactor(t, &repos))
+
+		events.On("CreateEvent", ctx, mock.Anything).Return(uint(7), nil).Once()
+		events.On("DeleteEvent", ctx, uint(5), uint(2)).Return(timeline.ErrPreconditionFailed).Once()
+
+		results, err := batchService.ExecuteBatch(ctx, "", ops)
+		require.ErrorIs(t, err, timeline.ErrPreconditionFailed)
+		require.Len(t, results, 3)
+		require.ErrorIs(t, results[0].Err, timeline.ErrBatchAborted)
+		require.ErrorIs(t, results[1].Err, timeline.ErrPreconditionFailed)
+		require.ErrorIs(t, results[2].Err, timeline.ErrBatchAborted)
+	})
+
+	t.Run("partial batch reports failures per operation", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		types := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: types}
+		batchService := NewBatchService(nil, 10, MediaLimits{}, newTestTransactor(t, &repos))
+
+		events.On("CreateEvent", ctx, mock.Anything).Return(uint(7), nil).Once()
+		events.On("DeleteEvent", ctx, uint(5), uint(2)).Return(timeline.ErrNotFound).Once()
+		types.On("DeleteType", ctx, uint(3), timeline.TypeDeleteRestrict, uint(0)).Return(nil).Once()
+
+		results, err := batchService.ExecuteBatch(ctx, timeline.BatchPartial, ops)
+		require.NoError(t, err)
+		require.Equal(t, uint(7), results[0].ID)
+		require.NoError(t, results[0].Err)
+		require.ErrorIs(t, results[1].Err, timeline.ErrNotFound)
+		require.NoError(t, results[2].Err)
+	})
+
+	t.Run("operations are validated like single requests", func(t *testing.T) {
+		repos := timeline.Repositories{Events: mocks.NewEventRepository(t), Types: mocks.NewTypeRepository(t)}
+		batchService := NewBatchService(nil, 10, MediaLimits{}, newTestTransactor(t, &repos))
+
+		results, err := batchService.ExecuteBatch(ctx, timeline.BatchPartial, []timeline.BatchOperation{
+			{Action: timeline.BatchCreate, Resource: timeline.BatchEvent, Event: &timeline.Event{Name: " "}},
+			{Action: timeline.BatchUpdate, Resource: timeline.BatchType, ID: 1},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, results[0].Err, timeline.ErrInvalid)
+		require.ErrorIs(t, results[1].Err, timeline.ErrInvalid)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		batchService := NewBatchService(nil, 2, MediaLimits{}, mocks.NewTransactor(t))
+
+		_, err := batchService.ExecuteBatch(ctx, timeline.BatchAtomic, ops)
+		require.ErrorIs(t, err, timeline.ErrTooLarge)
+
+		_, err = batchService.ExecuteBatch(ctx, "best-effort", ops[:1])
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
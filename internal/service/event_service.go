@@ -1,13 +1,18 @@
 package service
 
 import (
+	"fmt"
 	"github.com/kamkali/go-timeline/internal/timeline"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
+	"net/url"
+	"strings"
 )
 
 type EventService struct {
 	log *zap.Logger
+	// graphics bounds graphics embedded in events as data URIs, as uploads are.
+	graphics MediaLimits
 
 	repo timeline.EventRepository
 }
@@ -16,22 +21,121 @@ func (t EventService) GetEvent(ctx context.Context, id uint) (timeline.Event, er
 	return t.repo.GetEvent(ctx, id)
 }
 
+func (t EventService) GetNeighbourEvents(ctx context.Context, event timeline.Event) (*timeline.Event, *timeline.Event, error) {
+	return t.repo.GetNeighbourEvents(ctx, event)
+}
+
 func (t EventService) UpdateEvent(ctx context.Context, id uint, event *timeline.Event) error {
+	if err := validateEvent(event, t.graphics); err != nil {
+		return err
+	}
+	event.Tags = normalizeTags(event.Tags)
 	return t.repo.UpdateEvent(ctx, id, event)
 }
 
-func (t EventService) DeleteEvent(ctx context.Context, id uint) error {
-	return t.repo.DeleteEvent(ctx, id)
+func (t EventService) DeleteEvent(ctx context.Context, id uint, revision uint) error {
+	return t.repo.DeleteEvent(ctx, id, revision)
 }
 
 func (t EventService) CreateEvent(ctx context.Context, event *timeline.Event) (uint, error) {
+	if err := validateEvent(event, t.graphics); err != nil {
+		return 0, err
+	}
+	event.Tags = normalizeTags(event.Tags)
 	return t.repo.CreateEvent(ctx, event)
 }
 
-func (t EventService) ListEvents(ctx context.Context) ([]timeline.Event, error) {
-	return t.repo.ListEvents(ctx)
+func (t EventService) ListEvents(ctx context.Context, filter timeline.EventFilter) ([]timeline.Event, error) {
+	filter, err := normalizeEventFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return t.repo.ListEvents(ctx, filter)
+}
+
+func (t EventService) ListRecentEvents(ctx context.Context, filter timeline.EventFilter, limit int) ([]timeline.Event, error) {
+	filter, err := normalizeEventFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return t.repo.ListRecentEvents(ctx, filter, limit)
+}
+
+func normalizeEventFilter(filter timeline.EventFilter) (timeline.EventFilter, error) {
+	filter.Tags = normalizeTags(filter.Tags)
+	switch filter.TagMode {
+	case "":
+		filter.TagMode = timeline.TagModeAny
+	case timeline.TagModeAny, timeline.TagModeAll:
+	default:
+		return timeline.EventFilter{}, fmt.Errorf("%w: unknown tag mode %q", timeline.ErrInvalid, filter.TagMode)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return timeline.EventFilter{}, fmt.Errorf("%w: time range ends before it starts", timeline.ErrInvalid)
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	return filter, nil
+}
+
+func (t EventService) SearchEvents(ctx context.Context, query string, limit int) ([]timeline.SearchResult, error) {
+	if searcher, ok := t.repo.(timeline.EventSearcher); ok {
+		return searcher.SearchEvents(ctx, query, limit)
+	}
+	events, err := t.repo.ListEvents(ctx, timeline.EventFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return searchInProcess(events, query, limit), nil
+}
+
+func validateEvent(event *timeline.Event, graphics MediaLimits) error {
+	event.Name = strings.TrimSpace(event.Name)
+	if event.Name == "" {
+		return fmt.Errorf("%w: event name is required", timeline.ErrInvalid)
+	}
+	if event.EventTime.IsZero() {
+		return fmt.Errorf("%w: event time is required", timeline.ErrInvalid)
+	}
+	// plain text is stored as the empty format, as it was before formats were introduced
+	switch format := timeline.DescriptionFormat(strings.ToLower(strings.TrimSpace(string(event.DescriptionFormat)))); format {
+	case "", timeline.DescriptionFormatText:
+		event.DescriptionFormat = ""
+	case timeline.DescriptionFormatMarkdown:
+		event.DescriptionFormat = format
+	default:
+		return fmt.Errorf("%w: unknown description format %q", timeline.ErrInvalid, event.DescriptionFormat)
+	}
+	event.Graphic = strings.TrimSpace(event.Graphic)
+	return validateGraphic(event.Graphic, graphics)
+}
+
+// validateGraphic accepts graphics referencing stored media, HTTP URLs and data URIs of images passing the checks
+// of uploads. Pages link to graphics as they are, so no other kinds of URLs are accepted.
+func validateGraphic(graphic string, limits MediaLimits) error {
+	switch {
+	case graphic == "":
+		return nil
+	case strings.HasPrefix(graphic, timeline.MediaPathPrefix):
+		key := strings.TrimPrefix(graphic, timeline.MediaPathPrefix)
+		if key == "" || strings.ContainsAny(key, "/\\?#%") || strings.HasPrefix(key, ".") {
+			return fmt.Errorf("%w: graphic %q is not a media reference", timeline.ErrInvalid, graphic)
+		}
+		return nil
+	case strings.HasPrefix(graphic, "data:"):
+		content, err := decodeDataURI(graphic)
+		if err != nil {
+			return fmt.Errorf("%w: %v", timeline.ErrInvalidMedia, err)
+		}
+		_, err = validateMedia(content, limits)
+		return err
+	}
+	u, err := url.Parse(graphic)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: graphic must be a media reference, an HTTP URL or a data URI", timeline.ErrInvalid)
+	}
+	return nil
 }
 
-func NewEventService(log *zap.Logger, repo timeline.EventRepository) *EventService {
-	return &EventService{log: log, repo: repo}
+func NewEventService(log *zap.Logger, graphics MediaLimits, repo timeline.EventRepository) *EventService {
+	return &EventService{log: log, graphics: graphics, repo: repo}
 }
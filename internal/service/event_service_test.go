@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/base64"
 	"github.com/kamkali/go-timeline/internal/mocks"
 	"github.com/kamkali/go-timeline/internal/timeline"
 	"github.com/stretchr/testify/require"
@@ -25,7 +26,7 @@ func TestGetEvent(t *testing.T) {
 		}
 	)
 
-	eventService := NewEventService(nil, repoMock)
+	eventService := NewEventService(nil, MediaLimits{}, repoMock)
 	t.Run("happy path", func(t *testing.T) {
 		repoMock.On("GetEvent", ctx, validID).
 			Return(event, nil).
@@ -36,3 +37,136 @@ func TestGetEvent(t *testing.T) {
 		require.Equal(t, validID, e.ID)
 	})
 }
+
+func TestSearchEvents(t *testing.T) {
+	ctx := context.Background()
+	events := []timeline.Event{
+		{ID: 1, Name: "Launch of Apollo 11", ShortDescription: "Launch of the mission to the Moon"},
+		{ID: 2, Name: "Apollo 11 Moon landing", ShortDescription: "First humans on the Moon", DetailedDescription: "The lunar module landed on the Moon."},
+		{ID: 3, Name: "Launch of Sputnik 1", ShortDescription: "First artificial satellite"},
+	}
+
+	t.Run("in-process fallback ranks and highlights", func(t *testing.T) {
+		repoMock := mocks.NewEventRepository(t)
+		repoMock.On("ListEvents", ctx, timeline.EventFilter{}).
+			Return(events, nil).
+			Once()
+
+		results, err := NewEventService(nil, MediaLimits{}, repoMock).SearchEvents(ctx, "apollo moon", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, uint(2), results[0].Event.ID)
+		require.Equal(t, uint(1), results[1].Event.ID)
+		require.Greater(t, results[0].Rank, results[1].Rank)
+		require.Contains(t, results[1].Snippet, timeline.HighlightStart+"Moon"+timeline.HighlightStop)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		repoMock := mocks.NewEventRepository(t)
+		repoMock.On("ListEvents", ctx, timeline.EventFilter{}).
+			Return(events, nil).
+			Once()
+
+		results, err := NewEventService(nil, MediaLimits{}, repoMock).SearchEvents(ctx, "launch", 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+	})
+
+	t.Run("native search", func(t *testing.T) {
+		repo := struct {
+			*mocks.EventRepository
+			*mocks.EventSearcher
+		}{mocks.NewEventRepository(t), mocks.NewEventSearcher(t)}
+		repo.EventSearcher.On("SearchEvents", ctx, "moon", 10).
+			Return([]timeline.SearchResult{{Event: events[1], Rank: 1}}, nil).
+			Once()
+
+		results, err := NewEventService(nil, MediaLimits{}, repo).SearchEvents(ctx, "moon", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+	})
+}
+
+func TestUpdateEventValidation(t *testing.T) {
+	ctx := context.Background()
+	eventTime := time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		event   timeline.Event
+		wantErr bool
+	}{
+		"complete":     {event: timeline.Event{Name: " Apollo 11 ", EventTime: eventTime, TypeID: 1}},
+		"missing name": {event: timeline.Event{Name: "  ", EventTime: eventTime}, wantErr: true},
+		"missing time": {event: timeline.Event{Name: "Apollo 11"}, wantErr: true},
+		"text format":  {event: timeline.Event{Name: "Apollo 11", EventTime: eventTime, TypeID: 1, DescriptionFormat: " Text"}},
+		"unknown format": {
+			event:   timeline.Event{Name: "Apollo 11", EventTime: eventTime, DescriptionFormat: "html"},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repoMock := mocks.NewEventRepository(t)
+			if !tt.wantErr {
+				repoMock.On("UpdateEvent", ctx, uint(1), &timeline.Event{Name: "Apollo 11", EventTime: eventTime, TypeID: 1}).
+					Return(nil).
+					Once()
+			}
+
+			err := NewEventService(nil, MediaLimits{}, repoMock).UpdateEvent(ctx, 1, &tt.event)
+			if tt.wantErr {
+				require.ErrorIs(t, err, timeline.ErrInvalid)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestListEventsTimeRange(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(1969, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	repoMock := mocks.NewEventRepository(t)
+	repoMock.On("ListEvents", ctx, timeline.EventFilter{TagMode: timeline.TagModeAny, From: from, To: to, Query: "apollo"}).
+		Return([]timeline.Event{}, nil).
+		Once()
+	_, err := NewEventService(nil, MediaLimits{}, repoMock).ListEvents(ctx, timeline.EventFilter{From: from, To: to, Query: " apollo "})
+	require.NoError(t, err)
+
+	_, err = NewEventService(nil, MediaLimits{}, mocks.NewEventRepository(t)).ListEvents(ctx, timeline.EventFilter{From: to, To: from})
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
+
+func TestValidateGraphic(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString(testPNG(t, 4, 4))
+	limits := MediaLimits{MaxBytes: 1 << 20, MaxDimension: 2}
+
+	tests := map[string]struct {
+		graphic string
+		limits  MediaLimits
+		wantErr error
+	}{
+		"none":              {graphic: ""},
+		"media":             {graphic: "/media/eagle.png"},
+		"url":               {graphic: "https://example.com/eagle.png"},
+		"data uri":          {graphic: "data:image/png;base64," + png},
+		"script":            {graphic: "javascript:alert(1)", wantErr: timeline.ErrInvalid},
+		"relative":          {graphic: "eagle.png", wantErr: timeline.ErrInvalid},
+		"media traversal":   {graphic: "/media/../users", wantErr: timeline.ErrInvalid},
+		"not an image":      {graphic: "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("<svg/>")), wantErr: timeline.ErrInvalidMedia},
+		"malformed data":    {graphic: "data:image/png;base64,!!", wantErr: timeline.ErrInvalidMedia},
+		"beyond dimensions": {graphic: "data:image/png;base64," + png, limits: limits, wantErr: timeline.ErrTooLarge},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateGraphic(tt.graphic, tt.limits)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
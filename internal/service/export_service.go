@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+type ExportService struct {
+	log *zap.Logger
+
+	events timeline.EventRepository
+	types  timeline.TypeRepository
+	tags   timeline.TagRepository
+}
+
+// ExportTimeline loads types and tags before writing anything, so that a failure to read them
+// leaves the output untouched. Events are streamed to the writer as they are read.
+func (s ExportService) ExportTimeline(ctx context.Context, filter timeline.EventFilter, w timeline.ExportWriter) error {
+	filter, err := normalizeEventFilter(filter)
+	if err != nil {
+		return err
+	}
+	types, err := s.types.ListTypes(ctx)
+	if err != nil {
+		return err
+	}
+	// graphic exports draw events in the color their type inherits
+	byID := typesByID(types)
+	for i := range types {
+		types[i].EffectiveColor = inheritedColor(byID, &types[i])
+	}
+	tags, err := s.tags.ListTags(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteTypes(types); err != nil {
+		return err
+	}
+	if err := w.WriteTags(tags); err != nil {
+		return err
+	}
+	if err := s.events.StreamEvents(ctx, filter, w.WriteEvent); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func NewExportService(log *zap.Logger, events timeline.EventRepository, types timeline.TypeRepository, tags timeline.TagRepository) *ExportService {
+	return &ExportService{log: log, events: events, types: types, tags: tags}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+type recordingExportWriter struct {
+	written []string
+	closed  bool
+}
+
+func (r *recordingExportWriter) WriteTypes(types []timeline.Type) error {
+	for _, t := range types {
+		r.written = append(r.written, "type "+t.Name)
+	}
+	return nil
+}
+
+func (r *recordingExportWriter) WriteTags(tags []timeline.Tag) error {
+	for _, t := range tags {
+		r.written = append(r.written, "tag "+t.Name)
+	}
+	return nil
+}
+
+func (r *recordingExportWriter) WriteEvent(event timeline.Event) error {
+	r.written = append(r.written, "event "+event.Name)
+	return nil
+}
+
+func (r *recordingExportWriter) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestExportTimeline(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes types, tags and streamed events", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		types := mocks.NewTypeRepository(t)
+		tags := mocks.NewTagRepository(t)
+		exportService := NewExportService(nil, events, types, tags)
+
+		types.On("ListTypes", ctx).Return([]timeline.Type{{ID: 1, Name: "Mission"}}, nil).Once()
+		tags.On("ListTags", ctx).Return([]timeline.Tag{{ID: 1, Name: "moon"}}, nil).Once()
+		filter := timeline.EventFilter{Tags: []string{"moon"}, TagMode: timeline.TagModeAny}
+		events.On("StreamEvents", ctx, filter, mock.Anything).
+			Return(func(ctx context.Context, filter timeline.EventFilter, fn func(timeline.Event) error) error {
+				for _, name := range []string{"Apollo 11", "Apollo 12"} {
+					if err := fn(timeline.Event{Name: name}); err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Once()
+
+		w := &recordingExportWriter{}
+		err := exportService.ExportTimeline(ctx, timeline.EventFilter{Tags: []string{" moon "}}, w)
+		require.NoError(t, err)
+		require.Equal(t, []string{"type Mission", "tag moon", "event Apollo 11", "event Apollo 12"}, w.written)
+		require.True(t, w.closed)
+	})
+
+	t.Run("nothing is written when types cannot be read", func(t *testing.T) {
+		types := mocks.NewTypeRepository(t)
+		exportService := NewExportService(nil, mocks.NewEventRepository(t), types, mocks.NewTagRepository(t))
+
+		dbErr := errors.New("db error")
+		types.On("ListTypes", ctx).Return(nil, dbErr).Once()
+
+		w := &recordingExportWriter{}
+		require.ErrorIs(t, exportService.ExportTimeline(ctx, timeline.EventFilter{}, w), dbErr)
+		require.Empty(t, w.written)
+		require.False(t, w.closed)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		exportService := NewExportService(nil, mocks.NewEventRepository(t), mocks.NewTypeRepository(t), mocks.NewTagRepository(t))
+		err := exportService.ExportTimeline(ctx, timeline.EventFilter{TagMode: "some"}, &recordingExportWriter{})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+// errImportRolledBack rolls back dry runs and imports with failed rows.
+var errImportRolledBack = errors.New("import rolled back")
+
+type ImportService struct {
+	log      *zap.Logger
+	graphics MediaLimits
+
+	tx timeline.Transactor
+}
+
+// ImportEvents creates the imported events, or updates the events imported before under the same
+// external ID. The import is applied all at once: when any record fails, or for a dry run, nothing
+// is changed and the report tells what the import would have done. Types declared by the file are
+// created first, existing types of the same name are kept as they are.
+func (s ImportService) ImportEvents(ctx context.Context, file timeline.ImportFile, opts timeline.ImportOptions) (timeline.ImportReport, error) {
+	records := file.Records
+	report := timeline.ImportReport{DryRun: opts.DryRun, Rows: make([]timeline.ImportRowResult, len(records))}
+	err := s.tx.InTransaction(ctx, func(repos timeline.Repositories) error {
+		types, err := repos.Types.ListTypes(ctx)
+		if err != nil {
+			return err
+		}
+		resolver := &importTypes{log: s.log, byName: make(map[string]uint), byID: make(map[uint]bool)}
+		for _, t := range types {
+			resolver.byName[strings.ToLower(t.Name)] = t.ID
+			resolver.byID[t.ID] = true
+		}
+		if err := resolver.declare(ctx, repos, file.Types); err != nil {
+			return err
+		}
+		var defaultTypeID uint
+		if opts.DefaultType != "" {
+			defaultTypeID, err = resolver.resolve(ctx, repos, timeline.ImportRecord{TypeName: opts.DefaultType}, opts.CreateTypes)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, rec := range records {
+			row := &report.Rows[i]
+			row.Line, row.ExternalID, row.Err = rec.Line, rec.Event.ExternalID, rec.Err
+			if row.Err != nil {
+				continue
+			}
+			typeID, err := resolver.resolve(ctx, repos, rec, opts.CreateTypes)
+			if err != nil {
+				row.Err = err
+				continue
+			}
+			// a savepoint per record keeps the transaction usable after a failed statement
+			_ = repos.Tx.InTransaction(ctx, func(item timeline.Repositories) error {
+				row.Action, row.EventID, row.Err = s.importRecord(ctx, item, rec, typeID, defaultTypeID)
+				return row.Err
+			})
+		}
+		report.CreatedTypes = resolver.created
+
+		for _, row := range report.Rows {
+			switch {
+			case row.Err != nil:
+				report.Failed++
+			case row.Action == timeline.ImportCreate:
+				report.Created++
+			case row.Action == timeline.ImportUpdate:
+				report.Updated++
+			default:
+				report.Unchanged++
+			}
+		}
+		if report.Failed > 0 || opts.DryRun {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		return timeline.ImportReport{}, err
+	}
+	report.Committed = err == nil
+	if !report.Committed {
+		// identifiers of rolled back events are meaningless
+		for i := range report.Rows {
+			if report.Rows[i].Action == timeline.ImportCreate {
+				report.Rows[i].EventID = 0
+			}
+		}
+	}
+	return report, nil
+}
+
+func (s ImportService) importRecord(ctx context.Context, repos timeline.Repositories, rec timeline.ImportRecord, typeID, defaultTypeID uint) (timeline.ImportAction, uint, error) {
+	events := NewEventService(s.log, s.graphics, repos.Events)
+
+	if rec.Event.ExternalID != "" {
+		existing, err := findImportedEvent(ctx, repos, rec.Event.ExternalID)
+		switch {
+		case err == nil:
+			merged := mergeImportedEvent(existing, rec, typeID)
+			if err := validateEvent(&merged, s.graphics); err != nil {
+				return "", existing.ID, err
+			}
+			merged.Tags = normalizeTags(merged.Tags)
+			if sameImportedEvent(existing, merged) {
+				return timeline.ImportUnchanged, existing.ID, nil
+			}
+			merged.Revision = 0
+			if err := events.UpdateEvent(ctx, existing.ID, &merged); err != nil {
+				return "", existing.ID, err
+			}
+			return timeline.ImportUpdate, existing.ID, nil
+		case !errors.Is(err, timeline.ErrNotFound):
+			return "", 0, err
+		}
+	}
+
+	event := rec.Event
+	event.TypeID = typeID
+	if event.TypeID == 0 {
+		event.TypeID = defaultTypeID
+	}
+	if event.TypeID == 0 {
+		return "", 0, fmt.Errorf("%w: event type is required", timeline.ErrInvalid)
+	}
+	id, err := events.CreateEvent(ctx, &event)
+	if err != nil {
+		return "", 0, err
+	}
+	return timeline.ImportCreate, id, nil
+}
+
+// findImportedEvent returns the event imported under the external ID. Exports give events without an external ID
+// one made of their ID, which refers to the event of that ID unless an event was imported under it.
+func findImportedEvent(ctx context.Context, repos timeline.Repositories, externalID string) (timeline.Event, error) {
+	existing, err := repos.Events.GetEventByExternalID(ctx, externalID)
+	if !errors.Is(err, timeline.ErrNotFound) {
+		return existing, err
+	}
+	id, ok := timeline.ExportedEventID(externalID)
+	if !ok {
+		return timeline.Event{}, err
+	}
+	existing, err = repos.Events.GetEvent(ctx, id)
+	if err == nil && existing.ExternalID != "" {
+		return timeline.Event{}, timeline.ErrNotFound
+	}
+	return existing, err
+}
+
+// mergeImportedEvent overwrites the fields of an existing event that the record provides.
+func mergeImportedEvent(existing timeline.Event, rec timeline.ImportRecord, typeID uint) timeline.Event {
+	merged := existing
+	merged.Tags = append([]string(nil), existing.Tags...)
+	for _, field := range rec.Provided {
+		switch field {
+		case timeline.ImportFieldName:
+			merged.Name = rec.Event.Name
+		case timeline.ImportFieldEventTime:
+			merged.EventTime = rec.Event.EventTime
+		case timeline.ImportFieldShortDescription:
+			merged.ShortDescription = rec.Event.ShortDescription
+		case timeline.ImportFieldDetailedDescription:
+			merged.DetailedDescription = rec.Event.DetailedDescription
+		case timeline.ImportFieldDescriptionFormat:
+			merged.DescriptionFormat = rec.Event.DescriptionFormat
+		case timeline.ImportFieldGraphic:
+			merged.Graphic = rec.Event.Graphic
+		case timeline.ImportFieldTags:
+			merged.Tags = rec.Event.Tags
+		}
+	}
+	if typeID != 0 {
+		merged.TypeID = typeID
+	}
+	return merged
+}
+
+func sameImportedEvent(a, b timeline.Event) bool {
+	if a.Name != b.Name || !a.EventTime.Equal(b.EventTime) || a.ShortDescription != b.ShortDescription ||
+		a.DetailedDescription != b.DetailedDescription || a.DescriptionFormat != b.DescriptionFormat || a.Graphic != b.Graphic || a.TypeID != b.TypeID {
+		return false
+	}
+	tags := normalizeTags(a.Tags)
+	if len(tags) != len(b.Tags) {
+		return false
+	}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		seen[tag] = true
+	}
+	for _, tag := range b.Tags {
+		if !seen[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// importTypes resolves the types of imported records, creating missing ones when asked to.
+type importTypes struct {
+	log *zap.Logger
+
+	byName  map[string]uint
+	byID    map[uint]bool
+	created []string
+}
+
+// declare creates the declared types that do not exist yet, parents before their children.
+func (r *importTypes) declare(ctx context.Context, repos timeline.Repositories, types []timeline.ImportType) error {
+	pending := types
+	for len(pending) > 0 {
+		var deferred []timeline.ImportType
+		for _, it := range pending {
+			key := strings.ToLower(strings.TrimSpace(it.Type.Name))
+			if _, ok := r.byName[key]; ok {
+				continue
+			}
+			t := it.Type
+			t.ID, t.ParentID = 0, nil
+			if it.ParentName != "" {
+				parentID, ok := r.byName[strings.ToLower(strings.TrimSpace(it.ParentName))]
+				if !ok {
+					deferred = append(deferred, it)
+					continue
+				}
+				t.ParentID = &parentID
+			}
+			id, err := NewTypeService(r.log, repos.Types).CreateType(ctx, &t)
+			if err != nil {
+				return fmt.Errorf("line %d: cannot create type %q: %w", it.Line, it.Type.Name, err)
+			}
+			r.byName[strings.ToLower(t.Name)], r.byID[id] = id, true
+			r.created = append(r.created, t.Name)
+		}
+		if len(deferred) == len(pending) {
+			it := deferred[0]
+			return fmt.Errorf("%w: line %d: unknown parent type %q", timeline.ErrInvalid, it.Line, it.ParentName)
+		}
+		pending = deferred
+	}
+	return nil
+}
+
+// resolve returns the type of the record, or 0 if the record does not refer to any.
+func (r *importTypes) resolve(ctx context.Context, repos timeline.Repositories, rec timeline.ImportRecord, create bool) (uint, error) {
+	if rec.TypeName == "" {
+		if rec.Event.TypeID != 0 && !r.byID[rec.Event.TypeID] {
+			return 0, fmt.Errorf("%w: unknown type %d", timeline.ErrInvalid, rec.Event.TypeID)
+		}
+		return rec.Event.TypeID, nil
+	}
+	key := strings.ToLower(rec.TypeName)
+	if id, ok := r.byName[key]; ok {
+		return id, nil
+	}
+	if !create {
+		return 0, fmt.Errorf("%w: unknown type %q", timeline.ErrInvalid, rec.TypeName)
+	}
+	var id uint
+	err := repos.Tx.InTransaction(ctx, func(item timeline.Repositories) error {
+		var err error
+		id, err = NewTypeService(r.log, item.Types).CreateType(ctx, &timeline.Type{Name: rec.TypeName})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot create type %q: %w", rec.TypeName, err)
+	}
+	r.byName[key], r.byID[id] = id, true
+	r.created = append(r.created, rec.TypeName)
+	return id, nil
+}
+
+func NewImportService(log *zap.Logger, graphics MediaLimits, tx timeline.Transactor) *ImportService {
+	return &ImportService{log: log, graphics: graphics, tx: tx}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"testing"
+	"time"
+)
+
+func TestImportEvents(t *testing.T) {
+	ctx := context.Background()
+	eventTime := time.Date(1969, 7, 16, 0, 0, 0, 0, time.UTC)
+	existing := timeline.Event{
+		ID: 4, Name: "Apollo 11", EventTime: eventTime, TypeID: 1, Tags: []string{"moon"}, Revision: 3, ExternalID: "apollo-11",
+	}
+	types := []timeline.Type{{ID: 1, Name: "Mission"}}
+
+	t.Run("creates, updates and skips unchanged events", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		events.On("GetEventByExternalID", ctx, "apollo-11").Return(existing, nil).Twice()
+		events.On("GetEventByExternalID", ctx, "apollo-12").Return(timeline.Event{}, timeline.ErrNotFound).Once()
+		events.On("UpdateEvent", ctx, uint(4), mock.MatchedBy(func(e *timeline.Event) bool {
+			return e.Name == "Apollo 11" && e.ShortDescription == "First landing" && e.Revision == 0 && e.Tags[0] == "moon"
+		})).Return(nil).Once()
+		events.On("CreateEvent", ctx, mock.MatchedBy(func(e *timeline.Event) bool {
+			return e.ExternalID == "apollo-12" && e.TypeID == 1
+		})).Return(uint(5), nil).Once()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Records: []timeline.ImportRecord{
+			{Line: 2, Event: timeline.Event{ExternalID: "apollo-11", Name: "Apollo 11"}, Provided: []string{"external_id", "name"}},
+			{Line: 3, Event: timeline.Event{ExternalID: "apollo-11", ShortDescription: "First landing"}, Provided: []string{"external_id", "short_description"}},
+			{Line: 4, Event: timeline.Event{ExternalID: "apollo-12", Name: "Apollo 12", EventTime: eventTime}, TypeName: "mission"},
+		}}, timeline.ImportOptions{})
+		require.NoError(t, err)
+		require.True(t, report.Committed)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, 1, report.Updated)
+		require.Equal(t, 1, report.Unchanged)
+		require.Equal(t, uint(5), report.Rows[2].EventID)
+	})
+
+	t.Run("exported events without external IDs are updated", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+		untracked := timeline.Event{ID: 6, Name: "Apollo 13", EventTime: eventTime, TypeID: 1}
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		events.On("GetEventByExternalID", ctx, "event-6").Return(timeline.Event{}, timeline.ErrNotFound).Once()
+		events.On("GetEvent", ctx, uint(6)).Return(untracked, nil).Once()
+		// event 4 was imported under an external ID of its own, event-4 is not its
+		events.On("GetEventByExternalID", ctx, "event-4").Return(timeline.Event{}, timeline.ErrNotFound).Once()
+		events.On("GetEvent", ctx, uint(4)).Return(existing, nil).Once()
+		events.On("UpdateEvent", ctx, uint(6), mock.MatchedBy(func(e *timeline.Event) bool {
+			return e.ShortDescription == "Houston, we've had a problem"
+		})).Return(nil).Once()
+		events.On("CreateEvent", ctx, mock.MatchedBy(func(e *timeline.Event) bool {
+			return e.ExternalID == "event-4"
+		})).Return(uint(7), nil).Once()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Records: []timeline.ImportRecord{
+			{Line: 2, Event: timeline.Event{ExternalID: "event-6", ShortDescription: "Houston, we've had a problem"}, Provided: []string{"external_id", "short_description"}},
+			{Line: 3, Event: timeline.Event{ExternalID: "event-4", Name: "Apollo 11", EventTime: eventTime, TypeID: 1}},
+		}}, timeline.ImportOptions{})
+		require.NoError(t, err)
+		require.True(t, report.Committed)
+		require.Equal(t, 1, report.Updated)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, uint(6), report.Rows[0].EventID)
+	})
+
+	t.Run("deleted events are imported again", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		// deleted events are not found by their external IDs, which are unique among the remaining events only
+		events.On("GetEventByExternalID", ctx, "apollo-11").Return(timeline.Event{}, timeline.ErrNotFound).Once()
+		events.On("CreateEvent", ctx, mock.MatchedBy(func(e *timeline.Event) bool {
+			return e.ExternalID == "apollo-11" && e.Name == "Apollo 11"
+		})).Return(uint(8), nil).Once()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Records: []timeline.ImportRecord{
+			{Line: 2, Event: timeline.Event{ExternalID: "apollo-11", Name: "Apollo 11", EventTime: eventTime, TypeID: 1}},
+		}}, timeline.ImportOptions{})
+		require.NoError(t, err)
+		require.True(t, report.Committed)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, uint(8), report.Rows[0].EventID)
+	})
+
+	t.Run("failed rows roll back the import", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		events.On("CreateEvent", ctx, mock.Anything).Return(uint(5), nil).Once()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Records: []timeline.ImportRecord{
+			{Line: 2, Event: timeline.Event{Name: "Apollo 12", EventTime: eventTime}, TypeName: "Mission"},
+			{Line: 3, Event: timeline.Event{Name: "Vostok 1", EventTime: eventTime}, TypeName: "Flight"},
+			{Line: 4, Event: timeline.Event{Name: " ", EventTime: eventTime, TypeID: 1}},
+			{Line: 5, Err: timeline.ErrInvalid},
+		}}, timeline.ImportOptions{})
+		require.NoError(t, err)
+		require.False(t, report.Committed)
+		require.Equal(t, 3, report.Failed)
+		require.Equal(t, timeline.ImportCreate, report.Rows[0].Action)
+		require.Zero(t, report.Rows[0].EventID)
+		require.ErrorIs(t, report.Rows[1].Err, timeline.ErrInvalid)
+		require.ErrorIs(t, report.Rows[2].Err, timeline.ErrInvalid)
+		require.ErrorIs(t, report.Rows[3].Err, timeline.ErrInvalid)
+	})
+
+	t.Run("dry run creating missing types", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		typeRepo.On("CreateType", ctx, &timeline.Type{Name: "Flight"}).Return(uint(2), nil).Once()
+		events.On("CreateEvent", ctx, mock.MatchedBy(func(e *timeline.Event) bool { return e.TypeID == 2 })).Return(uint(5), nil).Twice()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Records: []timeline.ImportRecord{
+			{Line: 2, Event: timeline.Event{Name: "Vostok 1", EventTime: eventTime}, TypeName: "Flight"},
+			{Line: 3, Event: timeline.Event{Name: "Vostok 2", EventTime: eventTime}, TypeName: "flight"},
+		}}, timeline.ImportOptions{CreateTypes: true, DryRun: true})
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.False(t, report.Committed)
+		require.Equal(t, 2, report.Created)
+		require.Equal(t, []string{"Flight"}, report.CreatedTypes)
+	})
+
+	t.Run("declared types are created parents first", func(t *testing.T) {
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: mocks.NewEventRepository(t), Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+
+		parentID := uint(2)
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		typeRepo.On("CreateType", ctx, &timeline.Type{Name: "Flight", Color: "red"}).Return(parentID, nil).Once()
+		// the parent of the created child is validated against the current types
+		typeRepo.On("ListTypes", ctx).Return(append(types, timeline.Type{ID: parentID, Name: "Flight"}), nil).Once()
+		typeRepo.On("CreateType", ctx, &timeline.Type{Name: "Orbital", ParentID: &parentID}).Return(uint(3), nil).Once()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Types: []timeline.ImportType{
+			{Line: 2, Type: timeline.Type{Name: "Orbital"}, ParentName: "flight"},
+			{Line: 3, Type: timeline.Type{Name: "Flight", Color: "red"}},
+			{Line: 4, Type: timeline.Type{Name: "mission", Color: "green"}},
+		}}, timeline.ImportOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Flight", "Orbital"}, report.CreatedTypes)
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		_, err = importService.ImportEvents(ctx, timeline.ImportFile{Types: []timeline.ImportType{
+			{Line: 2, Type: timeline.Type{Name: "Orbital"}, ParentName: "Suborbital"},
+		}}, timeline.ImportOptions{})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+
+	t.Run("default type", func(t *testing.T) {
+		events := mocks.NewEventRepository(t)
+		typeRepo := mocks.NewTypeRepository(t)
+		repos := timeline.Repositories{Events: events, Types: typeRepo}
+		importService := NewImportService(nil, MediaLimits{}, newTestTransactor(t, &repos))
+
+		typeRepo.On("ListTypes", ctx).Return(append(types, timeline.Type{ID: 2, Name: "Meeting"}), nil).Once()
+		events.On("GetEventByExternalID", ctx, "standup").Return(timeline.Event{}, timeline.ErrNotFound).Once()
+		events.On("CreateEvent", ctx, mock.MatchedBy(func(e *timeline.Event) bool { return e.TypeID == 2 })).Return(uint(5), nil).Once()
+		events.On("CreateEvent", ctx, mock.MatchedBy(func(e *timeline.Event) bool { return e.TypeID == 1 })).Return(uint(6), nil).Once()
+
+		report, err := importService.ImportEvents(ctx, timeline.ImportFile{Records: []timeline.ImportRecord{
+			{Line: 3, Event: timeline.Event{ExternalID: "standup", Name: "Stand up", EventTime: eventTime}},
+			{Line: 9, Event: timeline.Event{Name: "Launch", EventTime: eventTime}, TypeName: "Mission"},
+		}}, timeline.ImportOptions{DefaultType: "meeting"})
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Created)
+
+		typeRepo.On("ListTypes", ctx).Return(types, nil).Once()
+		_, err = importService.ImportEvents(ctx, timeline.ImportFile{}, timeline.ImportOptions{DefaultType: "Meeting"})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"io"
+)
+
+// invalidator drops cached pages once the timeline changed. The change is done by then, so a failure to
+// invalidate is logged rather than returned, the pages expire eventually.
+type invalidator struct {
+	log   *zap.Logger
+	cache timeline.PageCache
+}
+
+// after invalidates the cache unless the change failed, and passes its error on.
+func (i invalidator) after(ctx context.Context, err error) error {
+	if err == nil {
+		i.invalidate(ctx)
+	}
+	return err
+}
+
+func (i invalidator) invalidate(ctx context.Context) {
+	if err := i.cache.Invalidate(ctx); err != nil {
+		i.log.Error(fmt.Sprintf("cannot invalidate page cache: %v", err))
+	}
+}
+
+// InvalidatingEventService invalidates the page cache after events are created, updated or deleted.
+type InvalidatingEventService struct {
+	timeline.EventService
+	invalidator
+}
+
+func NewInvalidatingEventService(log *zap.Logger, events timeline.EventService, cache timeline.PageCache) *InvalidatingEventService {
+	return &InvalidatingEventService{EventService: events, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingEventService) CreateEvent(ctx context.Context, event *timeline.Event) (uint, error) {
+	id, err := s.EventService.CreateEvent(ctx, event)
+	return id, s.after(ctx, err)
+}
+
+func (s InvalidatingEventService) UpdateEvent(ctx context.Context, id uint, event *timeline.Event) error {
+	return s.after(ctx, s.EventService.UpdateEvent(ctx, id, event))
+}
+
+func (s InvalidatingEventService) DeleteEvent(ctx context.Context, id uint, revision uint) error {
+	return s.after(ctx, s.EventService.DeleteEvent(ctx, id, revision))
+}
+
+// InvalidatingTypeService invalidates the page cache after types are created, updated or deleted.
+type InvalidatingTypeService struct {
+	timeline.TypeService
+	invalidator
+}
+
+func NewInvalidatingTypeService(log *zap.Logger, types timeline.TypeService, cache timeline.PageCache) *InvalidatingTypeService {
+	return &InvalidatingTypeService{TypeService: types, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingTypeService) CreateType(ctx context.Context, t *timeline.Type) (uint, error) {
+	id, err := s.TypeService.CreateType(ctx, t)
+	return id, s.after(ctx, err)
+}
+
+func (s InvalidatingTypeService) UpdateType(ctx context.Context, id uint, t *timeline.Type) error {
+	return s.after(ctx, s.TypeService.UpdateType(ctx, id, t))
+}
+
+func (s InvalidatingTypeService) DeleteType(ctx context.Context, id uint, policy timeline.TypeDeletePolicy, revision uint) error {
+	return s.after(ctx, s.TypeService.DeleteType(ctx, id, policy, revision))
+}
+
+// InvalidatingTagService invalidates the page cache after tags are renamed or merged.
+type InvalidatingTagService struct {
+	timeline.TagService
+	invalidator
+}
+
+func NewInvalidatingTagService(log *zap.Logger, tags timeline.TagService, cache timeline.PageCache) *InvalidatingTagService {
+	return &InvalidatingTagService{TagService: tags, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingTagService) RenameTag(ctx context.Context, id uint, name string) error {
+	return s.after(ctx, s.TagService.RenameTag(ctx, id, name))
+}
+
+func (s InvalidatingTagService) MergeTags(ctx context.Context, sourceID, targetID uint) error {
+	return s.after(ctx, s.TagService.MergeTags(ctx, sourceID, targetID))
+}
+
+// InvalidatingRelationService invalidates the page cache after relations are created or deleted.
+type InvalidatingRelationService struct {
+	timeline.RelationService
+	invalidator
+}
+
+func NewInvalidatingRelationService(log *zap.Logger, relations timeline.RelationService, cache timeline.PageCache) *InvalidatingRelationService {
+	return &InvalidatingRelationService{RelationService: relations, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingRelationService) CreateRelation(ctx context.Context, r *timeline.Relation) (uint, error) {
+	id, err := s.RelationService.CreateRelation(ctx, r)
+	return id, s.after(ctx, err)
+}
+
+func (s InvalidatingRelationService) DeleteRelation(ctx context.Context, eventID, id uint) error {
+	return s.after(ctx, s.RelationService.DeleteRelation(ctx, eventID, id))
+}
+
+// InvalidatingBatchService invalidates the page cache after batches. Batches that are not atomic may fail after
+// some of their operations succeeded, so the cache is invalidated regardless of the outcome.
+type InvalidatingBatchService struct {
+	timeline.BatchService
+	invalidator
+}
+
+func NewInvalidatingBatchService(log *zap.Logger, batches timeline.BatchService, cache timeline.PageCache) *InvalidatingBatchService {
+	return &InvalidatingBatchService{BatchService: batches, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingBatchService) ExecuteBatch(ctx context.Context, mode timeline.BatchMode, ops []timeline.BatchOperation) ([]timeline.BatchResult, error) {
+	results, err := s.BatchService.ExecuteBatch(ctx, mode, ops)
+	s.invalidate(ctx)
+	return results, err
+}
+
+// InvalidatingImportService invalidates the page cache after imports, unless they were dry runs.
+type InvalidatingImportService struct {
+	timeline.ImportService
+	invalidator
+}
+
+func NewInvalidatingImportService(log *zap.Logger, imports timeline.ImportService, cache timeline.PageCache) *InvalidatingImportService {
+	return &InvalidatingImportService{ImportService: imports, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingImportService) ImportEvents(ctx context.Context, file timeline.ImportFile, opts timeline.ImportOptions) (timeline.ImportReport, error) {
+	report, err := s.ImportService.ImportEvents(ctx, file, opts)
+	if opts.DryRun {
+		return report, err
+	}
+	return report, s.after(ctx, err)
+}
+
+// InvalidatingMediaService invalidates the page cache after graphics of events are uploaded or migrated.
+type InvalidatingMediaService struct {
+	timeline.MediaService
+	invalidator
+}
+
+func NewInvalidatingMediaService(log *zap.Logger, media timeline.MediaService, cache timeline.PageCache) *InvalidatingMediaService {
+	return &InvalidatingMediaService{MediaService: media, invalidator: invalidator{log: log, cache: cache}}
+}
+
+func (s InvalidatingMediaService) UploadEventGraphic(ctx context.Context, eventID uint, r io.Reader) (timeline.Media, error) {
+	m, err := s.MediaService.UploadEventGraphic(ctx, eventID, r)
+	return m, s.after(ctx, err)
+}
+
+// MigrateEventGraphics invalidates the cache if any graphic was migrated, even if the migration failed later on.
+func (s InvalidatingMediaService) MigrateEventGraphics(ctx context.Context) (int, error) {
+	n, err := s.MediaService.MigrateEventGraphics(ctx)
+	if n > 0 {
+		s.invalidate(ctx)
+	}
+	return n, err
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+func TestInvalidatingEventService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalidates after changes", func(t *testing.T) {
+		events := mocks.NewEventService(t)
+		cache := mocks.NewPageCache(t)
+		events.On("CreateEvent", ctx, &timeline.Event{Name: "Apollo 11"}).
+			Return(uint(1), nil).
+			Once()
+		events.On("DeleteEvent", ctx, uint(1), uint(2)).
+			Return(nil).
+			Once()
+		cache.On("Invalidate", ctx).
+			Return(nil).
+			Twice()
+
+		eventService := NewInvalidatingEventService(zap.NewNop(), events, cache)
+		id, err := eventService.CreateEvent(ctx, &timeline.Event{Name: "Apollo 11"})
+		require.NoError(t, err)
+		require.Equal(t, uint(1), id)
+		require.NoError(t, eventService.DeleteEvent(ctx, 1, 2))
+	})
+
+	t.Run("keeps the cache if the change failed", func(t *testing.T) {
+		events := mocks.NewEventService(t)
+		events.On("UpdateEvent", ctx, uint(1), &timeline.Event{}).
+			Return(timeline.ErrPreconditionFailed).
+			Once()
+
+		err := NewInvalidatingEventService(zap.NewNop(), events, mocks.NewPageCache(t)).UpdateEvent(ctx, 1, &timeline.Event{})
+		require.ErrorIs(t, err, timeline.ErrPreconditionFailed)
+	})
+
+	t.Run("failing cache does not fail the change", func(t *testing.T) {
+		events := mocks.NewEventService(t)
+		cache := mocks.NewPageCache(t)
+		events.On("DeleteEvent", ctx, uint(1), uint(0)).
+			Return(nil).
+			Once()
+		cache.On("Invalidate", ctx).
+			Return(errors.New("connection refused")).
+			Once()
+
+		require.NoError(t, NewInvalidatingEventService(zap.NewNop(), events, cache).DeleteEvent(ctx, 1, 0))
+	})
+
+	t.Run("reads pass through", func(t *testing.T) {
+		events := mocks.NewEventService(t)
+		events.On("GetEvent", ctx, uint(1)).
+			Return(timeline.Event{ID: 1}, nil).
+			Once()
+
+		event, err := NewInvalidatingEventService(zap.NewNop(), events, mocks.NewPageCache(t)).GetEvent(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), event.ID)
+	})
+}
+
+func TestInvalidatingImportService(t *testing.T) {
+	ctx := context.Background()
+	file := timeline.ImportFile{Records: []timeline.ImportRecord{{Line: 2, Event: timeline.Event{Name: "Apollo 11"}}}}
+	imports := mocks.NewImportService(t)
+	cache := mocks.NewPageCache(t)
+	imports.On("ImportEvents", ctx, file, timeline.ImportOptions{DryRun: true}).
+		Return(timeline.ImportReport{}, nil).
+		Once()
+	imports.On("ImportEvents", ctx, file, timeline.ImportOptions{}).
+		Return(timeline.ImportReport{}, nil).
+		Once()
+	cache.On("Invalidate", ctx).
+		Return(nil).
+		Once()
+
+	importService := NewInvalidatingImportService(zap.NewNop(), imports, cache)
+	_, err := importService.ImportEvents(ctx, file, timeline.ImportOptions{DryRun: true})
+	require.NoError(t, err)
+	_, err = importService.ImportEvents(ctx, file, timeline.ImportOptions{})
+	require.NoError(t, err)
+}
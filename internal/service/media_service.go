@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/imaging"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"io"
+	"net/url"
+	"strings"
+)
+
+var mediaExtensions = map[string]string{
+	imaging.JPEG: ".jpg",
+	imaging.PNG:  ".png",
+	imaging.GIF:  ".gif",
+	imaging.WebP: ".webp",
+}
+
+var mediaVariants = []struct {
+	name          string
+	width, height int
+}{
+	{name: timeline.MediaVariantThumbnail, width: 240, height: 180},
+	{name: timeline.MediaVariantMedium, width: 800, height: 600},
+}
+
+type MediaLimits struct {
+	MaxBytes     int64
+	MaxDimension int
+}
+
+type MediaService struct {
+	log    *zap.Logger
+	limits MediaLimits
+
+	repo      timeline.MediaRepository
+	eventRepo timeline.EventRepository
+	store     timeline.BlobStore
+}
+
+func (m MediaService) UploadEventGraphic(ctx context.Context, eventID uint, r io.Reader) (timeline.Media, error) {
+	event, err := m.eventRepo.GetEvent(ctx, eventID)
+	if err != nil {
+		return timeline.Media{}, err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return timeline.Media{}, fmt.Errorf("cannot read media: %w", err)
+	}
+
+	media, err := m.storeMedia(ctx, content)
+	if err != nil {
+		return timeline.Media{}, err
+	}
+
+	event.Graphic = timeline.MediaPath(media.Key)
+	if err := m.eventRepo.UpdateEvent(ctx, eventID, &event); err != nil {
+		return timeline.Media{}, err
+	}
+	return media, nil
+}
+
+func (m MediaService) GetMedia(ctx context.Context, key string) (timeline.Media, io.ReadCloser, error) {
+	media, err := m.repo.GetMedia(ctx, key)
+	if err != nil {
+		return timeline.Media{}, nil, err
+	}
+	rc, err := m.store.Get(ctx, key)
+	if err != nil {
+		return timeline.Media{}, nil, err
+	}
+	return media, rc, nil
+}
+
+// MigrateEventGraphics moves graphics embedded in events as data URIs into
+// the blob store and replaces them with media references. It returns the
+// number of migrated events.
+func (m MediaService) MigrateEventGraphics(ctx context.Context) (int, error) {
+	events, err := m.eventRepo.ListEvents(ctx, timeline.EventFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	var migrated int
+	for i := range events {
+		event := events[i]
+		if !strings.HasPrefix(event.Graphic, "data:") {
+			continue
+		}
+		content, err := decodeDataURI(event.Graphic)
+		if err != nil {
+			return migrated, fmt.Errorf("event %d: %w", event.ID, err)
+		}
+		media, err := m.storeMedia(ctx, content)
+		if err != nil {
+			return migrated, fmt.Errorf("event %d: %w", event.ID, err)
+		}
+		event.Graphic = timeline.MediaPath(media.Key)
+		if err := m.eventRepo.UpdateEvent(ctx, event.ID, &event); err != nil {
+			return migrated, fmt.Errorf("event %d: %w", event.ID, err)
+		}
+		m.log.Info(fmt.Sprintf("migrated graphic of event %d to %s", event.ID, event.Graphic))
+		migrated++
+	}
+	return migrated, nil
+}
+
+// storeMedia validates the image, strips its metadata and stores it along with its resized variants.
+func (m MediaService) storeMedia(ctx context.Context, content []byte) (timeline.Media, error) {
+	contentType, err := validateMedia(content, m.limits)
+	if err != nil {
+		return timeline.Media{}, err
+	}
+	content, err = imaging.Sanitize(contentType, content)
+	if err != nil {
+		return timeline.Media{}, fmt.Errorf("%w: %v", timeline.ErrInvalidMedia, err)
+	}
+
+	media, err := m.putMedia(ctx, checksumOf(content)+mediaExtensions[contentType], contentType, content)
+	if err != nil {
+		return timeline.Media{}, err
+	}
+	for _, v := range mediaVariants {
+		resized, err := imaging.Resize(content, v.width, v.height)
+		if err != nil {
+			return timeline.Media{}, fmt.Errorf("%w: %v", timeline.ErrInvalidMedia, err)
+		}
+		key := timeline.MediaVariantKey(media.Key, v.name)
+		if _, err := m.putMedia(ctx, key, imaging.VariantType(contentType), resized); err != nil {
+			return timeline.Media{}, err
+		}
+	}
+	return media, nil
+}
+
+func (m MediaService) putMedia(ctx context.Context, key, contentType string, content []byte) (timeline.Media, error) {
+	media := timeline.Media{
+		Key:         key,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Checksum:    checksumOf(content),
+	}
+	if err := m.store.Put(ctx, media.Key, bytes.NewReader(content), media.Size, media.ContentType); err != nil {
+		return timeline.Media{}, fmt.Errorf("cannot store media: %w", err)
+	}
+	if err := m.repo.CreateMedia(ctx, &media); err != nil {
+		return timeline.Media{}, err
+	}
+	return media, nil
+}
+
+// validateMedia checks that the content is an image within the limits and returns its content type.
+func validateMedia(content []byte, limits MediaLimits) (string, error) {
+	if len(content) == 0 {
+		return "", fmt.Errorf("%w: empty content", timeline.ErrInvalidMedia)
+	}
+	if limits.MaxBytes > 0 && int64(len(content)) > limits.MaxBytes {
+		return "", fmt.Errorf("%w: %d bytes exceeds the limit of %d", timeline.ErrTooLarge, len(content), limits.MaxBytes)
+	}
+	contentType, err := imaging.Sniff(content)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", timeline.ErrInvalidMedia, err)
+	}
+	width, height, err := imaging.Dimensions(content)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", timeline.ErrInvalidMedia, err)
+	}
+	if limits.MaxDimension > 0 && (width > limits.MaxDimension || height > limits.MaxDimension) {
+		return "", fmt.Errorf("%w: %dx%d exceeds the limit of %d pixels", timeline.ErrTooLarge, width, height, limits.MaxDimension)
+	}
+	return contentType, nil
+}
+
+func checksumOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// decodeDataURI returns the payload of an RFC 2397 data URI. The declared
+// media type is ignored, as the stored graphics are not labeled reliably.
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, fmt.Errorf("malformed data uri")
+	}
+	if strings.HasSuffix(header, ";base64") {
+		content, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("malformed base64 data uri: %w", err)
+		}
+		return content, nil
+	}
+	content, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed data uri: %w", err)
+	}
+	return []byte(content), nil
+}
+
+func NewMediaService(
+	log *zap.Logger,
+	limits MediaLimits,
+	repo timeline.MediaRepository,
+	eventRepo timeline.EventRepository,
+	store timeline.BlobStore,
+) *MediaService {
+	return &MediaService{log: log, limits: limits, repo: repo, eventRepo: eventRepo, store: store}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestUploadEventGraphic(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		repo      = mocks.NewMediaRepository(t)
+		eventRepo = mocks.NewEventRepository(t)
+		store     = mocks.NewBlobStore(t)
+	)
+	mediaService := NewMediaService(zap.NewNop(), MediaLimits{}, repo, eventRepo, store)
+	graphic := testPNG(t, 1600, 900)
+
+	eventRepo.On("GetEvent", ctx, uint(1)).
+		Return(timeline.Event{ID: 1, Name: "event"}, nil).
+		Once()
+	store.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("int64"), "image/png").
+		Return(nil).
+		Times(3)
+	repo.On("CreateMedia", ctx, mock.AnythingOfType("*timeline.Media")).
+		Return(nil).
+		Times(3)
+	eventRepo.On("UpdateEvent", ctx, uint(1), mock.MatchedBy(func(e *timeline.Event) bool {
+		return strings.HasPrefix(e.Graphic, timeline.MediaPathPrefix) && strings.HasSuffix(e.Graphic, ".png")
+	})).
+		Return(nil).
+		Once()
+
+	m, err := mediaService.UploadEventGraphic(ctx, 1, bytes.NewReader(graphic))
+	require.NoError(t, err)
+	require.Equal(t, "image/png", m.ContentType)
+	require.Equal(t, m.Checksum+".png", m.Key)
+	store.AssertCalled(t, "Put", ctx, m.Checksum+"-thumbnail.png", mock.Anything, mock.AnythingOfType("int64"), "image/png")
+	store.AssertCalled(t, "Put", ctx, m.Checksum+"-medium.png", mock.Anything, mock.AnythingOfType("int64"), "image/png")
+}
+
+func TestUploadEventGraphicValidation(t *testing.T) {
+	tests := map[string]struct {
+		content []byte
+		limits  MediaLimits
+		wantErr error
+	}{
+		"not an image": {
+			content: []byte("<html><script>alert(1)</script></html>"),
+			wantErr: timeline.ErrInvalidMedia,
+		},
+		"empty": {
+			content: []byte{},
+			wantErr: timeline.ErrInvalidMedia,
+		},
+		"too many bytes": {
+			content: testPNG(t, 10, 10),
+			limits:  MediaLimits{MaxBytes: 10},
+			wantErr: timeline.ErrTooLarge,
+		},
+		"too many pixels": {
+			content: testPNG(t, 200, 10),
+			limits:  MediaLimits{MaxDimension: 100},
+			wantErr: timeline.ErrTooLarge,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			eventRepo := mocks.NewEventRepository(t)
+			eventRepo.On("GetEvent", ctx, uint(1)).
+				Return(timeline.Event{ID: 1}, nil).
+				Once()
+			mediaService := NewMediaService(zap.NewNop(), tt.limits, mocks.NewMediaRepository(t), eventRepo, mocks.NewBlobStore(t))
+
+			_, err := mediaService.UploadEventGraphic(ctx, 1, bytes.NewReader(tt.content))
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMigrateEventGraphics(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		repo      = mocks.NewMediaRepository(t)
+		eventRepo = mocks.NewEventRepository(t)
+		store     = mocks.NewBlobStore(t)
+	)
+	mediaService := NewMediaService(zap.NewNop(), MediaLimits{}, repo, eventRepo, store)
+
+	eventRepo.On("ListEvents", ctx, timeline.EventFilter{}).
+		Return([]timeline.Event{
+			{ID: 1, Graphic: "data:text/plain;base64," + base64.StdEncoding.EncodeToString(testPNG(t, 10, 10))},
+			{ID: 2, Graphic: "/media/already-migrated.png"},
+			{ID: 3},
+		}, nil).
+		Once()
+	store.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("int64"), "image/png").
+		Return(nil).
+		Times(3)
+	repo.On("CreateMedia", ctx, mock.AnythingOfType("*timeline.Media")).
+		Return(nil).
+		Times(3)
+	eventRepo.On("UpdateEvent", ctx, uint(1), mock.AnythingOfType("*timeline.Event")).
+		Return(nil).
+		Once()
+
+	migrated, err := mediaService.MigrateEventGraphics(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, migrated)
+}
+
+func TestDecodeDataURI(t *testing.T) {
+	got, err := decodeDataURI("data:,Hello%2C%20World%21")
+	require.NoError(t, err)
+	require.Equal(t, "Hello, World!", string(got))
+
+	_, err = decodeDataURI("data:image/png;base64")
+	require.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+type RelationService struct {
+	log *zap.Logger
+
+	repo timeline.RelationRepository
+	tx   timeline.Transactor
+}
+
+func (rs RelationService) ListRelations(ctx context.Context) ([]timeline.Relation, error) {
+	return rs.repo.ListRelations(ctx, "")
+}
+
+func (rs RelationService) ListRelatedEvents(ctx context.Context, eventID uint) ([]timeline.RelatedEvent, error) {
+	return rs.repo.ListRelatedEvents(ctx, eventID)
+}
+
+func (rs RelationService) CreateRelation(ctx context.Context, r *timeline.Relation) (uint, error) {
+	switch r.Kind {
+	case timeline.RelationCausedBy, timeline.RelationFollows, timeline.RelationPartOf:
+	default:
+		return 0, fmt.Errorf("%w: unknown relation kind %q", timeline.ErrInvalid, r.Kind)
+	}
+	if r.FromID == r.ToID {
+		return 0, fmt.Errorf("%w: an event cannot be related to itself", timeline.ErrInvalid)
+	}
+
+	var id uint
+	err := rs.tx.InTransaction(ctx, func(repos timeline.Repositories) error {
+		if r.Kind == timeline.RelationPartOf {
+			// relations created meanwhile could close a cycle the check below does not see
+			if err := repos.Relations.LockRelations(ctx); err != nil {
+				return err
+			}
+			partOf, err := repos.Relations.ListRelations(ctx, timeline.RelationPartOf)
+			if err != nil {
+				return err
+			}
+			if reachable(partOf, r.ToID, r.FromID) {
+				return fmt.Errorf("%w: event %d is already (transitively) containing event %d", timeline.ErrConflict, r.FromID, r.ToID)
+			}
+		}
+		var err error
+		id, err = repos.Relations.CreateRelation(ctx, r)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (rs RelationService) DeleteRelation(ctx context.Context, eventID, id uint) error {
+	return rs.repo.DeleteRelation(ctx, eventID, id)
+}
+
+// reachable reports whether target can be reached from start following the relations' directions.
+func reachable(relations []timeline.Relation, start, target uint) bool {
+	edges := map[uint][]uint{}
+	for _, r := range relations {
+		edges[r.FromID] = append(edges[r.FromID], r.ToID)
+	}
+
+	visited := map[uint]bool{}
+	stack := []uint{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == target {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, edges[id]...)
+	}
+	return false
+}
+
+func NewRelationService(log *zap.Logger, repo timeline.RelationRepository, tx timeline.Transactor) *RelationService {
+	return &RelationService{log: log, repo: repo, tx: tx}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+func TestCreateRelation(t *testing.T) {
+	// 1 is part of 2, 2 is part of 3
+	hierarchy := []timeline.Relation{
+		{ID: 1, FromID: 1, ToID: 2, Kind: timeline.RelationPartOf},
+		{ID: 2, FromID: 2, ToID: 3, Kind: timeline.RelationPartOf},
+	}
+
+	tests := map[string]struct {
+		relation    timeline.Relation
+		setMockFunc func(*mocks.RelationRepository, *timeline.Relation)
+		wantErr     error
+	}{
+		"caused by": {
+			relation: timeline.Relation{FromID: 3, ToID: 1, Kind: timeline.RelationCausedBy},
+			setMockFunc: func(repo *mocks.RelationRepository, r *timeline.Relation) {
+				repo.On("CreateRelation", context.Background(), r).Return(uint(10), nil).Once()
+			},
+		},
+		"part of without cycle": {
+			relation: timeline.Relation{FromID: 4, ToID: 1, Kind: timeline.RelationPartOf},
+			setMockFunc: func(repo *mocks.RelationRepository, r *timeline.Relation) {
+				repo.On("LockRelations", context.Background()).Return(nil).Once()
+				repo.On("ListRelations", context.Background(), timeline.RelationPartOf).Return(hierarchy, nil).Once()
+				repo.On("CreateRelation", context.Background(), r).Return(uint(10), nil).Once()
+			},
+		},
+		"part of with cycle": {
+			relation: timeline.Relation{FromID: 3, ToID: 1, Kind: timeline.RelationPartOf},
+			setMockFunc: func(repo *mocks.RelationRepository, r *timeline.Relation) {
+				repo.On("LockRelations", context.Background()).Return(nil).Once()
+				repo.On("ListRelations", context.Background(), timeline.RelationPartOf).Return(hierarchy, nil).Once()
+			},
+			wantErr: timeline.ErrConflict,
+		},
+		"self relation": {
+			relation: timeline.Relation{FromID: 1, ToID: 1, Kind: timeline.RelationFollows},
+			wantErr:  timeline.ErrInvalid,
+		},
+		"unknown kind": {
+			relation: timeline.Relation{FromID: 1, ToID: 2, Kind: "blocks"},
+			wantErr:  timeline.ErrInvalid,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := mocks.NewRelationRepository(t)
+			if tt.setMockFunc != nil {
+				tt.setMockFunc(repo, &tt.relation)
+			}
+
+			tx := newTestTransactor(t, &timeline.Repositories{Relations: repo})
+			id, err := NewRelationService(nil, repo, tx).CreateRelation(context.Background(), &tt.relation)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint(10), id)
+		})
+	}
+}
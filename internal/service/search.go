@@ -0,0 +1,125 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const snippetRadius = 12 // words around the first match
+
+// field weights follow the Postgres ts_rank defaults for the A, B and C weight classes
+var searchFields = []struct {
+	weight float64
+	text   func(e *timeline.Event) string
+}{
+	{weight: 1.0, text: func(e *timeline.Event) string { return e.Name }},
+	{weight: 0.4, text: func(e *timeline.Event) string { return e.ShortDescription }},
+	{weight: 0.2, text: func(e *timeline.Event) string { return e.DetailedDescription }},
+}
+
+// searchInProcess is a simple ranked search used when the repository has no native full-text search.
+// All query terms must match (as prefixes of words); matches in the name rank higher than in descriptions.
+func searchInProcess(events []timeline.Event, query string, limit int) []timeline.SearchResult {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []timeline.SearchResult
+	for i := range events {
+		e := &events[i]
+		var rank float64
+		matchedAll := true
+		for _, term := range terms {
+			var termRank float64
+			for _, f := range searchFields {
+				termRank += f.weight * float64(countMatches(tokenize(f.text(e)), term))
+			}
+			if termRank == 0 {
+				matchedAll = false
+				break
+			}
+			rank += termRank
+		}
+		if !matchedAll {
+			continue
+		}
+		results = append(results, timeline.SearchResult{
+			Event:   *e,
+			Rank:    rank,
+			Snippet: snippet(e.ShortDescription+" "+e.DetailedDescription, terms),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func countMatches(words []string, term string) int {
+	var n int
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			n++
+		}
+	}
+	return n
+}
+
+func snippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	first := -1
+	for i, w := range words {
+		if matchesAny(w, terms) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	from, to := first-snippetRadius, first+snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	if to > len(words) {
+		to = len(words)
+	}
+	parts := make([]string, 0, to-from)
+	for _, w := range words[from:to] {
+		if matchesAny(w, terms) {
+			w = timeline.HighlightStart + w + timeline.HighlightStop
+		}
+		parts = append(parts, w)
+	}
+	result := strings.Join(parts, " ")
+	if from > 0 {
+		result = "… " + result
+	}
+	if to < len(words) {
+		result += " …"
+	}
+	return result
+}
+
+func matchesAny(word string, terms []string) bool {
+	for _, token := range tokenize(word) {
+		for _, term := range terms {
+			if strings.HasPrefix(token, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
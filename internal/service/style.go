@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cssColorNames are the named colors of CSS Color Module Level 4.
+var cssColorNames = map[string]bool{
+	"aliceblue": true, "antiquewhite": true, "aqua": true, "aquamarine": true, "azure": true,
+	"beige": true, "bisque": true, "black": true, "blanchedalmond": true, "blue": true,
+	"blueviolet": true, "brown": true, "burlywood": true, "cadetblue": true, "chartreuse": true,
+	"chocolate": true, "coral": true, "cornflowerblue": true, "cornsilk": true, "crimson": true,
+	"cyan": true, "darkblue": true, "darkcyan": true, "darkgoldenrod": true, "darkgray": true,
+	"darkgreen": true, "darkgrey": true, "darkkhaki": true, "darkmagenta": true, "darkolivegreen": true,
+	"darkorange": true, "darkorchid": true, "darkred": true, "darksalmon": true, "darkseagreen": true,
+	"darkslateblue": true, "darkslategray": true, "darkslategrey": true, "darkturquoise": true, "darkviolet": true,
+	"deeppink": true, "deepskyblue": true, "dimgray": true, "dimgrey": true, "dodgerblue": true,
+	"firebrick": true, "floralwhite": true, "forestgreen": true, "fuchsia": true, "gainsboro": true,
+	"ghostwhite": true, "gold": true, "goldenrod": true, "gray": true, "green": true,
+	"greenyellow": true, "grey": true, "honeydew": true, "hotpink": true, "indianred": true,
+	"indigo": true, "ivory": true, "khaki": true, "lavender": true, "lavenderblush": true,
+	"lawngreen": true, "lemonchiffon": true, "lightblue": true, "lightcoral": true, "lightcyan": true,
+	"lightgoldenrodyellow": true, "lightgray": true, "lightgreen": true, "lightgrey": true, "lightpink": true,
+	"lightsalmon": true, "lightseagreen": true, "lightskyblue": true, "lightslategray": true, "lightslategrey": true,
+	"lightsteelblue": true, "lightyellow": true, "lime": true, "limegreen": true, "linen": true,
+	"magenta": true, "maroon": true, "mediumaquamarine": true, "mediumblue": true, "mediumorchid": true,
+	"mediumpurple": true, "mediumseagreen": true, "mediumslateblue": true, "mediumspringgreen": true, "mediumturquoise": true,
+	"mediumvioletred": true, "midnightblue": true, "mintcream": true, "mistyrose": true, "moccasin": true,
+	"navajowhite": true, "navy": true, "oldlace": true, "olive": true, "olivedrab": true,
+	"orange": true, "orangered": true, "orchid": true, "palegoldenrod": true, "palegreen": true,
+	"paleturquoise": true, "palevioletred": true, "papayawhip": true, "peachpuff": true, "peru": true,
+	"pink": true, "plum": true, "powderblue": true, "purple": true, "rebeccapurple": true,
+	"red": true, "rosybrown": true, "royalblue": true, "saddlebrown": true, "salmon": true,
+	"sandybrown": true, "seagreen": true, "seashell": true, "sienna": true, "silver": true,
+	"skyblue": true, "slateblue": true, "slategray": true, "slategrey": true, "snow": true,
+	"springgreen": true, "steelblue": true, "tan": true, "teal": true, "thistle": true,
+	"tomato": true, "turquoise": true, "violet": true, "wheat": true, "white": true,
+	"whitesmoke": true, "yellow": true, "yellowgreen": true,
+}
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#([0-9a-f]{3,4}|[0-9a-f]{6}|[0-9a-f]{8})$`)
+	rgbColorPattern = regexp.MustCompile(`^rgba?\((.*)\)$`)
+	iconPattern     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// themePattern accepts the same names as the theme directories the renderer loads.
+	themePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+const (
+	maxIconLength  = 64
+	maxThemeLength = 64
+)
+
+// normalizeColor validates a CSS color and returns its canonical form: named colors in lower case
+// and everything else as lower case #rrggbb, or #rrggbbaa for translucent colors.
+// An empty color stays empty, so that it can be inherited.
+func normalizeColor(color string) (string, error) {
+	c := strings.ToLower(strings.TrimSpace(color))
+	switch {
+	case c == "":
+		return "", nil
+	case cssColorNames[c]:
+		return c, nil
+	case hexColorPattern.MatchString(c):
+		return expandHexColor(c), nil
+	case rgbColorPattern.MatchString(c):
+		if hex, ok := rgbToHex(rgbColorPattern.FindStringSubmatch(c)[1]); ok {
+			return hex, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q is not a CSS color name, hex or rgb() color", timeline.ErrInvalid, color)
+}
+
+func expandHexColor(c string) string {
+	digits := c[1:]
+	if len(digits) == 3 || len(digits) == 4 {
+		var b strings.Builder
+		for _, d := range digits {
+			b.WriteRune(d)
+			b.WriteRune(d)
+		}
+		digits = b.String()
+	}
+	if len(digits) == 8 && strings.HasSuffix(digits, "ff") {
+		digits = digits[:6]
+	}
+	return "#" + digits
+}
+
+// rgbToHex converts the arguments of rgb() or rgba() in either the comma or the space separated syntax.
+func rgbToHex(args string) (string, bool) {
+	var channels, alpha []string
+	if strings.Contains(args, ",") {
+		channels = strings.Split(args, ",")
+		if len(channels) == 4 {
+			channels, alpha = channels[:3], channels[3:]
+		}
+	} else {
+		parts := strings.SplitN(args, "/", 2)
+		channels = strings.Fields(parts[0])
+		if len(parts) == 2 {
+			alpha = parts[1:]
+		}
+	}
+	if len(channels) != 3 {
+		return "", false
+	}
+
+	hex := "#"
+	for _, ch := range channels {
+		v, ok := parseColorComponent(ch, 255)
+		if !ok {
+			return "", false
+		}
+		hex += fmt.Sprintf("%02x", v)
+	}
+	if len(alpha) == 1 {
+		a, ok := parseColorComponent(alpha[0], 1)
+		if !ok {
+			return "", false
+		}
+		if a < 255 {
+			hex += fmt.Sprintf("%02x", a)
+		}
+	}
+	return hex, true
+}
+
+// parseColorComponent parses a number in [0, max] or a percentage and scales it to [0, 255].
+func parseColorComponent(s string, max float64) (int, bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "%") {
+		s, max = strings.TrimSuffix(s, "%"), 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || v > max {
+		return 0, false
+	}
+	return int(math.Round(v / max * 255)), true
+}
+
+// normalizeTheme validates a theme name. Whether the theme is installed is up to the renderer,
+// which falls back to the default theme.
+func normalizeTheme(theme string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(theme))
+	if t == "" {
+		return "", nil
+	}
+	if len(t) > maxThemeLength || !themePattern.MatchString(t) {
+		return "", fmt.Errorf("%w: theme %q must be a lower case identifier like \"compact\"", timeline.ErrInvalid, theme)
+	}
+	return t, nil
+}
+
+// normalizeIcon validates an icon identifier such as "rocket" or "space-station".
+func normalizeIcon(icon string) (string, error) {
+	i := strings.ToLower(strings.TrimSpace(icon))
+	if i == "" {
+		return "", nil
+	}
+	if len(i) > maxIconLength || !iconPattern.MatchString(i) {
+		return "", fmt.Errorf("%w: icon %q must be a lower case identifier like \"rocket\"", timeline.ErrInvalid, icon)
+	}
+	return i, nil
+}
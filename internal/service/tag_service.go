@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+type TagService struct {
+	log *zap.Logger
+
+	repo timeline.TagRepository
+}
+
+func (t TagService) ListTags(ctx context.Context) ([]timeline.Tag, error) {
+	return t.repo.ListTags(ctx)
+}
+
+func (t TagService) RenameTag(ctx context.Context, id uint, name string) error {
+	name = normalizeTag(name)
+	if name == "" {
+		return fmt.Errorf("%w: empty tag name", timeline.ErrInvalid)
+	}
+	existing, err := t.repo.GetTagByName(ctx, name)
+	switch {
+	case err == nil && existing.ID != id:
+		return fmt.Errorf("%w: tag %q already exists, merge the tags instead", timeline.ErrConflict, name)
+	case err != nil && !errors.Is(err, timeline.ErrNotFound):
+		return err
+	}
+	return t.repo.RenameTag(ctx, id, name)
+}
+
+func (t TagService) MergeTags(ctx context.Context, sourceID, targetID uint) error {
+	if sourceID == targetID {
+		return fmt.Errorf("%w: cannot merge a tag into itself", timeline.ErrInvalid)
+	}
+	return t.repo.MergeTags(ctx, sourceID, targetID)
+}
+
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// normalizeTags trims the tags and drops empty and duplicate ones.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func NewTagService(log *zap.Logger, repo timeline.TagRepository) *TagService {
+	return &TagService{log: log, repo: repo}
+}
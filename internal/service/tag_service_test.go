@@ -0,0 +1,72 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+func TestRenameTag(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("happy path", func(t *testing.T) {
+		repo := mocks.NewTagRepository(t)
+		repo.On("GetTagByName", ctx, "Neil Armstrong").
+			Return(timeline.Tag{}, timeline.ErrNotFound).
+			Once()
+		repo.On("RenameTag", ctx, uint(1), "Neil Armstrong").
+			Return(nil).
+			Once()
+
+		require.NoError(t, NewTagService(nil, repo).RenameTag(ctx, 1, "  Neil   Armstrong "))
+	})
+
+	t.Run("name taken by another tag", func(t *testing.T) {
+		repo := mocks.NewTagRepository(t)
+		repo.On("GetTagByName", ctx, "apollo").
+			Return(timeline.Tag{ID: 2, Name: "apollo"}, nil).
+			Once()
+
+		err := NewTagService(nil, repo).RenameTag(ctx, 1, "apollo")
+		require.ErrorIs(t, err, timeline.ErrConflict)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		err := NewTagService(nil, mocks.NewTagRepository(t)).RenameTag(ctx, 1, "  ")
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
+
+func TestMergeTags(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewTagRepository(t)
+	tagService := NewTagService(nil, repo)
+
+	repo.On("MergeTags", ctx, uint(1), uint(2)).
+		Return(nil).
+		Once()
+	require.NoError(t, tagService.MergeTags(ctx, 1, 2))
+
+	require.ErrorIs(t, tagService.MergeTags(ctx, 2, 2), timeline.ErrInvalid)
+}
+
+func TestListEventsTagFilter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("normalizes filter", func(t *testing.T) {
+		repoMock := mocks.NewEventRepository(t)
+		repoMock.On("ListEvents", ctx, timeline.EventFilter{Tags: []string{"apollo", "nasa"}, TagMode: timeline.TagModeAny}).
+			Return([]timeline.Event{}, nil).
+			Once()
+
+		_, err := NewEventService(nil, MediaLimits{}, repoMock).ListEvents(ctx, timeline.EventFilter{Tags: []string{" apollo", "nasa", "apollo", ""}})
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown tag mode", func(t *testing.T) {
+		_, err := NewEventService(nil, MediaLimits{}, mocks.NewEventRepository(t)).ListEvents(ctx, timeline.EventFilter{TagMode: "some"})
+		require.ErrorIs(t, err, timeline.ErrInvalid)
+	})
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"fmt"
 	"github.com/kamkali/go-timeline/internal/timeline"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
+	"strings"
 )
 
 type TypeService struct {
@@ -12,24 +14,177 @@ type TypeService struct {
 	repo timeline.TypeRepository
 }
 
-func (t TypeService) GetType(ctx context.Context, id uint) (timeline.Type, error) {
-	return t.repo.GetType(ctx, id)
+func (t TypeService) GetType(ctx context.Context, id uint, opts timeline.TypeOptions) (timeline.Type, error) {
+	if opts.IncludeEvents && (opts.EventsLimit < 1 || opts.EventsOffset < 0) {
+		return timeline.Type{}, fmt.Errorf("%w: invalid events page", timeline.ErrInvalid)
+	}
+	dt, err := t.repo.GetType(ctx, id, opts)
+	if err != nil {
+		return timeline.Type{}, err
+	}
+	types, err := t.repo.ListTypes(ctx)
+	if err != nil {
+		return timeline.Type{}, err
+	}
+	dt.EffectiveColor = inheritedColor(typesByID(types), &dt)
+	return dt, nil
 }
 
 func (t TypeService) UpdateType(ctx context.Context, id uint, dt *timeline.Type) error {
+	if err := validateType(dt); err != nil {
+		return err
+	}
+	if err := t.validateParent(ctx, id, dt.ParentID); err != nil {
+		return err
+	}
 	return t.repo.UpdateType(ctx, id, dt)
 }
 
-func (t TypeService) DeleteType(ctx context.Context, id uint) error {
-	return t.repo.DeleteType(ctx, id)
+func (t TypeService) DeleteType(ctx context.Context, id uint, policy timeline.TypeDeletePolicy, revision uint) error {
+	switch policy {
+	case "":
+		policy = timeline.TypeDeleteRestrict
+	case timeline.TypeDeleteRestrict, timeline.TypeDeleteReparent:
+	default:
+		return fmt.Errorf("%w: unknown delete policy %q", timeline.ErrInvalid, policy)
+	}
+	return t.repo.DeleteType(ctx, id, policy, revision)
 }
 
 func (t TypeService) CreateType(ctx context.Context, dt *timeline.Type) (uint, error) {
+	if err := validateType(dt); err != nil {
+		return 0, err
+	}
+	if err := t.validateParent(ctx, 0, dt.ParentID); err != nil {
+		return 0, err
+	}
 	return t.repo.CreateType(ctx, dt)
 }
 
 func (t TypeService) ListTypes(ctx context.Context) ([]timeline.Type, error) {
-	return t.repo.ListTypes(ctx)
+	types, err := t.repo.ListTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := typesByID(types)
+	for i := range types {
+		types[i].EffectiveColor = inheritedColor(byID, &types[i])
+	}
+	return types, nil
+}
+
+// ListTypeTree returns the root types with their descendants nested as children.
+func (t TypeService) ListTypeTree(ctx context.Context) ([]timeline.Type, error) {
+	types, err := t.ListTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTypeTree(types), nil
+}
+
+// validateType checks the name and normalizes the styling of the type.
+func validateType(dt *timeline.Type) error {
+	dt.Name = strings.TrimSpace(dt.Name)
+	if dt.Name == "" {
+		return fmt.Errorf("%w: type name is required", timeline.ErrInvalid)
+	}
+	color, err := normalizeColor(dt.Color)
+	if err != nil {
+		return err
+	}
+	icon, err := normalizeIcon(dt.Icon)
+	if err != nil {
+		return err
+	}
+	theme, err := normalizeTheme(dt.Theme)
+	if err != nil {
+		return err
+	}
+	dt.Color, dt.Icon, dt.Theme = color, icon, theme
+	return nil
+}
+
+// validateParent makes sure the parent exists and that the type with id is not among its ancestors.
+// Types that are yet to be created have id 0.
+func (t TypeService) validateParent(ctx context.Context, id uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return fmt.Errorf("%w: a type cannot be its own parent", timeline.ErrInvalid)
+	}
+
+	types, err := t.repo.ListTypes(ctx)
+	if err != nil {
+		return err
+	}
+	byID := typesByID(types)
+	if _, ok := byID[*parentID]; !ok {
+		return fmt.Errorf("%w: parent type %d does not exist", timeline.ErrInvalid, *parentID)
+	}
+
+	visited := map[uint]bool{}
+	for ancestor := byID[*parentID]; ancestor != nil && !visited[ancestor.ID]; {
+		if ancestor.ID == id {
+			return fmt.Errorf("%w: type %d is an ancestor of type %d", timeline.ErrConflict, id, *parentID)
+		}
+		visited[ancestor.ID] = true
+		if ancestor.ParentID == nil {
+			break
+		}
+		ancestor = byID[*ancestor.ParentID]
+	}
+	return nil
+}
+
+func typesByID(types []timeline.Type) map[uint]*timeline.Type {
+	byID := make(map[uint]*timeline.Type, len(types))
+	for i := range types {
+		byID[types[i].ID] = &types[i]
+	}
+	return byID
+}
+
+// inheritedColor returns the color of the type or of its closest ancestor that sets one.
+func inheritedColor(byID map[uint]*timeline.Type, t *timeline.Type) string {
+	visited := map[uint]bool{}
+	for t != nil && !visited[t.ID] {
+		if t.Color != "" {
+			return t.Color
+		}
+		visited[t.ID] = true
+		if t.ParentID == nil {
+			break
+		}
+		t = byID[*t.ParentID]
+	}
+	return ""
+}
+
+// buildTypeTree nests the types under their parents. Types whose parent is unknown become roots.
+func buildTypeTree(types []timeline.Type) []timeline.Type {
+	byID := typesByID(types)
+	children := map[uint][]timeline.Type{}
+	roots := []timeline.Type{}
+	for _, t := range types {
+		if t.ParentID != nil && byID[*t.ParentID] != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+			continue
+		}
+		roots = append(roots, t)
+	}
+
+	var attach func(t *timeline.Type)
+	attach = func(t *timeline.Type) {
+		t.Children = children[t.ID]
+		for i := range t.Children {
+			attach(&t.Children[i])
+		}
+	}
+	for i := range roots {
+		attach(&roots[i])
+	}
+	return roots
 }
 
 func NewTypeService(log *zap.Logger, repo timeline.TypeRepository) *TypeService {
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"github.com/kamkali/go-timeline/internal/mocks"
+	"github.com/kamkali/go-timeline/internal/timeline"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"testing"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// mission > crewed > lunar, plus an unrelated root
+var hierarchy = []timeline.Type{
+	{ID: 1, Name: "mission", Color: "blue"},
+	{ID: 2, Name: "crewed", ParentID: uintPtr(1)},
+	{ID: 3, Name: "lunar", ParentID: uintPtr(2), Color: "silver"},
+	{ID: 4, Name: "other"},
+}
+
+func TestListTypes(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewTypeRepository(t)
+	repo.On("ListTypes", ctx).Return(append([]timeline.Type{}, hierarchy...), nil).Once()
+
+	types, err := NewTypeService(nil, repo).ListTypes(ctx)
+	require.NoError(t, err)
+
+	var colors []string
+	for _, dt := range types {
+		colors = append(colors, dt.EffectiveColor)
+	}
+	require.Equal(t, []string{"blue", "blue", "silver", ""}, colors)
+	require.Equal(t, "", types[1].Color)
+}
+
+func TestListTypeTree(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewTypeRepository(t)
+	repo.On("ListTypes", ctx).Return(append([]timeline.Type{}, hierarchy...), nil).Once()
+
+	tree, err := NewTypeService(nil, repo).ListTypeTree(ctx)
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	require.Equal(t, "mission", tree[0].Name)
+	require.Len(t, tree[0].Children, 1)
+	require.Equal(t, "crewed", tree[0].Children[0].Name)
+	require.Equal(t, "blue", tree[0].Children[0].EffectiveColor)
+	require.Len(t, tree[0].Children[0].Children, 1)
+	require.Equal(t, "lunar", tree[0].Children[0].Children[0].Name)
+	require.Empty(t, tree[1].Children)
+}
+
+func TestUpdateTypeParent(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		id       uint
+		parentID *uint
+		wantErr  error
+	}{
+		"valid parent":       {id: 4, parentID: uintPtr(3)},
+		"own parent":         {id: 2, parentID: uintPtr(2), wantErr: timeline.ErrInvalid},
+		"unknown parent":     {id: 2, parentID: uintPtr(42), wantErr: timeline.ErrInvalid},
+		"descendant parent":  {id: 1, parentID: uintPtr(3), wantErr: timeline.ErrConflict},
+		"direct child cycle": {id: 2, parentID: uintPtr(3), wantErr: timeline.ErrConflict},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := mocks.NewTypeRepository(t)
+			if tt.parentID != nil && *tt.parentID != tt.id {
+				repo.On("ListTypes", ctx).Return(append([]timeline.Type{}, hierarchy...), nil).Once()
+			}
+			dt := &timeline.Type{Name: "updated", ParentID: tt.parentID}
+			if tt.wantErr == nil {
+				repo.On("UpdateType", ctx, tt.id, dt).Return(nil).Once()
+			}
+
+			err := NewTypeService(nil, repo).UpdateType(ctx, tt.id, dt)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDeleteTypePolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewTypeRepository(t)
+	typeService := NewTypeService(nil, repo)
+
+	repo.On("DeleteType", ctx, uint(1), timeline.TypeDeleteRestrict, uint(3)).Return(nil).Once()
+	require.NoError(t, typeService.DeleteType(ctx, 1, "", 3))
+
+	repo.On("DeleteType", ctx, uint(1), timeline.TypeDeleteReparent, uint(3)).Return(nil).Once()
+	require.NoError(t, typeService.DeleteType(ctx, 1, timeline.TypeDeleteReparent, 3))
+
+	require.ErrorIs(t, typeService.DeleteType(ctx, 1, "orphan", 3), timeline.ErrInvalid)
+}
+
+func TestNormalizeColor(t *testing.T) {
+	tests := map[string]struct {
+		color   string
+		want    string
+		wantErr bool
+	}{
+		"empty":             {color: "", want: ""},
+		"name":              {color: " White ", want: "white"},
+		"short hex":         {color: "#F0a", want: "#ff00aa"},
+		"short hex alpha":   {color: "#f0a8", want: "#ff00aa88"},
+		"opaque hex alpha":  {color: "#FF00AAFF", want: "#ff00aa"},
+		"rgb":               {color: "rgb(255, 0, 128)", want: "#ff0080"},
+		"rgb percentages":   {color: "rgb(100%, 0%, 50%)", want: "#ff0080"},
+		"rgba":              {color: "rgba(255,0,128,0.5)", want: "#ff008080"},
+		"space syntax":      {color: "rgb(255 0 128 / 50%)", want: "#ff008080"},
+		"opaque rgba":       {color: "rgba(255, 0, 128, 1)", want: "#ff0080"},
+		"unknown name":      {color: "blurple", wantErr: true},
+		"bad hex":           {color: "#12345", wantErr: true},
+		"channel too large": {color: "rgb(256, 0, 0)", wantErr: true},
+		"missing channel":   {color: "rgb(255, 0)", wantErr: true},
+		"css injection":     {color: "red; background: url(x)", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := normalizeColor(tt.color)
+			if tt.wantErr {
+				require.ErrorIs(t, err, timeline.ErrInvalid)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateTypeStyle(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewTypeRepository(t)
+	typeService := NewTypeService(nil, repo)
+
+	repo.On("CreateType", ctx, &timeline.Type{Name: "crewed", Color: "#aabbcc", Icon: "rocket", Theme: "compact", DisplayOrder: 2}).
+		Return(uint(1), nil).
+		Once()
+	id, err := typeService.CreateType(ctx, &timeline.Type{Name: "crewed", Color: "#ABC", Icon: " Rocket", Theme: " Compact", DisplayOrder: 2})
+	require.NoError(t, err)
+	require.Equal(t, uint(1), id)
+
+	_, err = typeService.CreateType(ctx, &timeline.Type{Name: "crewed", Icon: "<script>"})
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+	_, err = typeService.CreateType(ctx, &timeline.Type{Name: "crewed", Theme: "../dark"})
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
+
+func TestGetType(t *testing.T) {
+	ctx := context.Background()
+	opts := timeline.TypeOptions{IncludeEvents: true, EventsLimit: 10, EventsOffset: 20}
+
+	repo := mocks.NewTypeRepository(t)
+	repo.On("GetType", ctx, uint(2), opts).
+		Return(timeline.Type{ID: 2, Name: "crewed", ParentID: uintPtr(1), EventCount: 21, Events: []timeline.Event{{ID: 7}}}, nil).
+		Once()
+	repo.On("ListTypes", ctx).Return(append([]timeline.Type{}, hierarchy...), nil).Once()
+
+	typeService := NewTypeService(nil, repo)
+	dt, err := typeService.GetType(ctx, 2, opts)
+	require.NoError(t, err)
+	require.Equal(t, "blue", dt.EffectiveColor)
+	require.Equal(t, 21, dt.EventCount)
+	require.Len(t, dt.Events, 1)
+
+	_, err = typeService.GetType(ctx, 2, timeline.TypeOptions{IncludeEvents: true})
+	require.ErrorIs(t, err, timeline.ErrInvalid)
+}
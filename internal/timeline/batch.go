@@ -0,0 +1,74 @@
+package timeline
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+)
+
+type BatchMode string
+
+const (
+	// BatchAtomic applies all operations or none of them.
+	BatchAtomic BatchMode = "atomic"
+	// BatchPartial applies every operation that succeeds and reports the failing ones.
+	BatchPartial BatchMode = "partial"
+)
+
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+type BatchResource string
+
+const (
+	BatchEvent BatchResource = "event"
+	BatchType  BatchResource = "type"
+)
+
+// ErrBatchAborted is reported for the operations of an atomic batch that were rolled back or skipped
+// because another operation failed.
+var ErrBatchAborted = errors.New("not applied, the batch was aborted")
+
+type BatchOperation struct {
+	Action   BatchAction
+	Resource BatchResource
+	// ID of the updated or deleted resource.
+	ID uint
+	// Revision of the updated or deleted resource, 0 to skip the check.
+	Revision uint
+	Event    *Event
+	Type     *Type
+	// Policy applies to deleted types.
+	Policy TypeDeletePolicy
+}
+
+type BatchResult struct {
+	ID  uint
+	Err error
+}
+
+type BatchService interface {
+	ExecuteBatch(ctx context.Context, mode BatchMode, ops []BatchOperation) ([]BatchResult, error)
+}
+
+//go:generate mockery --output=../mocks --name=BatchService
+
+// Repositories are bound to a single transaction.
+type Repositories struct {
+	Events    EventRepository
+	Types     TypeRepository
+	Relations RelationRepository
+	// Tx runs nested work in a savepoint of the transaction.
+	Tx Transactor
+}
+
+type Transactor interface {
+	// InTransaction runs fn with repositories bound to a transaction, which is committed if fn returns nil.
+	InTransaction(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+//go:generate mockery --output=../mocks --name=Transactor
@@ -0,0 +1,36 @@
+package timeline
+
+import (
+	"golang.org/x/net/context"
+	"net/http"
+	"time"
+)
+
+// CachedPage is a rendered page or list response, with the headers it is served with.
+type CachedPage struct {
+	Body   []byte
+	Header http.Header
+}
+
+// PageCacheVersion identifies the state of the timeline that cached pages were rendered from.
+type PageCacheVersion struct {
+	// Generation changes whenever the cache is invalidated, pages are cached under keys holding it.
+	Generation uint64
+	// Modified is when the timeline last changed, or a later time if the cache does not know.
+	Modified time.Time
+}
+
+// PageCache keeps rendered pages so that they are not rendered again until the timeline changes.
+// Caches are shared by all pages and invalidated as a whole whenever events, types, tags or relations change.
+type PageCache interface {
+	// Version returns the current generation. It is read before the data of a page is, so that pages rendered
+	// from data changed meanwhile are cached under a generation no longer served.
+	Version(ctx context.Context) (PageCacheVersion, error)
+	// Get returns the page cached under key, ok is false if there is none or it expired.
+	Get(ctx context.Context, key string) (page CachedPage, ok bool, err error)
+	Set(ctx context.Context, key string, page CachedPage) error
+	// Invalidate starts a new generation, pages of previous generations are not served anymore.
+	Invalidate(ctx context.Context) error
+}
+
+//go:generate mockery --output=../mocks --name=PageCache
@@ -7,4 +7,10 @@ import (
 var (
 	ErrNotFound     = errors.New("not found")
 	ErrUnauthorized = errors.New("unauthorized")
+	ErrInvalidMedia = errors.New("invalid media")
+	ErrTooLarge     = errors.New("too large")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalid      = errors.New("invalid")
+	// ErrPreconditionFailed is returned for writes based on a stale revision.
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
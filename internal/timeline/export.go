@@ -0,0 +1,49 @@
+package timeline
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"strconv"
+	"strings"
+)
+
+// exportedIDPrefix precedes the IDs of events in the external IDs exports give events that have none.
+const exportedIDPrefix = "event-"
+
+// ExportedExternalID returns the external ID an event is exported with, so that importing the export again updates
+// the events rather than duplicating them.
+func ExportedExternalID(e Event) string {
+	if e.ExternalID != "" {
+		return e.ExternalID
+	}
+	return fmt.Sprintf("%s%d", exportedIDPrefix, e.ID)
+}
+
+// ExportedEventID returns the ID of the event an external ID made by ExportedExternalID refers to.
+func ExportedEventID(externalID string) (uint, bool) {
+	if !strings.HasPrefix(externalID, exportedIDPrefix) {
+		return 0, false
+	}
+	rest := strings.TrimPrefix(externalID, exportedIDPrefix)
+	id, err := strconv.ParseUint(rest, 10, 32)
+	if err != nil || id == 0 || strconv.FormatUint(id, 10) != rest {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ExportWriter encodes the exported timeline. Types and tags are written before the events.
+type ExportWriter interface {
+	WriteTypes(types []Type) error
+	WriteTags(tags []Tag) error
+	WriteEvent(event Event) error
+	// Close completes the export, it is not called when the export fails.
+	Close() error
+}
+
+type ExportService interface {
+	// ExportTimeline writes all types and tags, and the events matching the filter in chronological order.
+	ExportTimeline(ctx context.Context, filter EventFilter, w ExportWriter) error
+}
+
+//go:generate mockery --output=../mocks --name=ExportService
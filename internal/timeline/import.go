@@ -0,0 +1,104 @@
+package timeline
+
+import (
+	"golang.org/x/net/context"
+)
+
+// Fields of an imported event, also used to name CSV columns.
+const (
+	ImportFieldExternalID          = "external_id"
+	ImportFieldName                = "name"
+	ImportFieldEventTime           = "event_time"
+	ImportFieldShortDescription    = "short_description"
+	ImportFieldDetailedDescription = "detailed_description"
+	ImportFieldDescriptionFormat   = "description_format"
+	ImportFieldGraphic             = "graphic"
+	ImportFieldType                = "type"
+	ImportFieldTypeID              = "type_id"
+	ImportFieldTags                = "tags"
+)
+
+// ImportFields lists all fields an import can provide.
+var ImportFields = []string{
+	ImportFieldExternalID,
+	ImportFieldName,
+	ImportFieldEventTime,
+	ImportFieldShortDescription,
+	ImportFieldDetailedDescription,
+	ImportFieldDescriptionFormat,
+	ImportFieldGraphic,
+	ImportFieldType,
+	ImportFieldTypeID,
+	ImportFieldTags,
+}
+
+// ImportFile is the content of an import file.
+type ImportFile struct {
+	// Types declared by the file, they are created unless a type of the same name exists.
+	Types   []ImportType
+	Records []ImportRecord
+}
+
+// ImportType is a type declared by an import file, as written by exports.
+type ImportType struct {
+	Line int
+	Type Type
+	// ParentName refers to the parent of the type by name.
+	ParentName string
+}
+
+// ImportRecord is a single event read from an import file.
+type ImportRecord struct {
+	// Line of the record in the imported file.
+	Line  int
+	Event Event
+	// TypeName refers to the type of the event by name, it takes precedence over Event.TypeID.
+	TypeName string
+	// Provided are the fields present in the record. When re-importing an event, the other fields are kept.
+	Provided []string
+	// Err is set for records that could not be read.
+	Err error
+}
+
+type ImportOptions struct {
+	// CreateTypes creates types that do not exist yet instead of rejecting their events.
+	CreateTypes bool
+	// DryRun reports what would be imported without changing anything.
+	DryRun bool
+	// DefaultType names the type of created events whose record does not refer to any.
+	DefaultType string
+}
+
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportUpdate    ImportAction = "update"
+	ImportUnchanged ImportAction = "unchanged"
+)
+
+type ImportRowResult struct {
+	Line       int
+	ExternalID string
+	Action     ImportAction
+	EventID    uint
+	Err        error
+}
+
+type ImportReport struct {
+	DryRun bool
+	// Committed is false for dry runs and for imports with failed rows, which are not applied at all.
+	Committed    bool
+	Created      int
+	Updated      int
+	Unchanged    int
+	Failed       int
+	CreatedTypes []string
+	Rows         []ImportRowResult
+}
+
+type ImportService interface {
+	ImportEvents(ctx context.Context, file ImportFile, opts ImportOptions) (ImportReport, error)
+}
+
+//go:generate mockery --output=../mocks --name=ImportService
@@ -0,0 +1,74 @@
+package timeline
+
+import (
+	"golang.org/x/net/context"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// MediaPathPrefix is the URL path under which stored media is served.
+const MediaPathPrefix = "/media/"
+
+// MediaPath returns the URL path referencing the media stored under key.
+func MediaPath(key string) string {
+	return MediaPathPrefix + key
+}
+
+const (
+	MediaVariantThumbnail = "thumbnail"
+	MediaVariantMedium    = "medium"
+)
+
+// MediaVariantKey returns the key of a resized variant of the media stored
+// under key. Variants of JPEGs are JPEGs, everything else is resized to PNG.
+func MediaVariantKey(key, variant string) string {
+	ext := path.Ext(key)
+	variantExt := ".png"
+	if ext == ".jpg" {
+		variantExt = ".jpg"
+	}
+	return strings.TrimSuffix(key, ext) + "-" + variant + variantExt
+}
+
+// MediaVariantPath returns the URL path of a variant of the graphic, or an
+// empty string if the graphic does not reference stored media.
+func MediaVariantPath(graphic, variant string) string {
+	if !strings.HasPrefix(graphic, MediaPathPrefix) {
+		return ""
+	}
+	return MediaPath(MediaVariantKey(strings.TrimPrefix(graphic, MediaPathPrefix), variant))
+}
+
+type Media struct {
+	Key         string
+	ContentType string
+	Size        int64
+	Checksum    string
+	CreatedAt   time.Time
+}
+
+type MediaService interface {
+	UploadEventGraphic(ctx context.Context, eventID uint, r io.Reader) (Media, error)
+	GetMedia(ctx context.Context, key string) (Media, io.ReadCloser, error)
+	MigrateEventGraphics(ctx context.Context) (int, error)
+}
+
+//go:generate mockery --output=../mocks --name=MediaService
+
+type MediaRepository interface {
+	CreateMedia(ctx context.Context, m *Media) error
+	GetMedia(ctx context.Context, key string) (Media, error)
+}
+
+//go:generate mockery --output=../mocks --name=MediaRepository
+
+// BlobStore keeps the binary content of media, addressed by key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+//go:generate mockery --output=../mocks --name=BlobStore
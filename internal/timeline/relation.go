@@ -0,0 +1,48 @@
+package timeline
+
+import (
+	"golang.org/x/net/context"
+)
+
+type RelationKind string
+
+const (
+	RelationCausedBy RelationKind = "caused_by"
+	RelationFollows  RelationKind = "follows"
+	RelationPartOf   RelationKind = "part_of"
+)
+
+// Relation is a typed, directed link between two events, e.g. FromID is part of ToID.
+type Relation struct {
+	ID     uint
+	FromID uint
+	ToID   uint
+	Kind   RelationKind
+}
+
+// RelatedEvent is the event on the other end of a relation.
+type RelatedEvent struct {
+	Relation Relation
+	Event    Event
+}
+
+type RelationService interface {
+	ListRelations(ctx context.Context) ([]Relation, error)
+	ListRelatedEvents(ctx context.Context, eventID uint) ([]RelatedEvent, error)
+	CreateRelation(ctx context.Context, r *Relation) (uint, error)
+	DeleteRelation(ctx context.Context, eventID, id uint) error
+}
+
+//go:generate mockery --output=../mocks --name=RelationService
+
+type RelationRepository interface {
+	ListRelations(ctx context.Context, kind RelationKind) ([]Relation, error)
+	ListRelatedEvents(ctx context.Context, eventID uint) ([]RelatedEvent, error)
+	CreateRelation(ctx context.Context, r *Relation) (uint, error)
+	DeleteRelation(ctx context.Context, eventID, id uint) error
+	// LockRelations keeps other transactions from changing relations until the transaction the repository is bound
+	// to ends, so that checks of the existing relations stay valid.
+	LockRelations(ctx context.Context) error
+}
+
+//go:generate mockery --output=../mocks --name=RelationRepository
@@ -0,0 +1,28 @@
+package timeline
+
+import (
+	"golang.org/x/net/context"
+)
+
+type Tag struct {
+	ID         uint
+	Name       string
+	EventCount int
+}
+
+type TagService interface {
+	ListTags(ctx context.Context) ([]Tag, error)
+	RenameTag(ctx context.Context, id uint, name string) error
+	MergeTags(ctx context.Context, sourceID, targetID uint) error
+}
+
+//go:generate mockery --output=../mocks --name=TagService
+
+type TagRepository interface {
+	ListTags(ctx context.Context) ([]Tag, error)
+	GetTagByName(ctx context.Context, name string) (Tag, error)
+	RenameTag(ctx context.Context, id uint, name string) error
+	MergeTags(ctx context.Context, sourceID, targetID uint) error
+}
+
+//go:generate mockery --output=../mocks --name=TagRepository
@@ -11,43 +11,151 @@ type Event struct {
 	EventTime           time.Time
 	ShortDescription    string
 	DetailedDescription string
-	Graphic             string
-	TypeID              uint
+	// DescriptionFormat is the markup of the detailed description, empty for plain text.
+	DescriptionFormat DescriptionFormat
+	Graphic           string
+	TypeID            uint
+	Tags              []string
+	// Revision is incremented on every update. Updates and deletes given a non-zero revision
+	// only succeed while it is still the current one.
+	Revision uint
+	// ExternalID identifies imported events in the system they were imported from. It is set on creation only.
+	ExternalID string
+	// CreatedAt and UpdatedAt are maintained by the repository.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// DescriptionFormat is the markup detailed descriptions are written in.
+type DescriptionFormat string
+
+const (
+	DescriptionFormatText     DescriptionFormat = "text"
+	DescriptionFormatMarkdown DescriptionFormat = "markdown"
+)
+
+type TagMode string
+
+const (
+	TagModeAny TagMode = "any"
+	TagModeAll TagMode = "all"
+)
+
+// EventFilter narrows down listed events. The zero value matches all events.
+type EventFilter struct {
+	Tags    []string
+	TagMode TagMode
+	// TypeIDs match events of any of the types and of all of their descendants.
+	TypeIDs []uint
+	// From and To bound the event time, both inclusive. Zero values leave the range open.
+	From time.Time
+	To   time.Time
+	// Query matches events by full-text search of their names and descriptions, like SearchEvents.
+	Query string
+}
+
+// FilterDateLayout is the layout of dates bounding the time range of filters in query strings, as sent by date inputs.
+const FilterDateLayout = "2006-01-02"
+
+// Highlighted parts of a search result snippet are enclosed in HighlightStart and HighlightStop.
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
+type SearchResult struct {
+	Event   Event
+	Rank    float64
+	Snippet string
 }
 
 type EventService interface {
-	ListEvents(ctx context.Context) ([]Event, error)
+	ListEvents(ctx context.Context, filter EventFilter) ([]Event, error)
+	// ListRecentEvents returns up to limit events matching the filter, the most recently created or updated first.
+	ListRecentEvents(ctx context.Context, filter EventFilter, limit int) ([]Event, error)
+	SearchEvents(ctx context.Context, query string, limit int) ([]SearchResult, error)
 	CreateEvent(ctx context.Context, event *Event) (uint, error)
 	GetEvent(ctx context.Context, id uint) (Event, error)
+	// GetNeighbourEvents returns the events right before and after the event on the timeline, nil at its ends.
+	GetNeighbourEvents(ctx context.Context, event Event) (previous, next *Event, err error)
 	UpdateEvent(ctx context.Context, id uint, event *Event) error
-	DeleteEvent(ctx context.Context, id uint) error
+	DeleteEvent(ctx context.Context, id uint, revision uint) error
 }
 
 //go:generate mockery --output=../mocks --name=EventService
 
 type EventRepository interface {
-	ListEvents(ctx context.Context) ([]Event, error)
+	ListEvents(ctx context.Context, filter EventFilter) ([]Event, error)
+	ListRecentEvents(ctx context.Context, filter EventFilter, limit int) ([]Event, error)
 	CreateEvent(ctx context.Context, event *Event) (uint, error)
 	GetEvent(ctx context.Context, id uint) (Event, error)
+	GetEventByExternalID(ctx context.Context, externalID string) (Event, error)
+	// GetNeighbourEvents returns the events right before and after the event in chronological order, events at the
+	// same time ordered by ID. Only their IDs, names and event times are loaded.
+	GetNeighbourEvents(ctx context.Context, event Event) (previous, next *Event, err error)
+	// StreamEvents calls fn for the events matching the filter in chronological order without loading them all at once.
+	StreamEvents(ctx context.Context, filter EventFilter, fn func(Event) error) error
 	UpdateEvent(ctx context.Context, id uint, event *Event) error
-	DeleteEvent(ctx context.Context, id uint) error
+	DeleteEvent(ctx context.Context, id uint, revision uint) error
 }
 
 //go:generate mockery --output=../mocks --name=EventRepository
 
+// EventSearcher is implemented by event repositories with native full-text search.
+type EventSearcher interface {
+	SearchEvents(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
+
+//go:generate mockery --output=../mocks --name=EventSearcher
+
 type Type struct {
-	ID     uint
-	Name   string
-	Color  string
-	Events []Event
+	ID    uint
+	Name  string
+	Color string
+	// Icon is an identifier like "rocket" of the icon shown on the type's event markers.
+	Icon string
+	// Theme names the theme rendering the page of the type and of its subtypes that do not set their own.
+	Theme string
+	// DisplayOrder sorts types in ascending order, ties are sorted by name.
+	DisplayOrder int
+	ParentID     *uint
+	// Revision works like Event.Revision.
+	Revision uint
+	// EffectiveColor is Color, or the color inherited from the closest ancestor that sets one.
+	EffectiveColor string
+	// EventCount, FirstEventTime and LastEventTime aggregate the events of the type itself, not of its children.
+	EventCount     int
+	FirstEventTime *time.Time
+	LastEventTime  *time.Time
+	Children       []Type
+	Events         []Event
 }
 
+// TypeOptions select what is loaded along with a single type.
+type TypeOptions struct {
+	// IncludeEvents loads a page of the type's events, ordered by event time, into Type.Events.
+	IncludeEvents bool
+	EventsLimit   int
+	EventsOffset  int
+}
+
+// TypeDeletePolicy decides what happens to the children of a deleted type.
+type TypeDeletePolicy string
+
+const (
+	// TypeDeleteRestrict refuses to delete a type that still has children.
+	TypeDeleteRestrict TypeDeletePolicy = "restrict"
+	// TypeDeleteReparent moves the children of a deleted type to its parent.
+	TypeDeleteReparent TypeDeletePolicy = "reparent"
+)
+
 type TypeService interface {
 	ListTypes(ctx context.Context) ([]Type, error)
+	ListTypeTree(ctx context.Context) ([]Type, error)
 	CreateType(ctx context.Context, t *Type) (uint, error)
-	GetType(ctx context.Context, id uint) (Type, error)
+	GetType(ctx context.Context, id uint, opts TypeOptions) (Type, error)
 	UpdateType(ctx context.Context, id uint, Type *Type) error
-	DeleteType(ctx context.Context, id uint) error
+	DeleteType(ctx context.Context, id uint, policy TypeDeletePolicy, revision uint) error
 }
 
 //go:generate mockery --output=../mocks --name=TypeService
@@ -55,9 +163,9 @@ type TypeService interface {
 type TypeRepository interface {
 	ListTypes(ctx context.Context) ([]Type, error)
 	CreateType(ctx context.Context, t *Type) (uint, error)
-	GetType(ctx context.Context, id uint) (Type, error)
+	GetType(ctx context.Context, id uint, opts TypeOptions) (Type, error)
 	UpdateType(ctx context.Context, id uint, Type *Type) error
-	DeleteType(ctx context.Context, id uint) error
+	DeleteType(ctx context.Context, id uint, policy TypeDeletePolicy, revision uint) error
 }
 
 //go:generate mockery --output=../mocks --name=TypeRepository